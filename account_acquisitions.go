@@ -0,0 +1,159 @@
+package recurly
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// AccountAcquisitionService manages the interactions for account acquisition.
+type AccountAcquisitionService interface {
+	// List returns a pager to paginate account acquisition data for the site.
+	// PagerOptions are used to optionally filter the results.
+	//
+	// https://dev.recurly.com/docs/list-account-acquisition
+	List(opts *PagerOptions) Pager
+
+	// Get retrieves acquisition data for an account. If the account does not
+	// exist, or the account does not have acquisition data, a nil struct
+	// and nil error are returned.
+	//
+	// https://dev.recurly.com/docs/lookup-account-acquisition
+	Get(ctx context.Context, accountCode string) (*AccountAcquisition, error)
+
+	// Create creates acquisition data for an account.
+	//
+	// https://dev.recurly.com/docs/create-account-acquisition
+	Create(ctx context.Context, accountCode string, a AccountAcquisition) (*AccountAcquisition, error)
+
+	// Update updates acquisition data for an account.
+	//
+	// https://dev.recurly.com/docs/update-account-acquisition
+	Update(ctx context.Context, accountCode string, a AccountAcquisition) (*AccountAcquisition, error)
+
+	// Delete removes acquisition data from an account.
+	//
+	// https://dev.recurly.com/docs/clear-account-acquisition
+	Delete(ctx context.Context, accountCode string) error
+}
+
+// Account acquisition channel constants.
+const (
+	AcquisitionChannelReferral      = "referral"
+	AcquisitionChannelSocialMedia   = "social_media"
+	AcquisitionChannelEmail         = "email"
+	AcquisitionChannelPaidSearch    = "paid_search"
+	AcquisitionChannelOrganicSearch = "organic_search"
+	AcquisitionChannelDirectTraffic = "direct_traffic"
+	AcquisitionChannelMarketplace   = "marketplace"
+	AcquisitionChannelInternal      = "internal"
+	AcquisitionChannelBlog          = "blog"
+	AcquisitionChannelPartner       = "partner"
+	AcquisitionChannelAffiliate     = "affiliate"
+	AcquisitionChannelTelevision    = "television"
+	AcquisitionChannelRadio         = "radio"
+	AcquisitionChannelPrint         = "print"
+	AcquisitionChannelOutdoor       = "outdoor"
+	AcquisitionChannelOther         = "other"
+)
+
+// AccountAcquisition holds the marketing channel, campaign, and cost
+// used to acquire an account.
+//
+// https://dev.recurly.com/docs/account-acquisition-object
+type AccountAcquisition struct {
+	XMLName     xml.Name `xml:"account_acquisition"`
+	AccountCode string   `xml:"-"`
+	CostInCents NullInt  `xml:"cost_in_cents,omitempty"`
+	Currency    string   `xml:"currency,omitempty"`
+	Channel     string   `xml:"channel,omitempty"`
+	Subchannel  string   `xml:"subchannel,omitempty"`
+	Campaign    string   `xml:"campaign,omitempty"`
+	CreatedAt   NullTime `xml:"created_at,omitempty"`
+	UpdatedAt   NullTime `xml:"updated_at,omitempty"`
+}
+
+// UnmarshalXML unmarshals account acquisition and handles intermediary state
+// during unmarshaling for types like href.
+func (a *AccountAcquisition) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type accountAcquisitionAlias AccountAcquisition
+	var v struct {
+		XMLName xml.Name `xml:"account_acquisition"`
+		accountAcquisitionAlias
+		AccountCode href `xml:"account"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	*a = AccountAcquisition(v.accountAcquisitionAlias)
+	a.XMLName = v.XMLName
+	a.AccountCode = v.AccountCode.LastPartOfPath()
+	return nil
+}
+
+var _ AccountAcquisitionService = &accountAcquisitionImpl{}
+
+// accountAcquisitionImpl implements AccountAcquisitionService.
+type accountAcquisitionImpl serviceImpl
+
+func (s *accountAcquisitionImpl) List(opts *PagerOptions) Pager {
+	return s.client.newPager("GET", "/acquisitions", opts)
+}
+
+func (s *accountAcquisitionImpl) Get(ctx context.Context, accountCode string) (*AccountAcquisition, error) {
+	path := fmt.Sprintf("/accounts/%s/acquisition", accountCode)
+	req, err := s.client.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst AccountAcquisition
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		if e, ok := err.(*ClientError); ok && e.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *accountAcquisitionImpl) Create(ctx context.Context, accountCode string, a AccountAcquisition) (*AccountAcquisition, error) {
+	path := fmt.Sprintf("/accounts/%s/acquisition", accountCode)
+	req, err := s.client.newRequest("POST", path, a)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst AccountAcquisition
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *accountAcquisitionImpl) Update(ctx context.Context, accountCode string, a AccountAcquisition) (*AccountAcquisition, error) {
+	path := fmt.Sprintf("/accounts/%s/acquisition", accountCode)
+	req, err := s.client.newRequest("PUT", path, a)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst AccountAcquisition
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *accountAcquisitionImpl) Delete(ctx context.Context, accountCode string) error {
+	path := fmt.Sprintf("/accounts/%s/acquisition", accountCode)
+	req, err := s.client.newRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	return err
+}
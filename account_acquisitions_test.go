@@ -0,0 +1,188 @@
+package recurly_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Ensure structs are encoded to XML properly.
+func TestAccountAcquisition_Encoding(t *testing.T) {
+	tests := []struct {
+		v        recurly.AccountAcquisition
+		expected string
+	}{
+		{
+			expected: MustCompactString(`
+				<account_acquisition></account_acquisition>
+			`),
+		},
+		{
+			v: recurly.AccountAcquisition{AccountCode: "1", CostInCents: recurly.NewInt(0), Currency: "USD"},
+			expected: MustCompactString(`
+				<account_acquisition>
+					<cost_in_cents>0</cost_in_cents>
+					<currency>USD</currency>
+				</account_acquisition>
+			`),
+		},
+		{
+			v: recurly.AccountAcquisition{Channel: recurly.AcquisitionChannelBlog, Subchannel: "Whitepaper Blog Post", Campaign: "spring"},
+			expected: MustCompactString(`
+				<account_acquisition>
+					<channel>blog</channel>
+					<subchannel>Whitepaper Blog Post</subchannel>
+					<campaign>spring</campaign>
+				</account_acquisition>
+			`),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := xml.NewEncoder(buf).Encode(tt.v); err != nil {
+				t.Fatal(err)
+			} else if buf.String() != tt.expected {
+				t.Fatal(buf.String())
+			}
+		})
+	}
+}
+
+func TestAccountAcquisition_List(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	var invocations int
+	s.HandleFunc("GET", "/v2/acquisitions", func(w http.ResponseWriter, r *http.Request) {
+		invocations++
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("account_acquisitions.xml"))
+	}, t)
+
+	pager := client.AccountAcquisition.List(nil)
+	for pager.Next() {
+		var a []recurly.AccountAcquisition
+		if err := pager.Fetch(context.Background(), &a); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected s to be invoked")
+		} else if diff := cmp.Diff(a, []recurly.AccountAcquisition{*NewTestAccountAcquisition()}); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+	if invocations != 1 {
+		t.Fatalf("unexpected number of invocations: %d", invocations)
+	}
+}
+
+func TestAccountAcquisition_Get(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/accounts/1/acquisition", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustOpenFile("account_acquisition.xml"))
+		}, t)
+
+		if a, err := client.AccountAcquisition.Get(context.Background(), "1"); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(a, NewTestAccountAcquisition()); diff != "" {
+			t.Fatal(diff)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		}
+	})
+
+	// Ensure a 404 returns nil values.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/accounts/1/acquisition", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, t)
+
+		if a, err := client.AccountAcquisition.Get(context.Background(), "1"); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if err != nil {
+			t.Fatal(err)
+		} else if a != nil {
+			t.Fatalf("expected nil: %#v", a)
+		}
+	})
+}
+
+func TestAccountAcquisition_Create(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("POST", "/v2/accounts/1/acquisition", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write(MustOpenFile("account_acquisition.xml"))
+	}, t)
+
+	if a, err := client.AccountAcquisition.Create(context.Background(), "1", recurly.AccountAcquisition{}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(a, NewTestAccountAcquisition()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestAccountAcquisition_Update(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("PUT", "/v2/accounts/1/acquisition", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("account_acquisition.xml"))
+	}, t)
+
+	if a, err := client.AccountAcquisition.Update(context.Background(), "1", recurly.AccountAcquisition{}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(a, NewTestAccountAcquisition()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestAccountAcquisition_Delete(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("DELETE", "/v2/accounts/1/acquisition", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, t)
+
+	if err := client.AccountAcquisition.Delete(context.Background(), "1"); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Returns account acquisition corresponding to testdata/account_acquisition.xml.
+func NewTestAccountAcquisition() *recurly.AccountAcquisition {
+	return &recurly.AccountAcquisition{
+		XMLName:     xml.Name{Local: "account_acquisition"},
+		AccountCode: "1",
+		CostInCents: recurly.NewInt(199),
+		Currency:    "USD",
+		Channel:     recurly.AcquisitionChannelBlog,
+		Subchannel:  "Whitepaper Blog Post",
+		Campaign:    "mailchimp67a904de95.0914d8f4b4",
+		CreatedAt:   recurly.NewTime(MustParseTime("2017-11-20T19:32:07Z")),
+		UpdatedAt:   recurly.NewTime(MustParseTime("2017-11-20T19:32:07Z")),
+	}
+}
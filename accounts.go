@@ -53,6 +53,12 @@ type AccountsService interface {
 	//
 	// https://dev.recurly.com/docs/list-account-notes
 	ListNotes(accountCode string, params *PagerOptions) Pager
+
+	// ListChildAccounts returns a pager to paginate the child accounts of a
+	// parent account. PagerOptions are used to optionally filter the results.
+	//
+	// https://dev.recurly.com/docs/list-child-accounts
+	ListChildAccounts(parentAccountCode string, opts *PagerOptions) Pager
 }
 
 // Account constants.
@@ -69,6 +75,7 @@ const (
 type Account struct {
 	XMLName                 xml.Name           `xml:"account"`
 	Code                    string             `xml:"account_code,omitempty"`
+	ParentAccountCode       string             `xml:"parent_account_code,omitempty"` // Read from the parent_account href in responses
 	State                   string             `xml:"state,omitempty"`
 	Username                string             `xml:"username,omitempty"`
 	Email                   string             `xml:"email,omitempty"`
@@ -97,6 +104,27 @@ type Account struct {
 	TransactionType         string             `xml:"transaction_type,omitempty"` // Create only
 }
 
+// UnmarshalXML unmarshals accounts and handles intermediary state during
+// unmarshaling for types like href.
+func (a *Account) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type accountAlias Account
+	var v struct {
+		XMLName xml.Name `xml:"account"`
+		accountAlias
+		ParentAccountCode href `xml:"parent_account"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	*a = Account(v.accountAlias)
+	a.XMLName = v.XMLName
+	if code := v.ParentAccountCode.LastPartOfPath(); code != "" {
+		a.ParentAccountCode = code
+	}
+	return nil
+}
+
 // AccountBalance is used for getting the account balance.
 type AccountBalance struct {
 	XMLName xml.Name   `xml:"account_balance"`
@@ -220,3 +248,8 @@ func (s *accountsImpl) ListNotes(accountCode string, params *PagerOptions) Pager
 	path := fmt.Sprintf("/accounts/%s/notes", accountCode)
 	return s.client.newPager("GET", path, params)
 }
+
+func (s *accountsImpl) ListChildAccounts(parentAccountCode string, opts *PagerOptions) Pager {
+	path := fmt.Sprintf("/accounts/%s/child_accounts", parentAccountCode)
+	return s.client.newPager("GET", path, opts)
+}
@@ -31,6 +31,15 @@ func TestAccounts_Encoding(t *testing.T) {
 				</account>
 			`),
 		},
+		{
+			v: recurly.Account{Code: "abc", ParentAccountCode: "parent"},
+			expected: MustCompactString(`
+				<account>
+					<account_code>abc</account_code>
+					<parent_account_code>parent</parent_account_code>
+				</account>
+			`),
+		},
 		{
 			v: recurly.Account{State: "active"},
 			expected: MustCompactString(`
@@ -434,6 +443,33 @@ func TestAccounts_ListNotes(t *testing.T) {
 	}
 }
 
+func TestAccounts_ListChildAccounts(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	var invocations int
+	s.HandleFunc("GET", "/v2/accounts/1/child_accounts", func(w http.ResponseWriter, r *http.Request) {
+		invocations++
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("child_accounts.xml"))
+	}, t)
+
+	pager := client.Accounts.ListChildAccounts("1", nil)
+	for pager.Next() {
+		var a []recurly.Account
+		if err := pager.Fetch(context.Background(), &a); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected s to be invoked")
+		} else if diff := cmp.Diff(a, []recurly.Account{*NewTestChildAccount()}); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+	if invocations != 1 {
+		t.Fatalf("unexpected number of invocations: %d", invocations)
+	}
+}
+
 // Returns an account corresponding to testdata/account.xml
 func NewTestAccount() *recurly.Account {
 	ts := MustParseTime("2011-10-25T12:00:00Z")
@@ -496,3 +532,33 @@ func NewTestNotes() []recurly.Note {
 		},
 	}
 }
+
+// Returns a child account corresponding to testdata/child_accounts.xml
+func NewTestChildAccount() *recurly.Account {
+	ts := MustParseTime("2019-03-05T18:00:00Z")
+	return &recurly.Account{
+		XMLName:           xml.Name{Local: "account"},
+		Code:              "2",
+		ParentAccountCode: "1",
+		State:             "active",
+		Email:             "benjamin@example.com",
+		FirstName:         "Benjamin",
+		LastName:          "Example",
+		TaxExempt:         recurly.NewBool(false),
+		BillingInfo: &recurly.Billing{
+			XMLName: xml.Name{Local: "billing_info"},
+		},
+		Address: &recurly.Address{
+			XMLName: xml.Name{Local: "address"},
+		},
+		HostedLoginToken:        "b73e5c42a6b1479fa3c1d7b0d2f1e8a4",
+		CreatedAt:               recurly.NewTime(ts),
+		UpdatedAt:               recurly.NewTime(ts),
+		HasLiveSubscription:     recurly.NewBool(true),
+		HasActiveSubscription:   recurly.NewBool(true),
+		HasFutureSubscription:   recurly.NewBool(false),
+		HasCanceledSubscription: recurly.NewBool(false),
+		HasPausedSubscription:   recurly.NewBool(false),
+		HasPastDueInvoice:       recurly.NewBool(false),
+	}
+}
@@ -38,6 +38,18 @@ type AddOnsService interface {
 	Delete(ctx context.Context, planCode string, code string) error
 }
 
+// Add-on type constants.
+const (
+	AddOnTypeFixed = "fixed"
+	AddOnTypeUsage = "usage"
+)
+
+// Usage type constants for usage-based add-ons.
+const (
+	UsageTypePrice      = "price"
+	UsageTypePercentage = "percentage"
+)
+
 // An AddOn is a charge billed each billing period in addition to a subscription’s
 // base charge. Each plan may have one or more add-ons associated with it.
 //
@@ -57,6 +69,12 @@ type AddOn struct {
 	TierType                    string     `xml:"tier_type,omitempty"`
 	Tiers                       *[]Tier    `xml:"tiers>tier,omitempty"`
 
+	// The following are only valid for usage-based add-ons.
+	AddOnType       string  `xml:"add_on_type,omitempty"`
+	UsageType       string  `xml:"usage_type,omitempty"`
+	UsagePercentage float64 `xml:"usage_percentage,omitempty"`
+	MeasuredUnitID  int64   `xml:"measured_unit_id,omitempty"`
+
 	// The following are only valid with an `Avalara for Communications` integration
 	AvalaraTransactionType int `xml:"avalara_transaction_type,omitempty"`
 	AvalaraServiceType     int `xml:"avalara_service_type,omitempty"`
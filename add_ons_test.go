@@ -32,6 +32,18 @@ func TestAddOns_Encoding(t *testing.T) {
 				</add_on>
 			`),
 		},
+		{
+			v: recurly.AddOn{Code: "storage", AddOnType: recurly.AddOnTypeUsage, UsageType: recurly.UsageTypePercentage, UsagePercentage: 1.5, MeasuredUnitID: 3473591245469944008},
+			expected: MustCompactString(`
+				<add_on>
+					<add_on_code>storage</add_on_code>
+					<add_on_type>usage</add_on_type>
+					<usage_type>percentage</usage_type>
+					<usage_percentage>1.5</usage_percentage>
+					<measured_unit_id>3473591245469944008</measured_unit_id>
+				</add_on>
+			`),
+		},
 		{
 			v: recurly.AddOn{Name: "IP Addresses"},
 			expected: MustCompactString(`
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // BillingService manages the interactions for billing.
@@ -121,6 +124,166 @@ func (b Billing) Type() string {
 	return ""
 }
 
+// Validate checks the raw credit card or bank account fields on b before
+// they are sent to Recurly. Card numbers must pass the Luhn check and
+// must not be expired as of now; routing numbers must pass the ABA checksum.
+// If b holds neither a card number nor a routing number, nil is returned.
+// The returned error is a *ValidationError.
+func (b Billing) Validate(now time.Time) error {
+	if b.Number > 0 {
+		number := strconv.Itoa(b.Number)
+		if len(number) < 12 || len(number) > 19 || !LuhnValid(number) {
+			return &ValidationError{Field: "number", Symbol: "invalid", Description: "is not a valid credit card number"}
+		} else if b.Month < 1 || b.Month > 12 {
+			return &ValidationError{Field: "month", Symbol: "invalid", Description: "is not a valid month"}
+		} else if b.Year < 1 {
+			return &ValidationError{Field: "year", Symbol: "invalid", Description: "is not a valid year"}
+		} else if b.Expired(now) {
+			return &ValidationError{Field: "year", Symbol: "expired", Description: "is expired or has an invalid expiration date"}
+		}
+	}
+	if b.RoutingNumber != "" && !ValidRoutingNumber(b.RoutingNumber) {
+		return &ValidationError{Field: "routing_number", Symbol: "invalid", Description: "is not a valid routing number"}
+	}
+	return nil
+}
+
+// Expired returns true if the card expiration date on b is before now.
+// Cards expire at the end of their expiration month.
+func (b Billing) Expired(now time.Time) bool {
+	// time.Date normalizes month 13 into January of the following year.
+	expiry := time.Date(b.Year, time.Month(b.Month)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !now.UTC().Before(expiry)
+}
+
+// Masked returns a copy of b that is safe to log. The raw card number is
+// replaced by FirstSix, LastFour and CardType, the verification value is
+// removed, and all but the last four digits of a bank account number are
+// masked.
+func (b Billing) Masked() Billing {
+	if b.Number > 0 {
+		number := strconv.Itoa(b.Number)
+		if len(number) >= 10 {
+			b.FirstSix, b.LastFour = number[:6], number[len(number)-4:]
+		}
+		if b.CardType == "" {
+			b.CardType = CardTypeFromNumber(number)
+		}
+	}
+	b.Number = 0
+	b.VerificationValue = 0
+	if n := len(b.AccountNumber); n > 4 {
+		b.AccountNumber = strings.Repeat("*", n-4) + b.AccountNumber[n-4:]
+	}
+	return b
+}
+
+// LuhnValid returns true if number passes the Luhn (mod 10) checksum.
+// Spaces and dashes are ignored; any other non-digit returns false.
+func LuhnValid(number string) bool {
+	number = stripCardNumber(number)
+	if number == "" {
+		return false
+	}
+
+	var sum int
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		d := int(number[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// cardRanges maps BIN (issuer identification number) ranges to card types.
+// Order matters: more specific ranges are listed before broader ones.
+var cardRanges = []struct {
+	cardType string
+	digits   int
+	low      int
+	high     int
+}{
+	{CardTypeForbrugsforeningen, 6, 600722, 600722},
+	{CardTypeDankort, 4, 5019, 5019},
+	{CardTypeLaser, 4, 6304, 6304},
+	{CardTypeLaser, 4, 6706, 6706},
+	{CardTypeLaser, 4, 6709, 6709},
+	{CardTypeLaser, 4, 6771, 6771},
+	{CardTypeDiscover, 6, 622126, 622925},
+	{CardTypeDiscover, 4, 6011, 6011},
+	{CardTypeDiscover, 3, 644, 649},
+	{CardTypeDiscover, 2, 65, 65},
+	{CardTypeMaestro, 4, 5018, 5018},
+	{CardTypeMaestro, 4, 5020, 5020},
+	{CardTypeMaestro, 4, 5038, 5038},
+	{CardTypeMaestro, 4, 5893, 5893},
+	{CardTypeMaestro, 4, 6759, 6759},
+	{CardTypeMaestro, 4, 6761, 6763},
+	{CardTypeMaestro, 4, 6390, 6390},
+	{CardTypeVisa, 1, 4, 4},
+	{CardTypeMaster, 2, 51, 55},
+	{CardTypeMaster, 4, 2221, 2720},
+	{CardTypeAmericanExpress, 2, 34, 34},
+	{CardTypeAmericanExpress, 2, 37, 37},
+	{CardTypeDinersClub, 3, 300, 305},
+	{CardTypeDinersClub, 3, 309, 309},
+	{CardTypeDinersClub, 2, 36, 36},
+	{CardTypeDinersClub, 2, 38, 39},
+	{CardTypeJCB, 4, 3528, 3589},
+}
+
+// CardTypeFromNumber returns the CardType* constant matching the BIN of
+// number, or an empty string if the card type cannot be determined.
+// Spaces and dashes are ignored.
+func CardTypeFromNumber(number string) string {
+	number = stripCardNumber(number)
+	for _, r := range cardRanges {
+		if len(number) < r.digits {
+			continue
+		}
+		prefix, err := strconv.Atoi(number[:r.digits])
+		if err != nil {
+			return ""
+		} else if prefix >= r.low && prefix <= r.high {
+			return r.cardType
+		}
+	}
+	return ""
+}
+
+// ValidRoutingNumber returns true if routingNumber is a nine digit ABA
+// routing number with a valid checksum.
+func ValidRoutingNumber(routingNumber string) bool {
+	if len(routingNumber) != 9 {
+		return false
+	}
+
+	weights := [3]int{3, 7, 1}
+	var sum int
+	for i := 0; i < len(routingNumber); i++ {
+		d := int(routingNumber[i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		sum += d * weights[i%3]
+	}
+	return sum%10 == 0
+}
+
+// stripCardNumber removes spaces and dashes from a card number.
+func stripCardNumber(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
 var _ BillingService = &billingImpl{}
 
 // billingImpl implements BillingService.
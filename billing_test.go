@@ -186,6 +186,159 @@ func TestBilling_Type(t *testing.T) {
 	})
 }
 
+func TestBilling_Validate(t *testing.T) {
+	now := MustParseTime("2020-05-15T12:00:00Z")
+	tests := []struct {
+		name string
+		v    recurly.Billing
+		err  *recurly.ValidationError
+	}{
+		{name: "Empty"},
+		{name: "Card", v: recurly.Billing{Number: 4111111111111111, Month: 5, Year: 2020}},
+		{
+			name: "InvalidNumber",
+			v:    recurly.Billing{Number: 4111111111111112, Month: 5, Year: 2020},
+			err:  &recurly.ValidationError{Field: "number", Symbol: "invalid", Description: "is not a valid credit card number"},
+		},
+		{
+			name: "ShortNumber",
+			v:    recurly.Billing{Number: 42, Month: 5, Year: 2020},
+			err:  &recurly.ValidationError{Field: "number", Symbol: "invalid", Description: "is not a valid credit card number"},
+		},
+		{
+			name: "InvalidMonth",
+			v:    recurly.Billing{Number: 4111111111111111, Month: 13, Year: 2020},
+			err:  &recurly.ValidationError{Field: "month", Symbol: "invalid", Description: "is not a valid month"},
+		},
+		{
+			name: "Expired",
+			v:    recurly.Billing{Number: 4111111111111111, Month: 4, Year: 2020},
+			err:  &recurly.ValidationError{Field: "year", Symbol: "expired", Description: "is expired or has an invalid expiration date"},
+		},
+		{name: "Bank", v: recurly.Billing{RoutingNumber: "065400137", AccountNumber: "0123456789"}},
+		{
+			name: "InvalidRoutingNumber",
+			v:    recurly.Billing{RoutingNumber: "065400138", AccountNumber: "0123456789"},
+			err:  &recurly.ValidationError{Field: "routing_number", Symbol: "invalid", Description: "is not a valid routing number"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.v.Validate(now)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if diff := cmp.Diff(err, tt.err); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestBilling_Expired(t *testing.T) {
+	b := recurly.Billing{Month: 12, Year: 2020}
+	if b.Expired(MustParseTime("2020-12-31T23:59:59Z")) {
+		t.Fatal("expected card to be valid through the end of the month")
+	} else if !b.Expired(MustParseTime("2021-01-01T00:00:00Z")) {
+		t.Fatal("expected card to be expired")
+	}
+}
+
+func TestBilling_Masked(t *testing.T) {
+	b := recurly.Billing{
+		FirstName:         "Verena",
+		Number:            4111111111111111,
+		Month:             5,
+		Year:              2020,
+		VerificationValue: 111,
+		RoutingNumber:     "065400137",
+		AccountNumber:     "0123456789",
+	}
+	if diff := cmp.Diff(b.Masked(), recurly.Billing{
+		FirstName:     "Verena",
+		FirstSix:      "411111",
+		LastFour:      "1111",
+		CardType:      recurly.CardTypeVisa,
+		Month:         5,
+		Year:          2020,
+		RoutingNumber: "065400137",
+		AccountNumber: "******6789",
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestLuhnValid(t *testing.T) {
+	tests := []struct {
+		number string
+		valid  bool
+	}{
+		{number: "4111111111111111", valid: true},
+		{number: "4111 1111 1111 1111", valid: true},
+		{number: "4111-1111-1111-1111", valid: true},
+		{number: "378282246310005", valid: true},
+		{number: "4111111111111112"},
+		{number: "4111a11111111111"},
+		{number: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if valid := recurly.LuhnValid(tt.number); valid != tt.valid {
+				t.Fatalf("unexpected result: %t", valid)
+			}
+		})
+	}
+}
+
+func TestCardTypeFromNumber(t *testing.T) {
+	tests := []struct {
+		number   string
+		expected string
+	}{
+		{number: "4111111111111111", expected: recurly.CardTypeVisa},
+		{number: "5555555555554444", expected: recurly.CardTypeMaster},
+		{number: "2223000048400011", expected: recurly.CardTypeMaster},
+		{number: "378282246310005", expected: recurly.CardTypeAmericanExpress},
+		{number: "6011111111111117", expected: recurly.CardTypeDiscover},
+		{number: "6221260000000000", expected: recurly.CardTypeDiscover},
+		{number: "30569309025904", expected: recurly.CardTypeDinersClub},
+		{number: "3530111333300000", expected: recurly.CardTypeJCB},
+		{number: "5019717010103742", expected: recurly.CardTypeDankort},
+		{number: "6007220000000004", expected: recurly.CardTypeForbrugsforeningen},
+		{number: "6304000000000000", expected: recurly.CardTypeLaser},
+		{number: "6759649826438453", expected: recurly.CardTypeMaestro},
+		{number: "6040000000000000"},
+		{number: "0604000000000000"},
+		{number: "9999999999999999"},
+		{number: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			if typ := recurly.CardTypeFromNumber(tt.number); typ != tt.expected {
+				t.Fatalf("unexpected card type: %q", typ)
+			}
+		})
+	}
+}
+
+func TestValidRoutingNumber(t *testing.T) {
+	if !recurly.ValidRoutingNumber("065400137") {
+		t.Fatal("expected valid routing number")
+	} else if recurly.ValidRoutingNumber("065400138") {
+		t.Fatal("expected invalid checksum")
+	} else if recurly.ValidRoutingNumber("06540013") {
+		t.Fatal("expected invalid length")
+	} else if recurly.ValidRoutingNumber("06540013a") {
+		t.Fatal("expected invalid characters")
+	}
+}
+
 func TestBilling_Get(t *testing.T) {
 	t.Run("OK", func(t *testing.T) {
 		client, s := recurly.NewTestServer()
@@ -0,0 +1,206 @@
+package recurly
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// GiftCardsService manages the interactions for gift cards.
+type GiftCardsService interface {
+	// List returns a pager to paginate gift cards. PagerOptions are used to
+	// optionally filter the results.
+	//
+	// https://dev.recurly.com/docs/list-gift-cards
+	List(opts *PagerOptions) Pager
+
+	// Get retrieves a gift card. If the gift card does not exist,
+	// a nil gift card and nil error are returned.
+	//
+	// https://dev.recurly.com/docs/lookup-a-gift-card
+	Get(ctx context.Context, id int64) (*GiftCard, error)
+
+	// Preview previews a gift card purchase. This runs the validations on the
+	// gifter account's billing info but does not create the gift card or
+	// charge the gifter.
+	//
+	// https://dev.recurly.com/docs/preview-a-gift-card
+	Preview(ctx context.Context, g GiftCard) (*GiftCard, error)
+
+	// Create purchases a gift card. g.GifterAccount is charged for the
+	// gift card and the card is delivered according to g.Delivery.
+	//
+	// https://dev.recurly.com/docs/create-a-gift-card
+	Create(ctx context.Context, g GiftCard) (*GiftCard, error)
+
+	// Redeem redeems a gift card by its redemption code and applies its
+	// balance as a credit on the recipient account.
+	//
+	// https://dev.recurly.com/docs/redeem-a-gift-card-on-an-account
+	Redeem(ctx context.Context, redemptionCode string, accountCode string) (*GiftCard, error)
+}
+
+// Gift card delivery method constants.
+const (
+	GiftCardDeliveryMethodEmail = "email"
+	GiftCardDeliveryMethodPost  = "post"
+)
+
+// GiftCard represents a gift card purchased by a gifter account, optionally
+// redeemed by a recipient account.
+//
+// https://dev.recurly.com/docs/gift-card-object
+type GiftCard struct {
+	XMLName                 xml.Name          `xml:"gift_card"`
+	ID                      int64             `xml:"id,omitempty"`
+	RedemptionCode          string            `xml:"redemption_code,omitempty"`
+	ProductCode             string            `xml:"product_code,omitempty"`
+	UnitAmountInCents       int               `xml:"unit_amount_in_cents,omitempty"`
+	BalanceInCents          int               `xml:"balance_in_cents,omitempty"`
+	Currency                string            `xml:"currency,omitempty"`
+	Delivery                *GiftCardDelivery `xml:"delivery,omitempty"`
+	GifterAccount           *Account          `xml:"-"` // Create/preview only
+	GifterAccountCode       string            `xml:"-"`
+	RecipientAccountCode    string            `xml:"-"`
+	PurchaseInvoiceNumber   int               `xml:"-"`
+	RedemptionInvoiceNumber int               `xml:"-"`
+	CreatedAt               NullTime          `xml:"created_at,omitempty"`
+	UpdatedAt               NullTime          `xml:"updated_at,omitempty"`
+	DeliveredAt             NullTime          `xml:"delivered_at,omitempty"`
+	RedeemedAt              NullTime          `xml:"redeemed_at,omitempty"`
+	CanceledAt              NullTime          `xml:"canceled_at,omitempty"`
+}
+
+// GiftCardDelivery holds the details of how and when a gift card is
+// delivered to its recipient.
+type GiftCardDelivery struct {
+	XMLName         xml.Name `xml:"delivery"`
+	Method          string   `xml:"method,omitempty"`
+	EmailAddress    string   `xml:"email_address,omitempty"`
+	DeliverAt       NullTime `xml:"deliver_at,omitempty"`
+	FirstName       string   `xml:"first_name,omitempty"`
+	LastName        string   `xml:"last_name,omitempty"`
+	Address         *Address `xml:"address,omitempty"`
+	GifterName      string   `xml:"gifter_name,omitempty"`
+	PersonalMessage string   `xml:"personal_message,omitempty"`
+}
+
+// MarshalXML marshals gift cards, sending the gifter account as
+// <gifter_account>.
+func (g GiftCard) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type giftCardAlias GiftCard
+	v := struct {
+		giftCardAlias
+		XMLName       xml.Name       `xml:"gift_card"`
+		GifterAccount *gifterAccount `xml:"gifter_account,omitempty"`
+	}{giftCardAlias: giftCardAlias(g)}
+	if g.GifterAccount != nil {
+		v.GifterAccount = &gifterAccount{Account: *g.GifterAccount}
+	}
+	return e.Encode(v)
+}
+
+// UnmarshalXML unmarshals gift cards and handles intermediary state during
+// unmarshaling for types like href.
+func (g *GiftCard) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type giftCardAlias GiftCard
+	var v struct {
+		XMLName xml.Name `xml:"gift_card"`
+		giftCardAlias
+		GifterAccountCode       href    `xml:"gifter_account"`
+		RecipientAccountCode    href    `xml:"recipient_account"`
+		PurchaseInvoiceNumber   hrefInt `xml:"purchase_invoice"`
+		RedemptionInvoiceNumber hrefInt `xml:"redemption_invoice"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	*g = GiftCard(v.giftCardAlias)
+	g.XMLName = v.XMLName
+	g.GifterAccountCode = v.GifterAccountCode.LastPartOfPath()
+	g.RecipientAccountCode = v.RecipientAccountCode.LastPartOfPath()
+	g.PurchaseInvoiceNumber = v.PurchaseInvoiceNumber.LastPartOfPath()
+	g.RedemptionInvoiceNumber = v.RedemptionInvoiceNumber.LastPartOfPath()
+	return nil
+}
+
+// gifterAccount marshals an account as <gifter_account>.
+type gifterAccount struct {
+	Account
+}
+
+// MarshalXML marshals the account using the provided start element name.
+func (a gifterAccount) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	return e.EncodeElement(a.Account, start)
+}
+
+var _ GiftCardsService = &giftCardsImpl{}
+
+// giftCardsImpl implements GiftCardsService.
+type giftCardsImpl serviceImpl
+
+func (s *giftCardsImpl) List(opts *PagerOptions) Pager {
+	return s.client.newPager("GET", "/gift_cards", opts)
+}
+
+func (s *giftCardsImpl) Get(ctx context.Context, id int64) (*GiftCard, error) {
+	path := fmt.Sprintf("/gift_cards/%d", id)
+	req, err := s.client.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst GiftCard
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		if e, ok := err.(*ClientError); ok && e.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *giftCardsImpl) Preview(ctx context.Context, g GiftCard) (*GiftCard, error) {
+	req, err := s.client.newRequest("POST", "/gift_cards/preview", g)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst GiftCard
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *giftCardsImpl) Create(ctx context.Context, g GiftCard) (*GiftCard, error) {
+	req, err := s.client.newRequest("POST", "/gift_cards", g)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst GiftCard
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *giftCardsImpl) Redeem(ctx context.Context, redemptionCode string, accountCode string) (*GiftCard, error) {
+	path := fmt.Sprintf("/gift_cards/%s/redeem", redemptionCode)
+	req, err := s.client.newRequest("POST", path, struct {
+		XMLName     xml.Name `xml:"recipient_account"`
+		AccountCode string   `xml:"account_code"`
+	}{AccountCode: accountCode})
+	if err != nil {
+		return nil, err
+	}
+
+	var dst GiftCard
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
@@ -0,0 +1,246 @@
+package recurly_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Ensure structs are encoded to XML properly.
+func TestGiftCards_Encoding(t *testing.T) {
+	tests := []struct {
+		v        recurly.GiftCard
+		expected string
+	}{
+		{
+			expected: MustCompactString(`
+				<gift_card></gift_card>
+			`),
+		},
+		{
+			v: recurly.GiftCard{ProductCode: "gift_card", UnitAmountInCents: 2000, Currency: "USD"},
+			expected: MustCompactString(`
+				<gift_card>
+					<product_code>gift_card</product_code>
+					<unit_amount_in_cents>2000</unit_amount_in_cents>
+					<currency>USD</currency>
+				</gift_card>
+			`),
+		},
+		{
+			v: recurly.GiftCard{
+				ProductCode:       "gift_card",
+				UnitAmountInCents: 2000,
+				Currency:          "USD",
+				Delivery: &recurly.GiftCardDelivery{
+					Method:          recurly.GiftCardDeliveryMethodEmail,
+					EmailAddress:    "benjamin@example.com",
+					FirstName:       "Benjamin",
+					LastName:        "Example",
+					GifterName:      "Verena",
+					PersonalMessage: "Happy birthday!",
+				},
+				GifterAccount: &recurly.Account{
+					Code:        "1",
+					BillingInfo: &recurly.Billing{Token: "507c7f79bcf86cd7994f6c0e"},
+				},
+			},
+			expected: MustCompactString(`
+				<gift_card>
+					<product_code>gift_card</product_code>
+					<unit_amount_in_cents>2000</unit_amount_in_cents>
+					<currency>USD</currency>
+					<delivery>
+						<method>email</method>
+						<email_address>benjamin@example.com</email_address>
+						<first_name>Benjamin</first_name>
+						<last_name>Example</last_name>
+						<gifter_name>Verena</gifter_name>
+						<personal_message>Happy birthday!</personal_message>
+					</delivery>
+					<gifter_account>
+						<account_code>1</account_code>
+						<billing_info>
+							<token_id>507c7f79bcf86cd7994f6c0e</token_id>
+						</billing_info>
+					</gifter_account>
+				</gift_card>
+			`),
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			if err := xml.NewEncoder(buf).Encode(tt.v); err != nil {
+				t.Fatal(err)
+			} else if buf.String() != tt.expected {
+				t.Fatal(buf.String())
+			}
+		})
+	}
+}
+
+func TestGiftCards_List(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	var invocations int
+	s.HandleFunc("GET", "/v2/gift_cards", func(w http.ResponseWriter, r *http.Request) {
+		invocations++
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("gift_cards.xml"))
+	}, t)
+
+	pager := client.GiftCards.List(nil)
+	for pager.Next() {
+		var g []recurly.GiftCard
+		if err := pager.Fetch(context.Background(), &g); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected s to be invoked")
+		} else if diff := cmp.Diff(g, []recurly.GiftCard{*NewTestGiftCard()}); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+	if invocations != 1 {
+		t.Fatalf("unexpected number of invocations: %d", invocations)
+	}
+}
+
+func TestGiftCards_Get(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/gift_cards/2005384587788419083", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustOpenFile("gift_card.xml"))
+		}, t)
+
+		if g, err := client.GiftCards.Get(context.Background(), 2005384587788419083); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(g, NewTestGiftCard()); diff != "" {
+			t.Fatal(diff)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		}
+	})
+
+	// Ensure a 404 returns nil values.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/gift_cards/2005384587788419083", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, t)
+
+		if g, err := client.GiftCards.Get(context.Background(), 2005384587788419083); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if err != nil {
+			t.Fatal(err)
+		} else if g != nil {
+			t.Fatalf("expected nil: %#v", g)
+		}
+	})
+}
+
+func TestGiftCards_Preview(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("POST", "/v2/gift_cards/preview", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("gift_card.xml"))
+	}, t)
+
+	if g, err := client.GiftCards.Preview(context.Background(), recurly.GiftCard{}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(g, NewTestGiftCard()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestGiftCards_Create(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("POST", "/v2/gift_cards", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write(MustOpenFile("gift_card.xml"))
+	}, t)
+
+	if g, err := client.GiftCards.Create(context.Background(), recurly.GiftCard{}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(g, NewTestGiftCard()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestGiftCards_Redeem(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("POST", "/v2/gift_cards/AE1DB8F9A2C37E5A/redeem", func(w http.ResponseWriter, r *http.Request) {
+		if str := MustReadAllString(r.Body); str != MustCompactString(`
+			<recipient_account>
+				<account_code>2</account_code>
+			</recipient_account>
+		`) {
+			t.Fatal(str)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("gift_card.xml"))
+	}, t)
+
+	if g, err := client.GiftCards.Redeem(context.Background(), "AE1DB8F9A2C37E5A", "2"); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(g, NewTestGiftCard()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+// Returns a gift card corresponding to testdata/gift_card.xml.
+func NewTestGiftCard() *recurly.GiftCard {
+	return &recurly.GiftCard{
+		XMLName:                 xml.Name{Local: "gift_card"},
+		ID:                      2005384587788419083,
+		RedemptionCode:          "AE1DB8F9A2C37E5A",
+		ProductCode:             "gift_card",
+		UnitAmountInCents:       2000,
+		Currency:                "USD",
+		GifterAccountCode:       "1",
+		RecipientAccountCode:    "2",
+		PurchaseInvoiceNumber:   1001,
+		RedemptionInvoiceNumber: 1002,
+		Delivery: &recurly.GiftCardDelivery{
+			XMLName:      xml.Name{Local: "delivery"},
+			Method:       recurly.GiftCardDeliveryMethodEmail,
+			EmailAddress: "benjamin@example.com",
+			FirstName:    "Benjamin",
+			LastName:     "Example",
+			Address: &recurly.Address{
+				XMLName: xml.Name{Local: "address"},
+			},
+			GifterName:      "Verena",
+			PersonalMessage: "Happy birthday!",
+		},
+		CreatedAt:   recurly.NewTime(MustParseTime("2016-08-03T20:37:27Z")),
+		UpdatedAt:   recurly.NewTime(MustParseTime("2016-08-04T20:37:27Z")),
+		DeliveredAt: recurly.NewTime(MustParseTime("2016-08-03T20:37:27Z")),
+		RedeemedAt:  recurly.NewTime(MustParseTime("2016-08-04T20:37:27Z")),
+	}
+}
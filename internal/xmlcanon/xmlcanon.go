@@ -0,0 +1,43 @@
+// Package xmlcanon compares XML documents independent of formatting.
+package xmlcanon
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Canonical returns b re-encoded without formatting, comments or processing
+// instructions, and with attributes sorted, so that equivalent documents
+// are equal.
+func Canonical(b []byte) (string, error) {
+	var buf bytes.Buffer
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return buf.String(), nil
+		} else if err != nil {
+			return "", err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			attrs := make([]string, len(tok.Attr))
+			for i, a := range tok.Attr {
+				attrs[i] = fmt.Sprintf(" %s=%q", a.Name.Local, a.Value)
+			}
+			sort.Strings(attrs)
+			buf.WriteString("<" + tok.Name.Local + strings.Join(attrs, "") + ">")
+		case xml.EndElement:
+			buf.WriteString("</" + tok.Name.Local + ">")
+		case xml.CharData:
+			if s := strings.TrimSpace(string(tok)); s != "" {
+				xml.EscapeText(&buf, []byte(s))
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package xmlcanon_test
+
+import (
+	"testing"
+
+	"github.com/blacklightcms/recurly/internal/xmlcanon"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{
+			name: "Formatting",
+			a:    "<account>\n  <account_code>1</account_code>\n</account>",
+			b:    "<account><account_code>1</account_code></account>",
+		},
+		{
+			name: "ProcInst",
+			a:    `<?xml version="1.0" encoding="UTF-8"?><account></account>`,
+			b:    "<account/>",
+		},
+		{
+			name: "Comment",
+			a:    "<account><!-- code --><account_code>1</account_code></account>",
+			b:    "<account><account_code>1</account_code></account>",
+		},
+		{
+			name: "Attributes",
+			a:    `<total type="integer" nil="nil"></total>`,
+			b:    `<total nil="nil" type="integer"></total>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := xmlcanon.Canonical([]byte(tt.a))
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := xmlcanon.Canonical([]byte(tt.b))
+			if err != nil {
+				t.Fatal(err)
+			} else if a != b {
+				t.Fatalf("unexpected canonical xml: %s != %s", a, b)
+			}
+		})
+	}
+
+	t.Run("Different", func(t *testing.T) {
+		a, _ := xmlcanon.Canonical([]byte("<account_code>1</account_code>"))
+		b, _ := xmlcanon.Canonical([]byte("<account_code>2</account_code>"))
+		if a == b {
+			t.Fatalf("expected %s != %s", a, b)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := xmlcanon.Canonical([]byte("<account>")); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package recurly
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pdfLanguages is the set of languages and locales accepted when retrieving
+// invoice PDFs.
+//
+// https://dev.recurly.com/docs/retrieve-a-pdf-invoice
+var pdfLanguages = map[string]struct{}{
+	"English":    {},
+	"Danish":     {},
+	"German":     {},
+	"Spanish":    {},
+	"Finnish":    {},
+	"French":     {},
+	"Hindi":      {},
+	"Italian":    {},
+	"Japanese":   {},
+	"Korean":     {},
+	"Norwegian":  {},
+	"Dutch":      {},
+	"Polish":     {},
+	"Portuguese": {},
+	"Romanian":   {},
+	"Russian":    {},
+	"Slovak":     {},
+	"Swedish":    {},
+	"Turkish":    {},
+	"Chinese":    {},
+
+	"da-DK": {},
+	"de-CH": {},
+	"de-DE": {},
+	"en-AU": {},
+	"en-CA": {},
+	"en-GB": {},
+	"en-IE": {},
+	"en-NZ": {},
+	"en-US": {},
+	"es-ES": {},
+	"es-MX": {},
+	"es-US": {},
+	"fi-FI": {},
+	"fr-BE": {},
+	"fr-CA": {},
+	"fr-CH": {},
+	"fr-FR": {},
+	"hi-IN": {},
+	"it-IT": {},
+	"ja-JP": {},
+	"ko-KR": {},
+	"nb-NO": {},
+	"nl-BE": {},
+	"nl-NL": {},
+	"pl-PL": {},
+	"pt-BR": {},
+	"pt-PT": {},
+	"ro-RO": {},
+	"ru-RU": {},
+	"sk-SK": {},
+	"sv-SE": {},
+	"tr-TR": {},
+	"zh-CN": {},
+}
+
+// ValidPDFLanguage returns true if language is one of the languages or
+// locales accepted when retrieving invoice PDFs, such as "French" or
+// "fr-CA". The comparison is case-insensitive.
+func ValidPDFLanguage(language string) bool {
+	_, ok := lookupPDFLanguage(language)
+	return ok
+}
+
+// pdfLanguage returns the Accept-Language value for language, defaulting
+// to English if language is empty or unsupported.
+func pdfLanguage(language string) string {
+	if v, ok := lookupPDFLanguage(language); ok {
+		return v
+	}
+	return "English"
+}
+
+// lookupPDFLanguage returns the canonical spelling of language.
+func lookupPDFLanguage(language string) (string, bool) {
+	if _, ok := pdfLanguages[language]; ok {
+		return language, true
+	}
+	for k := range pdfLanguages {
+		if strings.EqualFold(k, language) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// DownloadInvoicePDFsToDir streams the PDF of every invoice yielded by p
+// into dir, one file per invoice named after its number (including any
+// prefix), e.g. 1001.pdf. Invoices that no longer exist are skipped. The
+// number of PDFs written is returned.
+func DownloadInvoicePDFsToDir(ctx context.Context, s InvoicesService, p Pager, language string, dir string) (int, error) {
+	return downloadInvoicePDFs(ctx, s, p, language, func(name string) (io.WriteCloser, error) {
+		return os.Create(filepath.Join(dir, name))
+	}, func(name string) {
+		os.Remove(filepath.Join(dir, name))
+	})
+}
+
+// DownloadInvoicePDFsToZip streams the PDF of every invoice yielded by p
+// into a zip archive written to w. Entries are named the same way as
+// DownloadInvoicePDFsToDir. Invoices that no longer exist are skipped. The
+// number of PDFs written is returned.
+func DownloadInvoicePDFsToZip(ctx context.Context, s InvoicesService, p Pager, language string, w io.Writer) (int, error) {
+	zw := zip.NewWriter(w)
+	n, err := downloadInvoicePDFs(ctx, s, p, language, func(name string) (io.WriteCloser, error) {
+		f, err := zw.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		return nopWriteCloser{f}, nil
+	}, nil)
+	if err != nil {
+		zw.Close()
+		return n, err
+	}
+	return n, zw.Close()
+}
+
+// downloadInvoicePDFs pages through invoices and copies each PDF into the
+// writer returned by create. If copying fails, remove (if set) is called
+// to discard the partially written destination.
+func downloadInvoicePDFs(ctx context.Context, s InvoicesService, p Pager, language string, create func(name string) (io.WriteCloser, error), remove func(name string)) (int, error) {
+	var n int
+	for p.Next() {
+		var invoices []Invoice
+		if err := p.Fetch(ctx, &invoices); err != nil {
+			return n, err
+		}
+
+		for _, inv := range invoices {
+			rc, err := s.OpenPDF(ctx, inv.InvoiceNumber, language)
+			if err != nil {
+				return n, err
+			} else if rc == nil {
+				continue
+			}
+
+			name := fmt.Sprintf("%s%d.pdf", inv.InvoiceNumberPrefix, inv.InvoiceNumber)
+			if err := copyPDF(rc, name, create); err != nil {
+				if remove != nil {
+					remove(name)
+				}
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// copyPDF copies rc into the destination for name, closing both.
+func copyPDF(rc io.ReadCloser, name string, create func(name string) (io.WriteCloser, error)) error {
+	defer rc.Close()
+
+	w, err := create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, rc); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// nopWriteCloser adds a no-op Close method to an io.Writer.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
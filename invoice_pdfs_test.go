@@ -0,0 +1,128 @@
+package recurly_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+)
+
+func TestValidPDFLanguage(t *testing.T) {
+	for _, language := range []string{"English", "french", "fr-CA", "ZH-cn"} {
+		if !recurly.ValidPDFLanguage(language) {
+			t.Fatalf("expected %q to be valid", language)
+		}
+	}
+	for _, language := range []string{"", "Klingon", "fr_CA"} {
+		if recurly.ValidPDFLanguage(language) {
+			t.Fatalf("expected %q to be invalid", language)
+		}
+	}
+}
+
+func TestInvoices_DownloadInvoicePDFsToDir(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("GET", "/v2/invoices", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("invoices.xml"))
+	}, t)
+	s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+		if h := r.Header.Get("Accept-Language"); h != "de-DE" {
+			t.Fatalf("unexpected 'Accept-Language' header: %q", h)
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("binary pdf text"))
+	}, t)
+
+	dir, err := ioutil.TempDir("", "recurly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if n, err := recurly.DownloadInvoicePDFsToDir(context.Background(), client.Invoices, client.Invoices.List(nil), "de-DE", dir); err != nil {
+		t.Fatal(err)
+	} else if n != 1 {
+		t.Fatalf("unexpected count: %d", n)
+	} else if b, err := ioutil.ReadFile(filepath.Join(dir, "5558.pdf")); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "binary pdf text" {
+		t.Fatal(string(b))
+	}
+}
+
+func TestInvoices_DownloadInvoicePDFsToZip(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustOpenFile("invoices.xml"))
+		}, t)
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/pdf")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("binary pdf text"))
+		}, t)
+
+		var buf bytes.Buffer
+		if n, err := recurly.DownloadInvoicePDFsToZip(context.Background(), client.Invoices, client.Invoices.List(nil), "English", &buf); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("unexpected count: %d", n)
+		}
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatal(err)
+		} else if len(zr.File) != 1 {
+			t.Fatalf("unexpected number of files: %d", len(zr.File))
+		} else if zr.File[0].Name != "5558.pdf" {
+			t.Fatalf("unexpected file name: %q", zr.File[0].Name)
+		}
+
+		rc, err := zr.File[0].Open()
+		if err != nil {
+			t.Fatal(err)
+		} else if str := MustReadAllString(rc); str != "binary pdf text" {
+			t.Fatal(str)
+		}
+	})
+
+	// Ensure invoices that no longer exist are skipped.
+	t.Run("NotFound", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustOpenFile("invoices.xml"))
+		}, t)
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, t)
+
+		var buf bytes.Buffer
+		if n, err := recurly.DownloadInvoicePDFsToZip(context.Background(), client.Invoices, client.Invoices.List(nil), "English", &buf); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("unexpected count: %d", n)
+		}
+
+		if zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len())); err != nil {
+			t.Fatal(err)
+		} else if len(zr.File) != 0 {
+			t.Fatalf("unexpected number of files: %d", len(zr.File))
+		}
+	})
+}
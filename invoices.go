@@ -5,6 +5,8 @@ import (
 	"context"
 	"encoding/xml"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 )
 
@@ -35,6 +37,24 @@ type InvoicesService interface {
 	// https://dev.recurly.com/docs/retrieve-a-pdf-invoice
 	GetPDF(ctx context.Context, invoiceNumber int, language string) (*bytes.Buffer, error)
 
+	// OpenPDF retrieves an invoice as a PDF and returns the unread response
+	// body so it can be streamed rather than buffered in memory. The caller
+	// must close the returned io.ReadCloser. If the invoice does not exist,
+	// a nil io.ReadCloser and nil error are returned. language may be any
+	// of the names or locales accepted by ValidPDFLanguage; otherwise English
+	// is used.
+	// A *ContentTypeError is returned if the response is not a PDF.
+	//
+	// https://dev.recurly.com/docs/retrieve-a-pdf-invoice
+	OpenPDF(ctx context.Context, invoiceNumber int, language string) (io.ReadCloser, error)
+
+	// WritePDF streams an invoice PDF into w and returns the number of bytes
+	// written. If the invoice does not exist, nothing is written and 0 with
+	// a nil error is returned. See OpenPDF for language handling.
+	//
+	// https://dev.recurly.com/docs/retrieve-a-pdf-invoice
+	WritePDF(ctx context.Context, invoiceNumber int, language string, w io.Writer) (int64, error)
+
 	// Preview allows you to display the invoice details, including estimated
 	// tax, before you post it.
 	//
@@ -156,6 +176,7 @@ const (
 type Invoice struct {
 	XMLName                 xml.Name        `xml:"invoice,omitempty"`
 	AccountCode             string          `xml:"-"`
+	BillForAccountCode      string          `xml:"-"` // Account responsible for payment (parent account for child accounts)
 	Address                 Address         `xml:"-"`
 	OriginalInvoiceNumber   int             `xml:"-"`
 	UUID                    string          `xml:"-"`
@@ -250,6 +271,7 @@ func (i *InvoiceCollection) UnmarshalXML(d *xml.Decoder, start xml.StartElement)
 // invoiceFields is used by custom unmarshal functions.
 type invoiceFields struct {
 	AccountCode             href            `xml:"account,omitempty"`
+	BillForAccountCode      href            `xml:"bill_for_account,omitempty"`
 	Address                 Address         `xml:"address,omitempty"`
 	SubscriptionUUID        href            `xml:"subscription,omitempty"`
 	OriginalInvoiceNumber   hrefInt         `xml:"original_invoice,omitempty"`
@@ -288,6 +310,7 @@ func (i invoiceFields) ToInvoice() Invoice {
 	inv := Invoice{
 		XMLName:                 xml.Name{Local: "invoice"},
 		AccountCode:             i.AccountCode.LastPartOfPath(),
+		BillForAccountCode:      i.BillForAccountCode.LastPartOfPath(),
 		Address:                 i.Address,
 		OriginalInvoiceNumber:   i.OriginalInvoiceNumber.LastPartOfPath(),
 		UUID:                    i.UUID,
@@ -399,20 +422,10 @@ func (s *invoicesImpl) Get(ctx context.Context, invoiceNumber int) (*Invoice, er
 }
 
 func (s *invoicesImpl) GetPDF(ctx context.Context, invoiceNumber int, language string) (*bytes.Buffer, error) {
-	switch language {
-	case "English", "Danish", "German", "Spanish", "French", "Hindi",
-		"Japanese", "Dutch", "Portuguese", "Russian", "Turkish", "Chinese":
-	default:
-		language = "English"
-	}
-
-	path := fmt.Sprintf("/invoices/%d", invoiceNumber)
-	req, err := s.client.newRequest("GET", path, nil)
+	req, err := s.newPDFRequest(invoiceNumber, language)
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("Accept", "application/pdf")
-	req.Header.Set("Accept-Language", language)
 
 	b := new(bytes.Buffer)
 	if _, err := s.client.do(ctx, req, b); err != nil {
@@ -424,6 +437,52 @@ func (s *invoicesImpl) GetPDF(ctx context.Context, invoiceNumber int, language s
 	return b, nil
 }
 
+func (s *invoicesImpl) OpenPDF(ctx context.Context, invoiceNumber int, language string) (io.ReadCloser, error) {
+	req, err := s.newPDFRequest(invoiceNumber, language)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.stream(ctx, req)
+	if err != nil {
+		if e, ok := err.(*ClientError); ok && e.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNoContent {
+		return resp.Body, nil
+	} else if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err != nil || mediaType != "application/pdf" {
+		resp.Body.Close()
+		return nil, &ContentTypeError{Response: resp, Expected: "application/pdf"}
+	}
+	return resp.Body, nil
+}
+
+func (s *invoicesImpl) WritePDF(ctx context.Context, invoiceNumber int, language string, w io.Writer) (int64, error) {
+	rc, err := s.OpenPDF(ctx, invoiceNumber, language)
+	if err != nil {
+		return 0, err
+	} else if rc == nil {
+		return 0, nil
+	}
+	defer rc.Close()
+	return io.Copy(w, rc)
+}
+
+// newPDFRequest returns a request for an invoice PDF in the given language.
+func (s *invoicesImpl) newPDFRequest(invoiceNumber int, language string) (*http.Request, error) {
+	path := fmt.Sprintf("/invoices/%d", invoiceNumber)
+	req, err := s.client.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/pdf")
+	req.Header.Set("Accept-Language", pdfLanguage(language))
+	return req, nil
+}
+
 func (s *invoicesImpl) Preview(ctx context.Context, accountCode string) (*Invoice, error) {
 	path := fmt.Sprintf("/accounts/%s/invoices/preview", accountCode)
 	req, err := s.client.newRequest("POST", path, nil)
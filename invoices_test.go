@@ -115,6 +115,36 @@ func TestInvoices_Get(t *testing.T) {
 		}
 	})
 
+	// Invoices for child accounts billed to their parent reference the
+	// paying account separately.
+	t.Run("ChildAccount", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustCompact([]byte(`
+				<?xml version="1.0" encoding="UTF-8"?>
+				<invoice href="https://your-subdomain.recurly.com/v2/invoices/5558">
+					<account href="https://your-subdomain.recurly.com/v2/accounts/2" />
+					<bill_for_account href="https://your-subdomain.recurly.com/v2/accounts/1" />
+					<uuid>421f7b7d414e4c6792938e7c49d552e9</uuid>
+					<invoice_number type="integer">5558</invoice_number>
+				</invoice>
+			`)))
+		}, t)
+
+		if invoice, err := client.Invoices.Get(context.Background(), 5558); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if invoice.AccountCode != "2" {
+			t.Fatalf("unexpected account code: %q", invoice.AccountCode)
+		} else if invoice.BillForAccountCode != "1" {
+			t.Fatalf("unexpected bill for account code: %q", invoice.BillForAccountCode)
+		}
+	})
+
 	// Ensure a 404 returns nil values.
 	t.Run("ErrNotFound", func(t *testing.T) {
 		client, s := recurly.NewTestServer()
@@ -178,6 +208,140 @@ func TestInvoices_GetPDF(t *testing.T) {
 	})
 }
 
+func TestInvoices_OpenPDF(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			if h := r.Header.Get("Accept"); h != "application/pdf" {
+				t.Fatalf("unexpected 'Accept' header: %q", h)
+			} else if h := r.Header.Get("Accept-Language"); h != "fr-CA" {
+				t.Fatalf("unexpected 'Accept-Language' header: %q", h)
+			}
+
+			w.Header().Set("Content-Type", "application/pdf; charset=binary")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("binary pdf text"))
+		}, t)
+
+		rc, err := client.Invoices.OpenPDF(context.Background(), 5558, "fr-ca")
+		if err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		}
+		defer rc.Close()
+
+		if str := MustReadAllString(rc); str != "binary pdf text" {
+			t.Fatal(str)
+		}
+	})
+
+	// Ensure a 404 returns nil values.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, t)
+
+		if rc, err := client.Invoices.OpenPDF(context.Background(), 5558, "English"); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if err != nil {
+			t.Fatal(err)
+		} else if rc != nil {
+			t.Fatalf("expected nil: %#v", rc)
+		}
+	})
+
+	// Ensure a 204 returns an empty body, as GetPDF does.
+	t.Run("NoContent", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}, t)
+
+		rc, err := client.Invoices.OpenPDF(context.Background(), 5558, "English")
+		if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+
+		if str := MustReadAllString(rc); str != "" {
+			t.Fatal(str)
+		}
+	})
+
+	// Ensure a non-PDF response body returns a ContentTypeError.
+	t.Run("ErrContentType", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html")
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("<html></html>"))
+		}, t)
+
+		if rc, err := client.Invoices.OpenPDF(context.Background(), 5558, "English"); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if e, ok := err.(*recurly.ContentTypeError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if e.Expected != "application/pdf" {
+			t.Fatalf("unexpected expected content type: %q", e.Expected)
+		} else if rc != nil {
+			t.Fatalf("expected nil: %#v", rc)
+		}
+	})
+
+	// Ensure server errors are returned.
+	t.Run("ErrServer", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}, t)
+
+		if _, err := client.Invoices.OpenPDF(context.Background(), 5558, "English"); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if _, ok := err.(*recurly.ServerError); !ok {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestInvoices_WritePDF(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("GET", "/v2/invoices/5558", func(w http.ResponseWriter, r *http.Request) {
+		if h := r.Header.Get("Accept-Language"); h != "English" {
+			t.Fatalf("unexpected 'Accept-Language' header: %q", h)
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("binary pdf text"))
+	}, t)
+
+	var buf bytes.Buffer
+	if n, err := client.Invoices.WritePDF(context.Background(), 5558, "Klingon", &buf); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if n != int64(len("binary pdf text")) {
+		t.Fatalf("unexpected bytes written: %d", n)
+	} else if buf.String() != "binary pdf text" {
+		t.Fatal(buf.String())
+	}
+}
+
 func TestInvoices_Preview(t *testing.T) {
 	client, s := recurly.NewTestServer()
 	defer s.Close()
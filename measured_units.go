@@ -0,0 +1,117 @@
+package recurly
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// MeasuredUnitsService manages the interactions for measured units.
+type MeasuredUnitsService interface {
+	// List returns a pager to paginate measured units. PagerOptions are used
+	// to optionally filter the results.
+	//
+	// https://dev.recurly.com/docs/list-measured-units
+	List(opts *PagerOptions) Pager
+
+	// Get retrieves a measured unit. If the measured unit does not exist,
+	// a nil measured unit and nil error are returned.
+	//
+	// https://dev.recurly.com/docs/lookup-a-measured-unit
+	Get(ctx context.Context, id int64) (*MeasuredUnit, error)
+
+	// Create creates a new measured unit.
+	//
+	// https://dev.recurly.com/docs/create-measured-unit
+	Create(ctx context.Context, m MeasuredUnit) (*MeasuredUnit, error)
+
+	// Update updates a measured unit.
+	//
+	// https://dev.recurly.com/docs/update-measured-unit
+	Update(ctx context.Context, id int64, m MeasuredUnit) (*MeasuredUnit, error)
+
+	// Delete removes a measured unit. Measured units in use by an add-on
+	// cannot be deleted.
+	//
+	// https://dev.recurly.com/docs/delete-measured-unit
+	Delete(ctx context.Context, id int64) error
+}
+
+// MeasuredUnit describes the unit of measure (e.g. API calls, gigabytes)
+// used by usage-based add-ons.
+//
+// https://dev.recurly.com/docs/measured-unit-object
+type MeasuredUnit struct {
+	XMLName     xml.Name `xml:"measured_unit"`
+	ID          int64    `xml:"id,omitempty"`
+	Name        string   `xml:"name,omitempty"`
+	DisplayName string   `xml:"display_name,omitempty"`
+	Description string   `xml:"description,omitempty"`
+	CreatedAt   NullTime `xml:"created_at,omitempty"`
+	UpdatedAt   NullTime `xml:"updated_at,omitempty"`
+}
+
+var _ MeasuredUnitsService = &measuredUnitsImpl{}
+
+// measuredUnitsImpl implements MeasuredUnitsService.
+type measuredUnitsImpl serviceImpl
+
+func (s *measuredUnitsImpl) List(opts *PagerOptions) Pager {
+	return s.client.newPager("GET", "/measured_units", opts)
+}
+
+func (s *measuredUnitsImpl) Get(ctx context.Context, id int64) (*MeasuredUnit, error) {
+	path := fmt.Sprintf("/measured_units/%d", id)
+	req, err := s.client.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst MeasuredUnit
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		if e, ok := err.(*ClientError); ok && e.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *measuredUnitsImpl) Create(ctx context.Context, m MeasuredUnit) (*MeasuredUnit, error) {
+	req, err := s.client.newRequest("POST", "/measured_units", m)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst MeasuredUnit
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *measuredUnitsImpl) Update(ctx context.Context, id int64, m MeasuredUnit) (*MeasuredUnit, error) {
+	path := fmt.Sprintf("/measured_units/%d", id)
+	req, err := s.client.newRequest("PUT", path, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst MeasuredUnit
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *measuredUnitsImpl) Delete(ctx context.Context, id int64) error {
+	path := fmt.Sprintf("/measured_units/%d", id)
+	req, err := s.client.newRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	return err
+}
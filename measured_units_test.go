@@ -0,0 +1,148 @@
+package recurly_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMeasuredUnits_List(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	var invocations int
+	s.HandleFunc("GET", "/v2/measured_units", func(w http.ResponseWriter, r *http.Request) {
+		invocations++
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("measured_units.xml"))
+	}, t)
+
+	pager := client.MeasuredUnits.List(nil)
+	for pager.Next() {
+		var units []recurly.MeasuredUnit
+		if err := pager.Fetch(context.Background(), &units); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected s to be invoked")
+		} else if diff := cmp.Diff(units, []recurly.MeasuredUnit{*NewTestMeasuredUnit()}); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+	if invocations != 1 {
+		t.Fatalf("unexpected number of invocations: %d", invocations)
+	}
+}
+
+func TestMeasuredUnits_Get(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/measured_units/3473591245469944008", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustOpenFile("measured_unit.xml"))
+		}, t)
+
+		if m, err := client.MeasuredUnits.Get(context.Background(), 3473591245469944008); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(m, NewTestMeasuredUnit()); diff != "" {
+			t.Fatal(diff)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		}
+	})
+
+	// Ensure a 404 returns nil values.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/measured_units/3473591245469944008", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, t)
+
+		if m, err := client.MeasuredUnits.Get(context.Background(), 3473591245469944008); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if err != nil {
+			t.Fatal(err)
+		} else if m != nil {
+			t.Fatalf("expected nil: %#v", m)
+		}
+	})
+}
+
+func TestMeasuredUnits_Create(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("POST", "/v2/measured_units", func(w http.ResponseWriter, r *http.Request) {
+		if str := MustReadAllString(r.Body); str != MustCompactString(`
+			<measured_unit>
+				<name>gigabytes</name>
+				<display_name>GB</display_name>
+			</measured_unit>
+		`) {
+			t.Fatal(str)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write(MustOpenFile("measured_unit.xml"))
+	}, t)
+
+	if m, err := client.MeasuredUnits.Create(context.Background(), recurly.MeasuredUnit{Name: "gigabytes", DisplayName: "GB"}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(m, NewTestMeasuredUnit()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestMeasuredUnits_Update(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("PUT", "/v2/measured_units/3473591245469944008", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("measured_unit.xml"))
+	}, t)
+
+	if m, err := client.MeasuredUnits.Update(context.Background(), 3473591245469944008, recurly.MeasuredUnit{}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(m, NewTestMeasuredUnit()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestMeasuredUnits_Delete(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("DELETE", "/v2/measured_units/3473591245469944008", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, t)
+
+	if err := client.MeasuredUnits.Delete(context.Background(), 3473591245469944008); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Returns a measured unit corresponding to testdata/measured_unit.xml.
+func NewTestMeasuredUnit() *recurly.MeasuredUnit {
+	return &recurly.MeasuredUnit{
+		XMLName:     xml.Name{Local: "measured_unit"},
+		ID:          3473591245469944008,
+		Name:        "gigabytes",
+		DisplayName: "GB",
+		Description: "Storage used in gigabytes",
+		CreatedAt:   recurly.NewTime(MustParseTime("2019-08-12T18:20:22Z")),
+		UpdatedAt:   recurly.NewTime(MustParseTime("2019-08-12T18:20:22Z")),
+	}
+}
@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/blacklightcms/recurly"
+)
+
+var _ recurly.AccountAcquisitionService = &AccountAcquisitionService{}
+
+// AccountAcquisitionService manages the interactions for account acquisition.
+type AccountAcquisitionService struct {
+	Recorder
+
+	OnList      func(opts *recurly.PagerOptions) recurly.Pager
+	ListInvoked bool
+
+	OnGet      func(ctx context.Context, accountCode string) (*recurly.AccountAcquisition, error)
+	GetInvoked bool
+
+	OnCreate      func(ctx context.Context, accountCode string, a recurly.AccountAcquisition) (*recurly.AccountAcquisition, error)
+	CreateInvoked bool
+
+	OnUpdate      func(ctx context.Context, accountCode string, a recurly.AccountAcquisition) (*recurly.AccountAcquisition, error)
+	UpdateInvoked bool
+
+	OnDelete      func(ctx context.Context, accountCode string) error
+	DeleteInvoked bool
+}
+
+func (m *AccountAcquisitionService) List(opts *recurly.PagerOptions) recurly.Pager {
+	if !m.record("AccountAcquisition", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
+	return m.OnList(opts)
+}
+
+func (m *AccountAcquisitionService) Get(ctx context.Context, accountCode string) (*recurly.AccountAcquisition, error) {
+	if !m.record("AccountAcquisition", "Get", &m.GetInvoked, m.OnGet != nil, accountCode) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnGet(ctx, accountCode)
+}
+
+func (m *AccountAcquisitionService) Create(ctx context.Context, accountCode string, a recurly.AccountAcquisition) (*recurly.AccountAcquisition, error) {
+	if !m.record("AccountAcquisition", "Create", &m.CreateInvoked, m.OnCreate != nil, accountCode, a) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnCreate(ctx, accountCode, a)
+}
+
+func (m *AccountAcquisitionService) Update(ctx context.Context, accountCode string, a recurly.AccountAcquisition) (*recurly.AccountAcquisition, error) {
+	if !m.record("AccountAcquisition", "Update", &m.UpdateInvoked, m.OnUpdate != nil, accountCode, a) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnUpdate(ctx, accountCode, a)
+}
+
+func (m *AccountAcquisitionService) Delete(ctx context.Context, accountCode string) error {
+	if !m.record("AccountAcquisition", "Delete", &m.DeleteInvoked, m.OnDelete != nil, accountCode) {
+		return ErrNotStubbed
+	}
+	return m.OnDelete(ctx, accountCode)
+}
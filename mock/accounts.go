@@ -10,6 +10,8 @@ var _ recurly.AccountsService = &AccountsService{}
 
 // AccountsService manages the interactions for accounts.
 type AccountsService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -33,44 +35,70 @@ type AccountsService struct {
 
 	OnListNotes      func(code string, opts *recurly.PagerOptions) recurly.Pager
 	ListNotesInvoked bool
+
+	OnListChildAccounts      func(parentCode string, opts *recurly.PagerOptions) recurly.Pager
+	ListChildAccountsInvoked bool
 }
 
 func (m *AccountsService) List(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("Accounts", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(opts)
 }
 
 func (m *AccountsService) Get(ctx context.Context, code string) (*recurly.Account, error) {
-	m.GetInvoked = true
+	if !m.record("Accounts", "Get", &m.GetInvoked, m.OnGet != nil, code) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, code)
 }
 
 func (m *AccountsService) Balance(ctx context.Context, code string) (*recurly.AccountBalance, error) {
-	m.BalanceInvoked = true
+	if !m.record("Accounts", "Balance", &m.BalanceInvoked, m.OnBalance != nil, code) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnBalance(ctx, code)
 }
 
 func (m *AccountsService) Create(ctx context.Context, a recurly.Account) (*recurly.Account, error) {
-	m.CreateInvoked = true
+	if !m.record("Accounts", "Create", &m.CreateInvoked, m.OnCreate != nil, a) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, a)
 }
 
 func (m *AccountsService) Update(ctx context.Context, code string, a recurly.Account) (*recurly.Account, error) {
-	m.UpdateInvoked = true
+	if !m.record("Accounts", "Update", &m.UpdateInvoked, m.OnUpdate != nil, code, a) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnUpdate(ctx, code, a)
 }
 
 func (m *AccountsService) Close(ctx context.Context, code string) error {
-	m.CloseInvoked = true
+	if !m.record("Accounts", "Close", &m.CloseInvoked, m.OnClose != nil, code) {
+		return ErrNotStubbed
+	}
 	return m.OnClose(ctx, code)
 }
 
 func (m *AccountsService) Reopen(ctx context.Context, code string) error {
-	m.ReopenInvoked = true
+	if !m.record("Accounts", "Reopen", &m.ReopenInvoked, m.OnReopen != nil, code) {
+		return ErrNotStubbed
+	}
 	return m.OnReopen(ctx, code)
 }
 
 func (m *AccountsService) ListNotes(code string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListNotesInvoked = true
+	if !m.record("Accounts", "ListNotes", &m.ListNotesInvoked, m.OnListNotes != nil, code, opts) {
+		return emptyPager{}
+	}
 	return m.OnListNotes(code, opts)
 }
+
+func (m *AccountsService) ListChildAccounts(parentCode string, opts *recurly.PagerOptions) recurly.Pager {
+	if !m.record("Accounts", "ListChildAccounts", &m.ListChildAccountsInvoked, m.OnListChildAccounts != nil, parentCode, opts) {
+		return emptyPager{}
+	}
+	return m.OnListChildAccounts(parentCode, opts)
+}
@@ -10,6 +10,8 @@ var _ recurly.AddOnsService = &AddOnsService{}
 
 // AddOnsService manages the interactions for add ons.
 type AddOnsService struct {
+	Recorder
+
 	OnList      func(planCode string, opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -27,26 +29,36 @@ type AddOnsService struct {
 }
 
 func (m *AddOnsService) List(planCode string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("AddOns", "List", &m.ListInvoked, m.OnList != nil, planCode, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(planCode, opts)
 }
 
 func (m *AddOnsService) Get(ctx context.Context, planCode string, code string) (*recurly.AddOn, error) {
-	m.GetInvoked = true
+	if !m.record("AddOns", "Get", &m.GetInvoked, m.OnGet != nil, planCode, code) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, planCode, code)
 }
 
 func (m *AddOnsService) Create(ctx context.Context, planCode string, a recurly.AddOn) (*recurly.AddOn, error) {
-	m.CreateInvoked = true
+	if !m.record("AddOns", "Create", &m.CreateInvoked, m.OnCreate != nil, planCode, a) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, planCode, a)
 }
 
 func (m *AddOnsService) Update(ctx context.Context, planCode string, code string, a recurly.AddOn) (*recurly.AddOn, error) {
-	m.UpdateInvoked = true
+	if !m.record("AddOns", "Update", &m.UpdateInvoked, m.OnUpdate != nil, planCode, code, a) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnUpdate(ctx, planCode, code, a)
 }
 
 func (m *AddOnsService) Delete(ctx context.Context, planCode string, code string) error {
-	m.DeleteInvoked = true
+	if !m.record("AddOns", "Delete", &m.DeleteInvoked, m.OnDelete != nil, planCode, code) {
+		return ErrNotStubbed
+	}
 	return m.OnDelete(ctx, planCode, code)
 }
@@ -10,6 +10,8 @@ var _ recurly.AdjustmentsService = &AdjustmentsService{}
 
 // AdjustmentsService manages the interactions for adjustments.
 type AdjustmentsService struct {
+	Recorder
+
 	OnListAccount      func(accountCode string, opts *recurly.PagerOptions) recurly.Pager
 	ListAccountInvoked bool
 
@@ -24,21 +26,29 @@ type AdjustmentsService struct {
 }
 
 func (m *AdjustmentsService) ListAccount(accountCode string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListAccountInvoked = true
+	if !m.record("Adjustments", "ListAccount", &m.ListAccountInvoked, m.OnListAccount != nil, accountCode, opts) {
+		return emptyPager{}
+	}
 	return m.OnListAccount(accountCode, opts)
 }
 
 func (m *AdjustmentsService) Get(ctx context.Context, uuid string) (*recurly.Adjustment, error) {
-	m.GetInvoked = true
+	if !m.record("Adjustments", "Get", &m.GetInvoked, m.OnGet != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, uuid)
 }
 
 func (m *AdjustmentsService) Create(ctx context.Context, accountCode string, a recurly.Adjustment) (*recurly.Adjustment, error) {
-	m.CreateInvoked = true
+	if !m.record("Adjustments", "Create", &m.CreateInvoked, m.OnCreate != nil, accountCode, a) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, accountCode, a)
 }
 
 func (m *AdjustmentsService) Delete(ctx context.Context, uuid string) error {
-	m.DeleteInvoked = true
+	if !m.record("Adjustments", "Delete", &m.DeleteInvoked, m.OnDelete != nil, uuid) {
+		return ErrNotStubbed
+	}
 	return m.OnDelete(ctx, uuid)
 }
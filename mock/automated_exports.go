@@ -11,6 +11,8 @@ var _ recurly.AutomatedExportsService = &AutomatedExportsService{}
 
 // AutomatedExportsService manages the interactions for automated exports.
 type AutomatedExportsService struct {
+	Recorder
+
 	OnGet      func(ctx context.Context, date time.Time, fileName string) (*recurly.AutomatedExport, error)
 	GetInvoked bool
 
@@ -22,16 +24,22 @@ type AutomatedExportsService struct {
 }
 
 func (m *AutomatedExportsService) Get(ctx context.Context, date time.Time, fileName string) (*recurly.AutomatedExport, error) {
-	m.GetInvoked = true
+	if !m.record("AutomatedExports", "Get", &m.GetInvoked, m.OnGet != nil, date, fileName) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, date, fileName)
 }
 
 func (m *AutomatedExportsService) ListDates(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListDatesInvoked = true
+	if !m.record("AutomatedExports", "ListDates", &m.ListDatesInvoked, m.OnListDates != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnListDates(opts)
 }
 
 func (m *AutomatedExportsService) ListFiles(date time.Time, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListFilesInvoked = true
+	if !m.record("AutomatedExports", "ListFiles", &m.ListFilesInvoked, m.OnListFiles != nil, date, opts) {
+		return emptyPager{}
+	}
 	return m.OnListFiles(date, opts)
 }
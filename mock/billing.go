@@ -10,6 +10,8 @@ var _ recurly.BillingService = &BillingService{}
 
 // BillingService manages the interactions for billing.
 type BillingService struct {
+	Recorder
+
 	OnGet      func(ctx context.Context, accountCode string) (*recurly.Billing, error)
 	GetInvoked bool
 
@@ -24,21 +26,29 @@ type BillingService struct {
 }
 
 func (m *BillingService) Get(ctx context.Context, accountCode string) (*recurly.Billing, error) {
-	m.GetInvoked = true
+	if !m.record("Billing", "Get", &m.GetInvoked, m.OnGet != nil, accountCode) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, accountCode)
 }
 
 func (m *BillingService) Create(ctx context.Context, accountCode string, b recurly.Billing) (*recurly.Billing, error) {
-	m.CreateInvoked = true
+	if !m.record("Billing", "Create", &m.CreateInvoked, m.OnCreate != nil, accountCode, b) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, accountCode, b)
 }
 
 func (m *BillingService) Update(ctx context.Context, accountCode string, b recurly.Billing) (*recurly.Billing, error) {
-	m.UpdateInvoked = true
+	if !m.record("Billing", "Update", &m.UpdateInvoked, m.OnUpdate != nil, accountCode, b) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnUpdate(ctx, accountCode, b)
 }
 
 func (m *BillingService) Clear(ctx context.Context, accountCode string) error {
-	m.ClearInvoked = true
+	if !m.record("Billing", "Clear", &m.ClearInvoked, m.OnClear != nil, accountCode) {
+		return ErrNotStubbed
+	}
 	return m.OnClear(ctx, accountCode)
 }
@@ -9,20 +9,27 @@ import (
 type Client struct {
 	*recurly.Client
 
-	Accounts          AccountsService
-	AddOns            AddOnsService
-	Adjustments       AdjustmentsService
-	Billing           BillingService
-	Coupons           CouponsService
-	CreditPayments    CreditPaymentsService
-	Redemptions       RedemptionsService
-	Invoices          InvoicesService
-	Plans             PlansService
-	Purchases         PurchasesService
-	ShippingAddresses ShippingAddressesService
-	ShippingMethods   ShippingMethodsService
-	Subscriptions     SubscriptionsService
-	Transactions      TransactionsService
+	Accounts           AccountsService
+	AccountAcquisition AccountAcquisitionService
+	AddOns             AddOnsService
+	Adjustments        AdjustmentsService
+	AutomatedExports   AutomatedExportsService
+	Billing            BillingService
+	Coupons            CouponsService
+	CreditPayments     CreditPaymentsService
+	GiftCards          GiftCardsService
+	Redemptions        RedemptionsService
+	Invoices           InvoicesService
+	Items              ItemsService
+	MeasuredUnits      MeasuredUnitsService
+	Plans              PlansService
+	Purchases          PurchasesService
+	ShippingAddresses  ShippingAddressesService
+	ShippingMethods    ShippingMethodsService
+	Subscriptions      SubscriptionsService
+	Transactions       TransactionsService
+	UniqueCouponCodes  UniqueCouponCodesService
+	Usage              UsageService
 }
 
 // NewClient returns a new instance of *Client with the
@@ -32,18 +39,60 @@ func NewClient(subdomain, apiKey string) *Client {
 
 	// Attach mock implementations.
 	c.Client.Accounts = &c.Accounts
+	c.Client.AccountAcquisition = &c.AccountAcquisition
 	c.Client.AddOns = &c.AddOns
 	c.Client.Adjustments = &c.Adjustments
+	c.Client.AutomatedExports = &c.AutomatedExports
 	c.Client.Billing = &c.Billing
 	c.Client.Coupons = &c.Coupons
 	c.Client.CreditPayments = &c.CreditPayments
+	c.Client.GiftCards = &c.GiftCards
 	c.Client.Redemptions = &c.Redemptions
 	c.Client.Invoices = &c.Invoices
+	c.Client.Items = &c.Items
+	c.Client.MeasuredUnits = &c.MeasuredUnits
 	c.Client.Plans = &c.Plans
 	c.Client.Purchases = &c.Purchases
 	c.Client.ShippingAddresses = &c.ShippingAddresses
 	c.Client.ShippingMethods = &c.ShippingMethods
 	c.Client.Subscriptions = &c.Subscriptions
 	c.Client.Transactions = &c.Transactions
+	c.Client.UniqueCouponCodes = &c.UniqueCouponCodes
+	c.Client.Usage = &c.Usage
 	return c
 }
+
+// FailUnstubbed reports calls to mock methods without an On func as errors
+// on t rather than panicking. See Recorder.T.
+func (c *Client) FailUnstubbed(t TB) {
+	for _, r := range c.recorders() {
+		r.T = t
+	}
+}
+
+// recorders returns the recorders of all of the services.
+func (c *Client) recorders() []*Recorder {
+	return []*Recorder{
+		&c.Accounts.Recorder,
+		&c.AccountAcquisition.Recorder,
+		&c.AddOns.Recorder,
+		&c.Adjustments.Recorder,
+		&c.AutomatedExports.Recorder,
+		&c.Billing.Recorder,
+		&c.Coupons.Recorder,
+		&c.CreditPayments.Recorder,
+		&c.GiftCards.Recorder,
+		&c.Redemptions.Recorder,
+		&c.Invoices.Recorder,
+		&c.Items.Recorder,
+		&c.MeasuredUnits.Recorder,
+		&c.Plans.Recorder,
+		&c.Purchases.Recorder,
+		&c.ShippingAddresses.Recorder,
+		&c.ShippingMethods.Recorder,
+		&c.Subscriptions.Recorder,
+		&c.Transactions.Recorder,
+		&c.UniqueCouponCodes.Recorder,
+		&c.Usage.Recorder,
+	}
+}
@@ -10,6 +10,8 @@ var _ recurly.CouponsService = &CouponsService{}
 
 // CouponsService manages the interactions for coupons.
 type CouponsService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -33,36 +35,50 @@ type CouponsService struct {
 }
 
 func (m *CouponsService) List(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("Coupons", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(opts)
 }
 
 func (m *CouponsService) Get(ctx context.Context, code string) (*recurly.Coupon, error) {
-	m.GetInvoked = true
+	if !m.record("Coupons", "Get", &m.GetInvoked, m.OnGet != nil, code) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, code)
 }
 
 func (m *CouponsService) Create(ctx context.Context, c recurly.Coupon) (*recurly.Coupon, error) {
-	m.CreateInvoked = true
+	if !m.record("Coupons", "Create", &m.CreateInvoked, m.OnCreate != nil, c) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, c)
 }
 
 func (m *CouponsService) Update(ctx context.Context, code string, c recurly.Coupon) (*recurly.Coupon, error) {
-	m.UpdateInvoked = true
+	if !m.record("Coupons", "Update", &m.UpdateInvoked, m.OnUpdate != nil, code, c) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnUpdate(ctx, code, c)
 }
 
 func (m *CouponsService) Restore(ctx context.Context, code string, c recurly.Coupon) (*recurly.Coupon, error) {
-	m.RestoreInvoked = true
+	if !m.record("Coupons", "Restore", &m.RestoreInvoked, m.OnRestore != nil, code, c) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnRestore(ctx, code, c)
 }
 
 func (m *CouponsService) Delete(ctx context.Context, code string) error {
-	m.DeleteInvoked = true
+	if !m.record("Coupons", "Delete", &m.DeleteInvoked, m.OnDelete != nil, code) {
+		return ErrNotStubbed
+	}
 	return m.OnDelete(ctx, code)
 }
 
 func (m *CouponsService) Generate(ctx context.Context, code string, n int) (recurly.Pager, error) {
-	m.GenerateInvoked = true
+	if !m.record("Coupons", "Generate", &m.GenerateInvoked, m.OnGenerate != nil, code, n) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGenerate(ctx, code, n)
 }
@@ -10,6 +10,8 @@ var _ recurly.CreditPaymentsService = &CreditPaymentsService{}
 
 // CreditPaymentsService manages the interactions for credit payments.
 type CreditPaymentsService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -21,16 +23,22 @@ type CreditPaymentsService struct {
 }
 
 func (m *CreditPaymentsService) List(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("CreditPayments", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(opts)
 }
 
 func (m *CreditPaymentsService) ListAccount(code string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListAccountInvoked = true
+	if !m.record("CreditPayments", "ListAccount", &m.ListAccountInvoked, m.OnListAccount != nil, code, opts) {
+		return emptyPager{}
+	}
 	return m.OnListAccount(code, opts)
 }
 
 func (m *CreditPaymentsService) Get(ctx context.Context, uuid string) (*recurly.CreditPayment, error) {
-	m.GetInvoked = true
+	if !m.record("CreditPayments", "Get", &m.GetInvoked, m.OnGet != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, uuid)
 }
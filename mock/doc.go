@@ -127,6 +127,34 @@ Then in your test suite you might configure your own wrapper similar to mock.Cli
 		}
 	}
 
+Pagers
+
+NewPager returns a pager that pages through a slice of records, so list
+methods can be stubbed without writing each pager method:
+
+	client.Subscriptions.OnList = func(opts *recurly.PagerOptions) recurly.Pager {
+		return mock.NewPager([]recurly.Subscription{{UUID: "1"}, {UUID: "2"}}, opts)
+	}
+
+Use FailAt to return an error when a given page is fetched.
+
+Recording Calls
+
+Every mock records the calls made to it along with their arguments, excluding
+the context. Use Calls, CallsTo and CallCount to verify them:
+
+	if calls := client.Billing.CallsTo("Get"); len(calls) != 1 {
+		t.Fatalf("unexpected calls: %v", calls)
+	} else if calls[0].Args[0] != "10" {
+		t.Fatalf("unexpected account code: %v", calls[0].Args[0])
+	}
+
+Calling a method without its On func panics. Use FailUnstubbed to report
+these calls as test errors instead:
+
+	client := mock.NewClient("subdomain", "key")
+	client.FailUnstubbed(t)
+
 See examples for more.
 
 */
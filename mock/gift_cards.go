@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/blacklightcms/recurly"
+)
+
+var _ recurly.GiftCardsService = &GiftCardsService{}
+
+// GiftCardsService manages the interactions for gift cards.
+type GiftCardsService struct {
+	Recorder
+
+	OnList      func(opts *recurly.PagerOptions) recurly.Pager
+	ListInvoked bool
+
+	OnGet      func(ctx context.Context, id int64) (*recurly.GiftCard, error)
+	GetInvoked bool
+
+	OnPreview      func(ctx context.Context, g recurly.GiftCard) (*recurly.GiftCard, error)
+	PreviewInvoked bool
+
+	OnCreate      func(ctx context.Context, g recurly.GiftCard) (*recurly.GiftCard, error)
+	CreateInvoked bool
+
+	OnRedeem      func(ctx context.Context, redemptionCode string, accountCode string) (*recurly.GiftCard, error)
+	RedeemInvoked bool
+}
+
+func (m *GiftCardsService) List(opts *recurly.PagerOptions) recurly.Pager {
+	if !m.record("GiftCards", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
+	return m.OnList(opts)
+}
+
+func (m *GiftCardsService) Get(ctx context.Context, id int64) (*recurly.GiftCard, error) {
+	if !m.record("GiftCards", "Get", &m.GetInvoked, m.OnGet != nil, id) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnGet(ctx, id)
+}
+
+func (m *GiftCardsService) Preview(ctx context.Context, g recurly.GiftCard) (*recurly.GiftCard, error) {
+	if !m.record("GiftCards", "Preview", &m.PreviewInvoked, m.OnPreview != nil, g) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnPreview(ctx, g)
+}
+
+func (m *GiftCardsService) Create(ctx context.Context, g recurly.GiftCard) (*recurly.GiftCard, error) {
+	if !m.record("GiftCards", "Create", &m.CreateInvoked, m.OnCreate != nil, g) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnCreate(ctx, g)
+}
+
+func (m *GiftCardsService) Redeem(ctx context.Context, redemptionCode string, accountCode string) (*recurly.GiftCard, error) {
+	if !m.record("GiftCards", "Redeem", &m.RedeemInvoked, m.OnRedeem != nil, redemptionCode, accountCode) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnRedeem(ctx, redemptionCode, accountCode)
+}
@@ -3,6 +3,7 @@ package mock
 import (
 	"bytes"
 	"context"
+	"io"
 
 	"github.com/blacklightcms/recurly"
 )
@@ -11,6 +12,8 @@ var _ recurly.InvoicesService = &InvoicesService{}
 
 // InvoicesService manages the interactions for invoices.
 type InvoicesService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -23,6 +26,12 @@ type InvoicesService struct {
 	OnGetPDF      func(ctx context.Context, invoiceNumber int, language string) (*bytes.Buffer, error)
 	GetPDFInvoked bool
 
+	OnOpenPDF      func(ctx context.Context, invoiceNumber int, language string) (io.ReadCloser, error)
+	OpenPDFInvoked bool
+
+	OnWritePDF      func(ctx context.Context, invoiceNumber int, language string, w io.Writer) (int64, error)
+	WritePDFInvoked bool
+
 	OnPreview      func(ctx context.Context, accountCode string) (*recurly.Invoice, error)
 	PreviewInvoked bool
 
@@ -52,66 +61,106 @@ type InvoicesService struct {
 }
 
 func (m *InvoicesService) List(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("Invoices", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(opts)
 }
 
 func (m *InvoicesService) ListAccount(accountCode string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListAccountInvoked = true
+	if !m.record("Invoices", "ListAccount", &m.ListAccountInvoked, m.OnListAccount != nil, accountCode, opts) {
+		return emptyPager{}
+	}
 	return m.OnListAccount(accountCode, opts)
 }
 
 func (m *InvoicesService) Get(ctx context.Context, invoiceNumber int) (*recurly.Invoice, error) {
-	m.GetInvoked = true
+	if !m.record("Invoices", "Get", &m.GetInvoked, m.OnGet != nil, invoiceNumber) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, invoiceNumber)
 }
 
 func (m *InvoicesService) GetPDF(ctx context.Context, invoiceNumber int, language string) (*bytes.Buffer, error) {
-	m.GetPDFInvoked = true
+	if !m.record("Invoices", "GetPDF", &m.GetPDFInvoked, m.OnGetPDF != nil, invoiceNumber, language) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGetPDF(ctx, invoiceNumber, language)
 }
 
+func (m *InvoicesService) OpenPDF(ctx context.Context, invoiceNumber int, language string) (io.ReadCloser, error) {
+	if !m.record("Invoices", "OpenPDF", &m.OpenPDFInvoked, m.OnOpenPDF != nil, invoiceNumber, language) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnOpenPDF(ctx, invoiceNumber, language)
+}
+
+func (m *InvoicesService) WritePDF(ctx context.Context, invoiceNumber int, language string, w io.Writer) (int64, error) {
+	if !m.record("Invoices", "WritePDF", &m.WritePDFInvoked, m.OnWritePDF != nil, invoiceNumber, language, w) {
+		return 0, ErrNotStubbed
+	}
+	return m.OnWritePDF(ctx, invoiceNumber, language, w)
+}
+
 func (m *InvoicesService) Preview(ctx context.Context, accountCode string) (*recurly.Invoice, error) {
-	m.PreviewInvoked = true
+	if !m.record("Invoices", "Preview", &m.PreviewInvoked, m.OnPreview != nil, accountCode) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnPreview(ctx, accountCode)
 }
 
 func (m *InvoicesService) Create(ctx context.Context, accountCode string, invoice recurly.Invoice) (*recurly.Invoice, error) {
-	m.CreateInvoked = true
+	if !m.record("Invoices", "Create", &m.CreateInvoked, m.OnCreate != nil, accountCode, invoice) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, accountCode, invoice)
 }
 
 func (m *InvoicesService) Collect(ctx context.Context, invoiceNumber int, collectInvoice recurly.CollectInvoice) (*recurly.Invoice, error) {
-	m.CollectInvoked = true
+	if !m.record("Invoices", "Collect", &m.CollectInvoked, m.OnCollect != nil, invoiceNumber, collectInvoice) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCollect(ctx, invoiceNumber, collectInvoice)
 }
 
 func (m *InvoicesService) MarkPaid(ctx context.Context, invoiceNumber int) (*recurly.Invoice, error) {
-	m.MarkPaidInvoked = true
+	if !m.record("Invoices", "MarkPaid", &m.MarkPaidInvoked, m.OnMarkPaid != nil, invoiceNumber) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnMarkPaid(ctx, invoiceNumber)
 }
 
 func (m *InvoicesService) MarkFailed(ctx context.Context, invoiceNumber int) (*recurly.Invoice, error) {
-	m.MarkFailedInvoked = true
+	if !m.record("Invoices", "MarkFailed", &m.MarkFailedInvoked, m.OnMarkFailed != nil, invoiceNumber) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnMarkFailed(ctx, invoiceNumber)
 }
 
 func (m *InvoicesService) RefundVoidLineItems(ctx context.Context, invoiceNumber int, refund recurly.InvoiceLineItemsRefund) (*recurly.Invoice, error) {
-	m.RefundVoidLineItemsInvoked = true
+	if !m.record("Invoices", "RefundVoidLineItems", &m.RefundVoidLineItemsInvoked, m.OnRefundVoidLineItems != nil, invoiceNumber, refund) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnRefundVoidLineItems(ctx, invoiceNumber, refund)
 }
 
 func (m *InvoicesService) RefundVoidOpenAmount(ctx context.Context, invoiceNumber int, refund recurly.InvoiceRefund) (*recurly.Invoice, error) {
-	m.RefundVoidOpenAmountInvoked = true
+	if !m.record("Invoices", "RefundVoidOpenAmount", &m.RefundVoidOpenAmountInvoked, m.OnRefundVoidOpenAmount != nil, invoiceNumber, refund) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnRefundVoidOpenAmount(ctx, invoiceNumber, refund)
 }
 
 func (m *InvoicesService) VoidCreditInvoice(ctx context.Context, invoiceNumber int) (*recurly.Invoice, error) {
-	m.VoidCreditInvoiceInvoked = true
+	if !m.record("Invoices", "VoidCreditInvoice", &m.VoidCreditInvoiceInvoked, m.OnVoidCreditInvoice != nil, invoiceNumber) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnVoidCreditInvoice(ctx, invoiceNumber)
 }
 
 func (m *InvoicesService) RecordPayment(ctx context.Context, pmt recurly.OfflinePayment) (*recurly.Transaction, error) {
-	m.RecordPaymentInvoked = true
+	if !m.record("Invoices", "RecordPayment", &m.RecordPaymentInvoked, m.OnRecordPayment != nil, pmt) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnRecordPayment(ctx, pmt)
 }
@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/blacklightcms/recurly"
+)
+
+var _ recurly.ItemsService = &ItemsService{}
+
+// ItemsService manages the interactions for items.
+type ItemsService struct {
+	Recorder
+
+	OnList      func(opts *recurly.PagerOptions) recurly.Pager
+	ListInvoked bool
+
+	OnGet      func(ctx context.Context, itemCode string) (*recurly.Item, error)
+	GetInvoked bool
+
+	OnCreate      func(ctx context.Context, item recurly.Item) (*recurly.Item, error)
+	CreateInvoked bool
+
+	OnUpdate      func(ctx context.Context, itemCode string, item recurly.Item) (*recurly.Item, error)
+	UpdateInvoked bool
+
+	OnDeactivate      func(ctx context.Context, itemCode string) error
+	DeactivateInvoked bool
+}
+
+func (m *ItemsService) List(opts *recurly.PagerOptions) recurly.Pager {
+	if !m.record("Items", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
+	return m.OnList(opts)
+}
+
+func (m *ItemsService) Get(ctx context.Context, itemCode string) (*recurly.Item, error) {
+	if !m.record("Items", "Get", &m.GetInvoked, m.OnGet != nil, itemCode) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnGet(ctx, itemCode)
+}
+
+func (m *ItemsService) Create(ctx context.Context, item recurly.Item) (*recurly.Item, error) {
+	if !m.record("Items", "Create", &m.CreateInvoked, m.OnCreate != nil, item) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnCreate(ctx, item)
+}
+
+func (m *ItemsService) Update(ctx context.Context, itemCode string, item recurly.Item) (*recurly.Item, error) {
+	if !m.record("Items", "Update", &m.UpdateInvoked, m.OnUpdate != nil, itemCode, item) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnUpdate(ctx, itemCode, item)
+}
+
+func (m *ItemsService) Deactivate(ctx context.Context, itemCode string) error {
+	if !m.record("Items", "Deactivate", &m.DeactivateInvoked, m.OnDeactivate != nil, itemCode) {
+		return ErrNotStubbed
+	}
+	return m.OnDeactivate(ctx, itemCode)
+}
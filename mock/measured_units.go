@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/blacklightcms/recurly"
+)
+
+var _ recurly.MeasuredUnitsService = &MeasuredUnitsService{}
+
+// MeasuredUnitsService manages the interactions for measured units.
+type MeasuredUnitsService struct {
+	Recorder
+
+	OnList      func(opts *recurly.PagerOptions) recurly.Pager
+	ListInvoked bool
+
+	OnGet      func(ctx context.Context, id int64) (*recurly.MeasuredUnit, error)
+	GetInvoked bool
+
+	OnCreate      func(ctx context.Context, m recurly.MeasuredUnit) (*recurly.MeasuredUnit, error)
+	CreateInvoked bool
+
+	OnUpdate      func(ctx context.Context, id int64, m recurly.MeasuredUnit) (*recurly.MeasuredUnit, error)
+	UpdateInvoked bool
+
+	OnDelete      func(ctx context.Context, id int64) error
+	DeleteInvoked bool
+}
+
+func (m *MeasuredUnitsService) List(opts *recurly.PagerOptions) recurly.Pager {
+	if !m.record("MeasuredUnits", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
+	return m.OnList(opts)
+}
+
+func (m *MeasuredUnitsService) Get(ctx context.Context, id int64) (*recurly.MeasuredUnit, error) {
+	if !m.record("MeasuredUnits", "Get", &m.GetInvoked, m.OnGet != nil, id) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnGet(ctx, id)
+}
+
+func (m *MeasuredUnitsService) Create(ctx context.Context, mu recurly.MeasuredUnit) (*recurly.MeasuredUnit, error) {
+	if !m.record("MeasuredUnits", "Create", &m.CreateInvoked, m.OnCreate != nil, mu) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnCreate(ctx, mu)
+}
+
+func (m *MeasuredUnitsService) Update(ctx context.Context, id int64, mu recurly.MeasuredUnit) (*recurly.MeasuredUnit, error) {
+	if !m.record("MeasuredUnits", "Update", &m.UpdateInvoked, m.OnUpdate != nil, id, mu) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnUpdate(ctx, id, mu)
+}
+
+func (m *MeasuredUnitsService) Delete(ctx context.Context, id int64) error {
+	if !m.record("MeasuredUnits", "Delete", &m.DeleteInvoked, m.OnDelete != nil, id) {
+		return ErrNotStubbed
+	}
+	return m.OnDelete(ctx, id)
+}
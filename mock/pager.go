@@ -2,13 +2,21 @@ package mock
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
 
 	"github.com/blacklightcms/recurly"
 )
 
 var _ recurly.Pager = &Pager{}
 
+// Pager is a mock recurly.Pager. Use NewPager to page through a slice of
+// records.
 type Pager struct {
+	Recorder
+
 	OnCount      func(ctx context.Context) (int, error)
 	CountInvoked bool
 
@@ -26,26 +34,158 @@ type Pager struct {
 }
 
 func (m *Pager) Count(ctx context.Context) (int, error) {
-	m.CountInvoked = true
+	if !m.record("Pager", "Count", &m.CountInvoked, m.OnCount != nil) {
+		return 0, ErrNotStubbed
+	}
 	return m.OnCount(ctx)
 }
 
 func (m *Pager) Next() bool {
-	m.NextInvoked = true
+	if !m.record("Pager", "Next", &m.NextInvoked, m.OnNext != nil) {
+		return false
+	}
 	return m.OnNext()
 }
 
 func (m *Pager) Cursor() string {
-	m.CursorInvoked = true
+	if !m.record("Pager", "Cursor", &m.CursorInvoked, m.OnCursor != nil) {
+		return ""
+	}
 	return m.OnCursor()
 }
 
 func (m *Pager) Fetch(ctx context.Context, dst interface{}) error {
-	m.FetchInvoked = true
+	if !m.record("Pager", "Fetch", &m.FetchInvoked, m.OnFetch != nil, dst) {
+		return ErrNotStubbed
+	}
 	return m.OnFetch(ctx, dst)
 }
 
 func (m *Pager) FetchAll(ctx context.Context, dst interface{}) error {
-	m.FetchAllInvoked = true
+	if !m.record("Pager", "FetchAll", &m.FetchAllInvoked, m.OnFetchAll != nil, dst) {
+		return ErrNotStubbed
+	}
 	return m.OnFetchAll(ctx, dst)
 }
+
+// DefaultPerPage is the page size of pagers returned by NewPager when no
+// PerPage option is given, matching Recurly's default.
+const DefaultPerPage = 50
+
+// NewPager returns a pager that pages through records, which must be a slice
+// such as []recurly.Subscription. Fetch and FetchAll populate a pointer to a
+// slice of the same type.
+//
+// opts may be nil. PerPage sets the page size and defaults to
+// DefaultPerPage. Like recurly.Pager, FetchAll fetches pages of 200 records.
+// Cursor holds the offset of the next page, and a Cursor option resumes
+// from that offset. Count returns the total number of records.
+//
+// The On funcs of the returned pager are set, and may be replaced to
+// override individual methods. See FailAt to inject errors.
+func NewPager(records interface{}, opts *recurly.PagerOptions) *Pager {
+	v := reflect.ValueOf(records)
+	if v.Kind() != reflect.Slice {
+		panic(fmt.Sprintf("mock: NewPager called with %T, not a slice", records))
+	}
+
+	perPage := DefaultPerPage
+	var cursor string
+	if opts != nil {
+		if opts.PerPage > 0 {
+			perPage = opts.PerPage
+		}
+		cursor = opts.Cursor
+	}
+	offset, _ := strconv.Atoi(cursor)
+	expectResults := true
+
+	m := &Pager{}
+	m.OnCount = func(ctx context.Context) (int, error) {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		return v.Len(), nil
+	}
+	m.OnNext = func() bool { return expectResults }
+	m.OnCursor = func() string { return cursor }
+	m.OnFetch = func(ctx context.Context, dst interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		} else if !expectResults {
+			return errors.New("no more results")
+		}
+
+		out, err := sliceOf(v, dst)
+		if err != nil {
+			return err
+		}
+
+		start, end := offset, offset+perPage
+		if start > v.Len() {
+			start = v.Len()
+		}
+		if end > v.Len() {
+			end = v.Len()
+		}
+		page := reflect.MakeSlice(v.Type(), end-start, end-start)
+		reflect.Copy(page, v.Slice(start, end))
+		out.Set(page)
+
+		if offset = end; offset < v.Len() {
+			cursor = strconv.Itoa(offset)
+		} else {
+			cursor, expectResults = "", false
+		}
+		return nil
+	}
+	m.OnFetchAll = func(ctx context.Context, dst interface{}) error {
+		out, err := sliceOf(v, dst)
+		if err != nil {
+			return err
+		}
+
+		// Call the On funcs directly so only FetchAll is recorded. They are
+		// read here rather than captured so FailAt applies.
+		perPage = 200
+		all := reflect.Zero(v.Type())
+		for m.OnNext() {
+			page := reflect.New(v.Type())
+			if err := m.OnFetch(ctx, page.Interface()); err != nil {
+				return err
+			}
+			all = reflect.AppendSlice(all, page.Elem())
+		}
+		out.Set(all)
+		return nil
+	}
+	return m
+}
+
+// sliceOf returns the slice dst points to, or an error if it cannot hold the
+// records in v.
+func sliceOf(v reflect.Value, dst interface{}) (reflect.Value, error) {
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Ptr || d.IsNil() || d.Elem().Type() != v.Type() {
+		return reflect.Value{}, fmt.Errorf("unknown type used for pagination: %T", dst)
+	}
+	return d.Elem(), nil
+}
+
+// FailAt makes fetching page n, counting from 1, return err. Like
+// recurly.Pager, Next returns false after the error. m must have OnNext and
+// OnFetch set, as pagers returned by NewPager do. It returns m.
+func (m *Pager) FailAt(n int, err error) *Pager {
+	next, fetch := m.OnNext, m.OnFetch
+	var page int
+	var failed bool
+	m.OnNext = func() bool { return !failed && next() }
+	m.OnFetch = func(ctx context.Context, dst interface{}) error {
+		if page++; page == n {
+			failed = true
+			return err
+		}
+		return fetch(ctx, dst)
+	}
+	return m
+}
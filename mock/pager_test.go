@@ -0,0 +1,122 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/mock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNewPager(t *testing.T) {
+	ctx := context.Background()
+	subs := make([]recurly.Subscription, 5)
+	for i := range subs {
+		subs[i].UUID = strconv.Itoa(i)
+	}
+
+	t.Run("Fetch", func(t *testing.T) {
+		pager := mock.NewPager(subs, &recurly.PagerOptions{PerPage: 2})
+		if n, err := pager.Count(ctx); err != nil {
+			t.Fatal(err)
+		} else if n != 5 {
+			t.Fatalf("unexpected count: %d", n)
+		}
+
+		var pages [][]recurly.Subscription
+		var cursors []string
+		for pager.Next() {
+			var page []recurly.Subscription
+			if err := pager.Fetch(ctx, &page); err != nil {
+				t.Fatal(err)
+			}
+			pages = append(pages, page)
+			cursors = append(cursors, pager.Cursor())
+		}
+		if diff := cmp.Diff(pages, [][]recurly.Subscription{subs[0:2], subs[2:4], subs[4:5]}); diff != "" {
+			t.Fatal(diff)
+		} else if diff := cmp.Diff(cursors, []string{"2", "4", ""}); diff != "" {
+			t.Fatal(diff)
+		} else if n := pager.CallCount("Fetch"); n != 3 {
+			t.Fatalf("unexpected fetches: %d", n)
+		}
+
+		if err := pager.Fetch(ctx, &[]recurly.Subscription{}); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("Cursor", func(t *testing.T) {
+		var page []recurly.Subscription
+		if err := mock.NewPager(subs, &recurly.PagerOptions{PerPage: 2, Cursor: "3"}).Fetch(ctx, &page); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(page, subs[3:5]); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("FetchAll", func(t *testing.T) {
+		var all []recurly.Subscription
+		pager := mock.NewPager(subs, &recurly.PagerOptions{PerPage: 2})
+		if err := pager.FetchAll(ctx, &all); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(all, subs); diff != "" {
+			t.Fatal(diff)
+		} else if diff := cmp.Diff(pager.Calls(), []mock.Call{{Method: "FetchAll", Args: []interface{}{&all}}}); diff != "" {
+			t.Fatal(diff)
+		}
+
+		var none []recurly.Account
+		if err := mock.NewPager([]recurly.Account{}, nil).FetchAll(ctx, &none); err != nil {
+			t.Fatal(err)
+		} else if none != nil {
+			t.Fatalf("unexpected accounts: %#v", none)
+		}
+	})
+
+	t.Run("ErrType", func(t *testing.T) {
+		var accounts []recurly.Account
+		if err := mock.NewPager(subs, nil).Fetch(ctx, &accounts); err == nil {
+			t.Fatal("expected error")
+		} else if err := mock.NewPager(subs, nil).FetchAll(ctx, accounts); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("ErrContext", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		var page []recurly.Subscription
+		pager := mock.NewPager(subs, nil)
+		if err := pager.Fetch(ctx, &page); err != context.Canceled {
+			t.Fatalf("unexpected error: %v", err)
+		} else if _, err := pager.Count(ctx); err != context.Canceled {
+			t.Fatalf("unexpected error: %v", err)
+		} else if !pager.Next() {
+			t.Fatal("expected results")
+		}
+	})
+
+	t.Run("FailAt", func(t *testing.T) {
+		errFailed := errors.New("failed")
+		pager := mock.NewPager(subs, &recurly.PagerOptions{PerPage: 2}).FailAt(2, errFailed)
+
+		var page []recurly.Subscription
+		if err := pager.Fetch(ctx, &page); err != nil {
+			t.Fatal(err)
+		} else if err := pager.Fetch(ctx, &page); err != errFailed {
+			t.Fatalf("unexpected error: %v", err)
+		} else if pager.Next() {
+			t.Fatal("expected no more results")
+		}
+
+		var all []recurly.Subscription
+		if err := mock.NewPager(subs, nil).FailAt(1, errFailed).FetchAll(ctx, &all); err != errFailed {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
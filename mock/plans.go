@@ -10,6 +10,8 @@ var _ recurly.PlansService = &PlansService{}
 
 // PlansService manages the interactions for plans.
 type PlansService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -27,26 +29,36 @@ type PlansService struct {
 }
 
 func (m *PlansService) List(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("Plans", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(opts)
 }
 
 func (m *PlansService) Get(ctx context.Context, code string) (*recurly.Plan, error) {
-	m.GetInvoked = true
+	if !m.record("Plans", "Get", &m.GetInvoked, m.OnGet != nil, code) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, code)
 }
 
 func (m *PlansService) Create(ctx context.Context, p recurly.Plan) (*recurly.Plan, error) {
-	m.CreateInvoked = true
+	if !m.record("Plans", "Create", &m.CreateInvoked, m.OnCreate != nil, p) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, p)
 }
 
 func (m *PlansService) Update(ctx context.Context, code string, p recurly.Plan) (*recurly.Plan, error) {
-	m.UpdateInvoked = true
+	if !m.record("Plans", "Update", &m.UpdateInvoked, m.OnUpdate != nil, code, p) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnUpdate(ctx, code, p)
 }
 
 func (m *PlansService) Delete(ctx context.Context, code string) error {
-	m.DeleteInvoked = true
+	if !m.record("Plans", "Delete", &m.DeleteInvoked, m.OnDelete != nil, code) {
+		return ErrNotStubbed
+	}
 	return m.OnDelete(ctx, code)
 }
@@ -9,6 +9,8 @@ import (
 var _ recurly.PurchasesService = &PurchasesService{}
 
 type PurchasesService struct {
+	Recorder
+
 	OnCreate      func(ctx context.Context, p recurly.Purchase) (*recurly.InvoiceCollection, error)
 	CreateInvoked bool
 
@@ -29,31 +31,43 @@ type PurchasesService struct {
 }
 
 func (m *PurchasesService) Create(ctx context.Context, p recurly.Purchase) (*recurly.InvoiceCollection, error) {
-	m.CreateInvoked = true
+	if !m.record("Purchases", "Create", &m.CreateInvoked, m.OnCreate != nil, p) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, p)
 }
 
 func (m *PurchasesService) Preview(ctx context.Context, p recurly.Purchase) (*recurly.InvoiceCollection, error) {
-	m.PreviewInvoked = true
+	if !m.record("Purchases", "Preview", &m.PreviewInvoked, m.OnPreview != nil, p) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnPreview(ctx, p)
 }
 
 func (m *PurchasesService) Authorize(ctx context.Context, p recurly.Purchase) (*recurly.Purchase, error) {
-	m.AuthorizeInvoked = true
+	if !m.record("Purchases", "Authorize", &m.AuthorizeInvoked, m.OnAuthorize != nil, p) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnAuthorize(ctx, p)
 }
 
 func (m *PurchasesService) Pending(ctx context.Context, p recurly.Purchase) (*recurly.Purchase, error) {
-	m.PendingInvoked = true
+	if !m.record("Purchases", "Pending", &m.PendingInvoked, m.OnPending != nil, p) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnPending(ctx, p)
 }
 
 func (m *PurchasesService) Capture(ctx context.Context, transactionUUID string) (*recurly.InvoiceCollection, error) {
-	m.CaptureInvoked = true
+	if !m.record("Purchases", "Capture", &m.CaptureInvoked, m.OnCapture != nil, transactionUUID) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCapture(ctx, transactionUUID)
 }
 
 func (m *PurchasesService) Cancel(ctx context.Context, transactionUUID string) (*recurly.InvoiceCollection, error) {
-	m.CancelInvoked = true
+	if !m.record("Purchases", "Cancel", &m.CancelInvoked, m.OnCancel != nil, transactionUUID) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCancel(ctx, transactionUUID)
 }
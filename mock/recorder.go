@@ -0,0 +1,107 @@
+package mock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// ErrNotStubbed is returned by mocks when a method is called without its On
+// func set and the test is reported with Recorder.T.
+var ErrNotStubbed = errors.New("mock: method is not stubbed")
+
+// TB is the subset of testing.TB used to report calls to methods that are
+// not stubbed.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// Call is a call made to a mock.
+type Call struct {
+	// Method is the name of the method called, such as "Get".
+	Method string
+
+	// Args holds the arguments of the call, excluding the context.
+	Args []interface{}
+}
+
+// Recorder records the calls made to a mock. It is embedded in every mock.
+type Recorder struct {
+	// T, if set, reports calls to methods without an On func as test
+	// errors. The method then returns zero values and ErrNotStubbed, or an
+	// empty pager. If T is nil such calls panic.
+	T TB
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls returns the calls made to the mock, in order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo returns the calls made to method, in order.
+func (r *Recorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, c := range r.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// CallCount returns the number of calls made to method.
+func (r *Recorder) CallCount(method string) int {
+	return len(r.CallsTo(method))
+}
+
+// ResetCalls clears the recorded calls.
+func (r *Recorder) ResetCalls() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = nil
+}
+
+// record records a call to service.method and sets its invoked flag. It
+// returns false if the method is not stubbed, after reporting the call to T.
+func (r *Recorder) record(service, method string, invoked *bool, stubbed bool, args ...interface{}) bool {
+	r.mu.Lock()
+	*invoked = true
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+	r.mu.Unlock()
+
+	if stubbed {
+		return true
+	}
+
+	msg := fmt.Sprintf("mock: %s.%s called without On%s", service, method, method)
+	if r.T == nil {
+		panic(msg)
+	}
+	r.T.Helper()
+	r.T.Errorf("%s", msg)
+	return false
+}
+
+// emptyPager is returned by mocks for pagers that are not stubbed. It has no
+// results and fetching returns ErrNotStubbed.
+type emptyPager struct{}
+
+var _ recurly.Pager = emptyPager{}
+
+func (emptyPager) Count(ctx context.Context) (int, error)              { return 0, ErrNotStubbed }
+func (emptyPager) Next() bool                                          { return false }
+func (emptyPager) Cursor() string                                      { return "" }
+func (emptyPager) Fetch(ctx context.Context, dst interface{}) error    { return ErrNotStubbed }
+func (emptyPager) FetchAll(ctx context.Context, dst interface{}) error { return ErrNotStubbed }
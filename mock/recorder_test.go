@@ -0,0 +1,128 @@
+package mock_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/mock"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestClient_Services(t *testing.T) {
+	client := mock.NewClient("subdomain", "key")
+	if client.Client.Items != &client.Items {
+		t.Fatal("expected Items to be mocked")
+	} else if client.Client.AutomatedExports != &client.AutomatedExports {
+		t.Fatal("expected AutomatedExports to be mocked")
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	client := mock.NewClient("subdomain", "key")
+	client.Items.OnGet = func(ctx context.Context, itemCode string) (*recurly.Item, error) {
+		return &recurly.Item{Code: itemCode}, nil
+	}
+	client.Items.OnUpdate = func(ctx context.Context, itemCode string, item recurly.Item) (*recurly.Item, error) {
+		return &item, nil
+	}
+
+	for _, code := range []string{"a", "b"} {
+		if _, err := client.Client.Items.Get(ctx, code); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := client.Client.Items.Update(ctx, "a", recurly.Item{Name: "A"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(client.Items.Calls(), []mock.Call{
+		{Method: "Get", Args: []interface{}{"a"}},
+		{Method: "Get", Args: []interface{}{"b"}},
+		{Method: "Update", Args: []interface{}{"a", recurly.Item{Name: "A"}}},
+	}); diff != "" {
+		t.Fatal(diff)
+	} else if n := client.Items.CallCount("Get"); n != 2 {
+		t.Fatalf("unexpected count: %d", n)
+	} else if calls := client.Items.CallsTo("Update"); len(calls) != 1 {
+		t.Fatalf("unexpected calls: %v", calls)
+	} else if !client.Items.GetInvoked {
+		t.Fatal("expected Get() to be invoked")
+	}
+
+	client.Items.ResetCalls()
+	if calls := client.Items.Calls(); len(calls) != 0 {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+// Ensure mocks can be called concurrently.
+func TestRecorder_Concurrent(t *testing.T) {
+	client := mock.NewClient("subdomain", "key")
+	client.Items.OnGet = func(ctx context.Context, itemCode string) (*recurly.Item, error) {
+		return &recurly.Item{Code: itemCode}, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.Client.Items.Get(context.Background(), "a")
+		}()
+	}
+	wg.Wait()
+
+	if n := client.Items.CallCount("Get"); n != 10 {
+		t.Fatalf("unexpected count: %d", n)
+	} else if !client.Items.GetInvoked {
+		t.Fatal("expected Get() to be invoked")
+	}
+}
+
+func TestRecorder_Unstubbed(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Panic", func(t *testing.T) {
+		defer func() {
+			if r := recover(); r != "mock: Items.Get called without OnGet" {
+				t.Fatalf("unexpected panic: %v", r)
+			}
+		}()
+		mock.NewClient("subdomain", "key").Client.Items.Get(ctx, "a")
+	})
+
+	t.Run("FailUnstubbed", func(t *testing.T) {
+		var tb testTB
+		client := mock.NewClient("subdomain", "key")
+		client.FailUnstubbed(&tb)
+
+		if _, err := client.Client.AutomatedExports.Get(ctx, time.Now(), "file"); err != mock.ErrNotStubbed {
+			t.Fatalf("unexpected error: %v", err)
+		} else if err := client.Client.Accounts.List(nil).FetchAll(ctx, nil); err != mock.ErrNotStubbed {
+			t.Fatalf("unexpected error: %v", err)
+		} else if diff := cmp.Diff(tb.errors, []string{
+			"mock: AutomatedExports.Get called without OnGet",
+			"mock: Accounts.List called without OnList",
+		}); diff != "" {
+			t.Fatal(diff)
+		} else if n := client.AutomatedExports.CallCount("Get"); n != 1 {
+			t.Fatalf("unexpected count: %d", n)
+		}
+	})
+}
+
+// testTB records the errors reported by mocks.
+type testTB struct {
+	errors []string
+}
+
+func (tb *testTB) Helper() {}
+
+func (tb *testTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
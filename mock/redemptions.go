@@ -10,6 +10,8 @@ var _ recurly.RedemptionsService = &RedemptionsService{}
 
 // RedemptionsService manages the interactions for redemptions.
 type RedemptionsService struct {
+	Recorder
+
 	OnListAccount      func(accountCode string, opts *recurly.PagerOptions) recurly.Pager
 	ListAccountInvoked bool
 
@@ -27,26 +29,36 @@ type RedemptionsService struct {
 }
 
 func (m *RedemptionsService) ListAccount(accountCode string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListAccountInvoked = true
+	if !m.record("Redemptions", "ListAccount", &m.ListAccountInvoked, m.OnListAccount != nil, accountCode, opts) {
+		return emptyPager{}
+	}
 	return m.OnListAccount(accountCode, opts)
 }
 
 func (m *RedemptionsService) ListInvoice(invoiceNumber int, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoiceInvoked = true
+	if !m.record("Redemptions", "ListInvoice", &m.ListInvoiceInvoked, m.OnListInvoice != nil, invoiceNumber, opts) {
+		return emptyPager{}
+	}
 	return m.OnListInvoice(invoiceNumber, opts)
 }
 
 func (m *RedemptionsService) ListSubscription(uuid string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListSubscriptionInvoked = true
+	if !m.record("Redemptions", "ListSubscription", &m.ListSubscriptionInvoked, m.OnListSubscription != nil, uuid, opts) {
+		return emptyPager{}
+	}
 	return m.OnListSubscription(uuid, opts)
 }
 
 func (m *RedemptionsService) Redeem(ctx context.Context, code string, r recurly.CouponRedemption) (*recurly.Redemption, error) {
-	m.RedeemInvoked = true
+	if !m.record("Redemptions", "Redeem", &m.RedeemInvoked, m.OnRedeem != nil, code, r) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnRedeem(ctx, code, r)
 }
 
 func (m *RedemptionsService) Delete(ctx context.Context, accountCode, redemptionUUID string) error {
-	m.DeleteInvoked = true
+	if !m.record("Redemptions", "Delete", &m.DeleteInvoked, m.OnDelete != nil, accountCode, redemptionUUID) {
+		return ErrNotStubbed
+	}
 	return m.OnDelete(ctx, accountCode, redemptionUUID)
 }
@@ -9,6 +9,8 @@ import (
 var _ recurly.ShippingAddressesService = &ShippingAddressesService{}
 
 type ShippingAddressesService struct {
+	Recorder
+
 	OnListAccount      func(accountCode string, opts *recurly.PagerOptions) recurly.Pager
 	ListAccountInvoked bool
 
@@ -23,21 +25,29 @@ type ShippingAddressesService struct {
 }
 
 func (s *ShippingAddressesService) ListAccount(accountCode string, opts *recurly.PagerOptions) recurly.Pager {
-	s.ListAccountInvoked = true
+	if !s.record("ShippingAddresses", "ListAccount", &s.ListAccountInvoked, s.OnListAccount != nil, accountCode, opts) {
+		return emptyPager{}
+	}
 	return s.OnListAccount(accountCode, opts)
 }
 
 func (s *ShippingAddressesService) Create(ctx context.Context, accountCode string, address recurly.ShippingAddress) (*recurly.ShippingAddress, error) {
-	s.CreateInvoked = true
+	if !s.record("ShippingAddresses", "Create", &s.CreateInvoked, s.OnCreate != nil, accountCode, address) {
+		return nil, ErrNotStubbed
+	}
 	return s.OnCreate(ctx, accountCode, address)
 }
 
 func (s *ShippingAddressesService) Update(ctx context.Context, accountCode string, shippingAddressID int, address recurly.ShippingAddress) (*recurly.ShippingAddress, error) {
-	s.UpdateInvoked = true
+	if !s.record("ShippingAddresses", "Update", &s.UpdateInvoked, s.OnUpdate != nil, accountCode, shippingAddressID, address) {
+		return nil, ErrNotStubbed
+	}
 	return s.OnUpdate(ctx, accountCode, shippingAddressID, address)
 }
 
 func (s *ShippingAddressesService) Delete(ctx context.Context, accountCode string, shippingAddressID int) error {
-	s.DeleteInvoked = true
+	if !s.record("ShippingAddresses", "Delete", &s.DeleteInvoked, s.OnDelete != nil, accountCode, shippingAddressID) {
+		return ErrNotStubbed
+	}
 	return s.OnDelete(ctx, accountCode, shippingAddressID)
 }
@@ -9,6 +9,8 @@ import (
 var _ recurly.ShippingMethodsService = &ShippingMethodsService{}
 
 type ShippingMethodsService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -17,11 +19,15 @@ type ShippingMethodsService struct {
 }
 
 func (s *ShippingMethodsService) List(opts *recurly.PagerOptions) recurly.Pager {
-	s.ListInvoked = true
+	if !s.record("ShippingMethods", "List", &s.ListInvoked, s.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return s.OnList(opts)
 }
 
 func (s *ShippingMethodsService) Get(ctx context.Context, code string) (*recurly.ShippingMethod, error) {
-	s.GetInvoked = true
+	if !s.record("ShippingMethods", "Get", &s.GetInvoked, s.OnGet != nil, code) {
+		return nil, ErrNotStubbed
+	}
 	return s.OnGet(ctx, code)
 }
@@ -11,6 +11,8 @@ var _ recurly.SubscriptionsService = &SubscriptionsService{}
 
 // SubscriptionsService mocks the subscription service.
 type SubscriptionsService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -58,76 +60,106 @@ type SubscriptionsService struct {
 }
 
 func (m *SubscriptionsService) List(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("Subscriptions", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(opts)
 }
 
 func (m *SubscriptionsService) ListAccount(accountCode string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListAccountInvoked = true
+	if !m.record("Subscriptions", "ListAccount", &m.ListAccountInvoked, m.OnListAccount != nil, accountCode, opts) {
+		return emptyPager{}
+	}
 	return m.OnListAccount(accountCode, opts)
 }
 
 func (m *SubscriptionsService) Get(ctx context.Context, uuid string) (*recurly.Subscription, error) {
-	m.GetInvoked = true
+	if !m.record("Subscriptions", "Get", &m.GetInvoked, m.OnGet != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, uuid)
 }
 
 func (m *SubscriptionsService) Create(ctx context.Context, sub recurly.NewSubscription) (*recurly.Subscription, error) {
-	m.CreateInvoked = true
+	if !m.record("Subscriptions", "Create", &m.CreateInvoked, m.OnCreate != nil, sub) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCreate(ctx, sub)
 }
 
 func (m *SubscriptionsService) Preview(ctx context.Context, sub recurly.NewSubscription) (*recurly.Subscription, error) {
-	m.PreviewInvoked = true
+	if !m.record("Subscriptions", "Preview", &m.PreviewInvoked, m.OnPreview != nil, sub) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnPreview(ctx, sub)
 }
 
 func (m *SubscriptionsService) Update(ctx context.Context, uuid string, sub recurly.UpdateSubscription) (*recurly.Subscription, error) {
-	m.UpdateInvoked = true
+	if !m.record("Subscriptions", "Update", &m.UpdateInvoked, m.OnUpdate != nil, uuid, sub) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnUpdate(ctx, uuid, sub)
 }
 
 func (m *SubscriptionsService) UpdateNotes(ctx context.Context, uuid string, n recurly.SubscriptionNotes) (*recurly.Subscription, error) {
-	m.UpdateNotesInvoked = true
+	if !m.record("Subscriptions", "UpdateNotes", &m.UpdateNotesInvoked, m.OnUpdateNotes != nil, uuid, n) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnUpdateNotes(ctx, uuid, n)
 }
 
 func (m *SubscriptionsService) PreviewChange(ctx context.Context, uuid string, sub recurly.UpdateSubscription) (*recurly.Subscription, error) {
-	m.PreviewChangeInvoked = true
+	if !m.record("Subscriptions", "PreviewChange", &m.PreviewChangeInvoked, m.OnPreviewChange != nil, uuid, sub) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnPreviewChange(ctx, uuid, sub)
 }
 
 func (m *SubscriptionsService) Cancel(ctx context.Context, uuid string) (*recurly.Subscription, error) {
-	m.CancelInvoked = true
+	if !m.record("Subscriptions", "Cancel", &m.CancelInvoked, m.OnCancel != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnCancel(ctx, uuid)
 }
 
 func (m *SubscriptionsService) Reactivate(ctx context.Context, uuid string) (*recurly.Subscription, error) {
-	m.ReactivateInvoked = true
+	if !m.record("Subscriptions", "Reactivate", &m.ReactivateInvoked, m.OnReactivate != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnReactivate(ctx, uuid)
 }
 
 func (m *SubscriptionsService) Terminate(ctx context.Context, uuid string, refundType string) (*recurly.Subscription, error) {
-	m.TerminateInvoked = true
+	if !m.record("Subscriptions", "Terminate", &m.TerminateInvoked, m.OnTerminate != nil, uuid, refundType) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnTerminate(ctx, uuid, refundType)
 }
 
 func (m *SubscriptionsService) Postpone(ctx context.Context, uuid string, dt time.Time, bulk bool) (*recurly.Subscription, error) {
-	m.PostponeInvoked = true
+	if !m.record("Subscriptions", "Postpone", &m.PostponeInvoked, m.OnPostpone != nil, uuid, dt, bulk) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnPostpone(ctx, uuid, dt, bulk)
 }
 
 func (m *SubscriptionsService) Pause(ctx context.Context, uuid string, cycles int) (*recurly.Subscription, error) {
-	m.PauseInvoked = true
+	if !m.record("Subscriptions", "Pause", &m.PauseInvoked, m.OnPause != nil, uuid, cycles) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnPause(ctx, uuid, cycles)
 }
 
 func (m *SubscriptionsService) Resume(ctx context.Context, uuid string) (*recurly.Subscription, error) {
-	m.ResumeInvoked = true
+	if !m.record("Subscriptions", "Resume", &m.ResumeInvoked, m.OnResume != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnResume(ctx, uuid)
 }
 
 func (m *SubscriptionsService) ConvertTrial(ctx context.Context, uuid string) (*recurly.Subscription, error) {
-	m.ConvertTrialInvoked = true
+	if !m.record("Subscriptions", "ConvertTrial", &m.ConvertTrialInvoked, m.OnConvertTrial != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnConvertTrial(ctx, uuid)
 }
\ No newline at end of file
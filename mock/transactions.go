@@ -10,6 +10,8 @@ var _ recurly.TransactionsService = &TransactionsService{}
 
 // TransactionsService mocks the transaction service.
 type TransactionsService struct {
+	Recorder
+
 	OnList      func(opts *recurly.PagerOptions) recurly.Pager
 	ListInvoked bool
 
@@ -21,16 +23,22 @@ type TransactionsService struct {
 }
 
 func (m *TransactionsService) List(opts *recurly.PagerOptions) recurly.Pager {
-	m.ListInvoked = true
+	if !m.record("Transactions", "List", &m.ListInvoked, m.OnList != nil, opts) {
+		return emptyPager{}
+	}
 	return m.OnList(opts)
 }
 
 func (m *TransactionsService) ListAccount(accountCode string, opts *recurly.PagerOptions) recurly.Pager {
-	m.ListAccountInvoked = true
+	if !m.record("Transactions", "ListAccount", &m.ListAccountInvoked, m.OnListAccount != nil, accountCode, opts) {
+		return emptyPager{}
+	}
 	return m.OnListAccount(accountCode, opts)
 }
 
 func (m *TransactionsService) Get(ctx context.Context, uuid string) (*recurly.Transaction, error) {
-	m.GetInvoked = true
+	if !m.record("Transactions", "Get", &m.GetInvoked, m.OnGet != nil, uuid) {
+		return nil, ErrNotStubbed
+	}
 	return m.OnGet(ctx, uuid)
 }
@@ -0,0 +1,54 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/blacklightcms/recurly"
+)
+
+var _ recurly.UniqueCouponCodesService = &UniqueCouponCodesService{}
+
+// UniqueCouponCodesService manages the interactions for unique coupon codes.
+type UniqueCouponCodesService struct {
+	Recorder
+
+	OnList      func(couponCode string, opts *recurly.PagerOptions) recurly.Pager
+	ListInvoked bool
+
+	OnGet      func(ctx context.Context, code string) (*recurly.UniqueCouponCode, error)
+	GetInvoked bool
+
+	OnDeactivate      func(ctx context.Context, code string) error
+	DeactivateInvoked bool
+
+	OnRestore      func(ctx context.Context, code string) (*recurly.UniqueCouponCode, error)
+	RestoreInvoked bool
+}
+
+func (m *UniqueCouponCodesService) List(couponCode string, opts *recurly.PagerOptions) recurly.Pager {
+	if !m.record("UniqueCouponCodes", "List", &m.ListInvoked, m.OnList != nil, couponCode, opts) {
+		return emptyPager{}
+	}
+	return m.OnList(couponCode, opts)
+}
+
+func (m *UniqueCouponCodesService) Get(ctx context.Context, code string) (*recurly.UniqueCouponCode, error) {
+	if !m.record("UniqueCouponCodes", "Get", &m.GetInvoked, m.OnGet != nil, code) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnGet(ctx, code)
+}
+
+func (m *UniqueCouponCodesService) Deactivate(ctx context.Context, code string) error {
+	if !m.record("UniqueCouponCodes", "Deactivate", &m.DeactivateInvoked, m.OnDeactivate != nil, code) {
+		return ErrNotStubbed
+	}
+	return m.OnDeactivate(ctx, code)
+}
+
+func (m *UniqueCouponCodesService) Restore(ctx context.Context, code string) (*recurly.UniqueCouponCode, error) {
+	if !m.record("UniqueCouponCodes", "Restore", &m.RestoreInvoked, m.OnRestore != nil, code) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnRestore(ctx, code)
+}
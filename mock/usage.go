@@ -0,0 +1,64 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/blacklightcms/recurly"
+)
+
+var _ recurly.UsageService = &UsageService{}
+
+// UsageService manages the interactions for usage records.
+type UsageService struct {
+	Recorder
+
+	OnList      func(subUUID string, addOnCode string, opts *recurly.PagerOptions) recurly.Pager
+	ListInvoked bool
+
+	OnGet      func(ctx context.Context, subUUID string, addOnCode string, usageID int64) (*recurly.Usage, error)
+	GetInvoked bool
+
+	OnCreate      func(ctx context.Context, subUUID string, addOnCode string, u recurly.Usage) (*recurly.Usage, error)
+	CreateInvoked bool
+
+	OnUpdate      func(ctx context.Context, subUUID string, addOnCode string, usageID int64, u recurly.Usage) (*recurly.Usage, error)
+	UpdateInvoked bool
+
+	OnDelete      func(ctx context.Context, subUUID string, addOnCode string, usageID int64) error
+	DeleteInvoked bool
+}
+
+func (m *UsageService) List(subUUID string, addOnCode string, opts *recurly.PagerOptions) recurly.Pager {
+	if !m.record("Usage", "List", &m.ListInvoked, m.OnList != nil, subUUID, addOnCode, opts) {
+		return emptyPager{}
+	}
+	return m.OnList(subUUID, addOnCode, opts)
+}
+
+func (m *UsageService) Get(ctx context.Context, subUUID string, addOnCode string, usageID int64) (*recurly.Usage, error) {
+	if !m.record("Usage", "Get", &m.GetInvoked, m.OnGet != nil, subUUID, addOnCode, usageID) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnGet(ctx, subUUID, addOnCode, usageID)
+}
+
+func (m *UsageService) Create(ctx context.Context, subUUID string, addOnCode string, u recurly.Usage) (*recurly.Usage, error) {
+	if !m.record("Usage", "Create", &m.CreateInvoked, m.OnCreate != nil, subUUID, addOnCode, u) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnCreate(ctx, subUUID, addOnCode, u)
+}
+
+func (m *UsageService) Update(ctx context.Context, subUUID string, addOnCode string, usageID int64, u recurly.Usage) (*recurly.Usage, error) {
+	if !m.record("Usage", "Update", &m.UpdateInvoked, m.OnUpdate != nil, subUUID, addOnCode, usageID, u) {
+		return nil, ErrNotStubbed
+	}
+	return m.OnUpdate(ctx, subUUID, addOnCode, usageID, u)
+}
+
+func (m *UsageService) Delete(ctx context.Context, subUUID string, addOnCode string, usageID int64) error {
+	if !m.record("Usage", "Delete", &m.DeleteInvoked, m.OnDelete != nil, subUUID, addOnCode, usageID) {
+		return ErrNotStubbed
+	}
+	return m.OnDelete(ctx, subUUID, addOnCode, usageID)
+}
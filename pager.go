@@ -112,23 +112,28 @@ func (p *pager) Fetch(ctx context.Context, dst interface{}) error {
 	}
 
 	var unmarshaler struct {
-		XMLName         xml.Name
-		Account         []Account         `xml:"account"`
-		Adjustment      []Adjustment      `xml:"adjustment"`
-		AddOn           []AddOn           `xml:"add_on"`
-		Coupon          []Coupon          `xml:"coupon"`
-		CreditPayment   []CreditPayment   `xml:"credit_payment"`
-		ExportDate      []ExportDate      `xml:"export_date"`
-		ExportFile      []ExportFile      `xml:"export_file"`
-		Invoice         []Invoice         `xml:"invoice"`
-		Note            []Note            `xml:"note"`
-		Plan            []Plan            `xml:"plan"`
-		Redemption      []Redemption      `xml:"redemption"`
-		ShippingAddress []ShippingAddress `xml:"shipping_address"`
-		ShippingMethod  []ShippingMethod  `xml:"shipping_method"`
-		Subscription    []Subscription    `xml:"subscription"`
-		Transaction     []Transaction     `xml:"transaction"`
-		Item            []Item            `xml:"item"`
+		XMLName            xml.Name
+		Account            []Account            `xml:"account"`
+		AccountAcquisition []AccountAcquisition `xml:"account_acquisition"`
+		Adjustment         []Adjustment         `xml:"adjustment"`
+		AddOn              []AddOn              `xml:"add_on"`
+		Coupon             []Coupon             `xml:"coupon"`
+		CreditPayment      []CreditPayment      `xml:"credit_payment"`
+		ExportDate         []ExportDate         `xml:"export_date"`
+		ExportFile         []ExportFile         `xml:"export_file"`
+		GiftCard           []GiftCard           `xml:"gift_card"`
+		Invoice            []Invoice            `xml:"invoice"`
+		Note               []Note               `xml:"note"`
+		Plan               []Plan               `xml:"plan"`
+		Redemption         []Redemption         `xml:"redemption"`
+		ShippingAddress    []ShippingAddress    `xml:"shipping_address"`
+		ShippingMethod     []ShippingMethod     `xml:"shipping_method"`
+		Subscription       []Subscription       `xml:"subscription"`
+		Transaction        []Transaction        `xml:"transaction"`
+		UniqueCouponCode   []UniqueCouponCode   `xml:"unique_coupon_code"`
+		Item               []Item               `xml:"item"`
+		MeasuredUnit       []MeasuredUnit       `xml:"measured_unit"`
+		Usage              []Usage              `xml:"usage"`
 	}
 
 	resp, err := p.client.do(ctx, req, &unmarshaler)
@@ -143,6 +148,8 @@ func (p *pager) Fetch(ctx context.Context, dst interface{}) error {
 	switch v := dst.(type) {
 	case *[]Account:
 		*v = unmarshaler.Account
+	case *[]AccountAcquisition:
+		*v = unmarshaler.AccountAcquisition
 	case *[]Adjustment:
 		*v = unmarshaler.Adjustment
 	case *[]AddOn:
@@ -155,6 +162,8 @@ func (p *pager) Fetch(ctx context.Context, dst interface{}) error {
 		*v = unmarshaler.ExportDate
 	case *[]ExportFile:
 		*v = unmarshaler.ExportFile
+	case *[]GiftCard:
+		*v = unmarshaler.GiftCard
 	case *[]Invoice:
 		*v = unmarshaler.Invoice
 	case *[]Note:
@@ -171,8 +180,14 @@ func (p *pager) Fetch(ctx context.Context, dst interface{}) error {
 		*v = unmarshaler.Subscription
 	case *[]Transaction:
 		*v = unmarshaler.Transaction
+	case *[]UniqueCouponCode:
+		*v = unmarshaler.UniqueCouponCode
 	case *[]Item:
 		*v = unmarshaler.Item
+	case *[]MeasuredUnit:
+		*v = unmarshaler.MeasuredUnit
+	case *[]Usage:
+		*v = unmarshaler.Usage
 	default:
 		return fmt.Errorf("unknown type used for pagination: %T", dst)
 	}
@@ -196,6 +211,16 @@ func (p *pager) FetchAll(ctx context.Context, dst interface{}) error {
 			all = append(all, dst...)
 		}
 		*v = all
+	case *[]AccountAcquisition:
+		var all []AccountAcquisition
+		for p.Next() {
+			var dst []AccountAcquisition
+			if err := p.Fetch(ctx, &dst); err != nil {
+				return err
+			}
+			all = append(all, dst...)
+		}
+		*v = all
 	case *[]Adjustment:
 		var all []Adjustment
 		for p.Next() {
@@ -236,6 +261,16 @@ func (p *pager) FetchAll(ctx context.Context, dst interface{}) error {
 			all = append(all, dst...)
 		}
 		*v = all
+	case *[]GiftCard:
+		var all []GiftCard
+		for p.Next() {
+			var dst []GiftCard
+			if err := p.Fetch(ctx, &dst); err != nil {
+				return err
+			}
+			all = append(all, dst...)
+		}
+		*v = all
 	case *[]Invoice:
 		var all []Invoice
 		for p.Next() {
@@ -246,6 +281,16 @@ func (p *pager) FetchAll(ctx context.Context, dst interface{}) error {
 			all = append(all, dst...)
 		}
 		*v = all
+	case *[]MeasuredUnit:
+		var all []MeasuredUnit
+		for p.Next() {
+			var dst []MeasuredUnit
+			if err := p.Fetch(ctx, &dst); err != nil {
+				return err
+			}
+			all = append(all, dst...)
+		}
+		*v = all
 	case *[]Note:
 		var all []Note
 		for p.Next() {
@@ -316,6 +361,26 @@ func (p *pager) FetchAll(ctx context.Context, dst interface{}) error {
 			all = append(all, dst...)
 		}
 		*v = all
+	case *[]UniqueCouponCode:
+		var all []UniqueCouponCode
+		for p.Next() {
+			var dst []UniqueCouponCode
+			if err := p.Fetch(ctx, &dst); err != nil {
+				return err
+			}
+			all = append(all, dst...)
+		}
+		*v = all
+	case *[]Usage:
+		var all []Usage
+		for p.Next() {
+			var dst []Usage
+			if err := p.Fetch(ctx, &dst); err != nil {
+				return err
+			}
+			all = append(all, dst...)
+		}
+		*v = all
 	default:
 		return fmt.Errorf("unknown type used for pagination: %T", dst)
 	}
@@ -345,6 +410,9 @@ type PagerOptions struct {
 	State string // supported by some endpoints. Check Recurly's documenation.
 	Type  string // supported by some endpoints. Check Recurly's documentation.
 
+	// BillingStatus filters usage records by "unbilled", "billed", or "all".
+	BillingStatus string
+
 	// query is for any one-off URL params used by a specific endpoint.
 	// Values sent as time.Time or recurly.NullTime will be automatically
 	// converted to a valid datetime format for Recurly.
@@ -406,6 +474,7 @@ func (p PagerOptions) append(u *url.URL) {
 	p.query["order"] = p.Order
 	p.query["state"] = p.State
 	p.query["type"] = p.Type
+	p.query["billing_status"] = p.BillingStatus
 	p.query["cursor"] = p.Cursor
 	p.query.append(u)
 }
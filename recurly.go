@@ -17,8 +17,6 @@ import (
 )
 
 // apiVersion is the API version in use by this client.
-// NOTE: v2.19:
-//		- Parent/child accounts not yet implemented.
 const apiVersion = "2.27"
 
 // uaVersion is the userAgent version sent to Recurly so they can track usage
@@ -51,22 +49,27 @@ type Client struct {
 	Client HTTPDoer
 
 	// Services used for talking with different parts of the Recurly API
-	Accounts          AccountsService
-	Adjustments       AdjustmentsService
-	AddOns            AddOnsService
-	AutomatedExports  AutomatedExportsService
-	Billing           BillingService
-	Coupons           CouponsService
-	CreditPayments    CreditPaymentsService
-	Invoices          InvoicesService
-	Plans             PlansService
-	Purchases         PurchasesService
-	Redemptions       RedemptionsService
-	ShippingAddresses ShippingAddressesService
-	ShippingMethods   ShippingMethodsService
-	Subscriptions     SubscriptionsService
-	Transactions      TransactionsService
-	Items             ItemsService
+	Accounts           AccountsService
+	AccountAcquisition AccountAcquisitionService
+	Adjustments        AdjustmentsService
+	AddOns             AddOnsService
+	AutomatedExports   AutomatedExportsService
+	Billing            BillingService
+	Coupons            CouponsService
+	CreditPayments     CreditPaymentsService
+	GiftCards          GiftCardsService
+	Invoices           InvoicesService
+	MeasuredUnits      MeasuredUnitsService
+	Plans              PlansService
+	Purchases          PurchasesService
+	Redemptions        RedemptionsService
+	ShippingAddresses  ShippingAddressesService
+	ShippingMethods    ShippingMethodsService
+	Subscriptions      SubscriptionsService
+	Transactions       TransactionsService
+	UniqueCouponCodes  UniqueCouponCodesService
+	Usage              UsageService
+	Items              ItemsService
 }
 
 type serviceImpl struct {
@@ -95,13 +98,16 @@ func NewClient(subdomain, apiKey string) *Client {
 	}
 
 	client.Accounts = &accountsImpl{client: client}
+	client.AccountAcquisition = &accountAcquisitionImpl{client: client}
 	client.Adjustments = &adjustmentsImpl{client: client}
 	client.AddOns = &addOnsImpl{client: client}
 	client.AutomatedExports = &automatedExportsImpl{client: client}
 	client.Billing = &billingImpl{client: client}
 	client.Coupons = &couponsImpl{client: client}
 	client.CreditPayments = &creditInvoicesImpl{client: client}
+	client.GiftCards = &giftCardsImpl{client: client}
 	client.Invoices = &invoicesImpl{client: client}
+	client.MeasuredUnits = &measuredUnitsImpl{client: client}
 	client.Plans = &plansImpl{client: client}
 	client.Purchases = &purchasesImpl{client: client}
 	client.Redemptions = &redemptionsImpl{client: client}
@@ -109,6 +115,8 @@ func NewClient(subdomain, apiKey string) *Client {
 	client.ShippingMethods = &shippingMethodsImpl{client: client}
 	client.Subscriptions = &subscriptionsImpl{client: client}
 	client.Transactions = &transactionsImpl{client: client}
+	client.UniqueCouponCodes = &uniqueCouponCodesImpl{client: client}
+	client.Usage = &usageImpl{client: client}
 	client.Items = &itemsImpl{client: client}
 	return client
 }
@@ -191,11 +199,6 @@ func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*res
 	response := newResponse(resp)
 	if resp.StatusCode == http.StatusNoContent {
 		return response, nil
-	} else if resp.StatusCode == http.StatusTooManyRequests {
-		return nil, &RateLimitError{
-			Response: resp,
-			Rate:     response.rate,
-		}
 	} else if v != nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 {
 		if w, ok := v.(io.Writer); ok {
 			io.Copy(w, resp.Body)
@@ -203,15 +206,44 @@ func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*res
 			return response, err
 		}
 		return response, nil
-	} else if resp.StatusCode >= 400 && resp.StatusCode <= 499 {
-		return response, response.parseClientError(v)
-	} else if resp.StatusCode >= 500 && resp.StatusCode <= 599 {
-		return nil, &ServerError{Response: resp}
+	} else if err := response.error(v); err != nil {
+		return response, err
 	}
 
 	return response, nil
 }
 
+// stream sends an API request and returns the raw response without reading
+// the body, allowing large non-XML payloads (e.g. PDFs) to be streamed. On
+// success the caller is responsible for closing the response body. Errors
+// are handled the same way as do.
+func (c *Client) stream(ctx context.Context, req *http.Request) (*http.Response, error) {
+	req = req.WithContext(ctx)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		select {
+		default:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if err := newResponse(resp).error(nil); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("unexpected response: %s %s: %d",
+		resp.Request.Method,
+		resp.Request.URL.Path,
+		resp.StatusCode)
+}
+
 // response is a Recurly API response. This wraps the standard http.Response
 // returned from Recurly and provides access to pagination cursors and rate
 // limits.
@@ -283,6 +315,22 @@ func (r *response) populateRateLimit() {
 	}
 }
 
+// error returns the error for a 429, 4xx or 5xx response, or nil for any
+// other status. It is shared by do and stream.
+func (r *response) error(v interface{}) error {
+	if r.StatusCode == http.StatusTooManyRequests {
+		return &RateLimitError{
+			Response: r.Response,
+			Rate:     r.rate,
+		}
+	} else if r.StatusCode >= 400 && r.StatusCode <= 499 {
+		return r.parseClientError(v)
+	} else if r.StatusCode >= 500 && r.StatusCode <= 599 {
+		return &ServerError{Response: r.Response}
+	}
+	return nil
+}
+
 // parses client errors.
 func (r *response) parseClientError(v interface{}) error {
 	// Immediately return a client error if there is no response body.
@@ -460,6 +508,24 @@ func (e *ServerError) Error() string {
 		e.Response.StatusCode)
 }
 
+// ContentTypeError occurs when Recurly returns a successful response with
+// an unexpected Content-Type, such as an HTML or XML body when a PDF was
+// requested.
+type ContentTypeError struct {
+	Response *http.Response
+
+	// Expected is the media type that was requested.
+	Expected string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("unexpected content type: %s %s: %q (expected %q)",
+		e.Response.Request.Method,
+		e.Response.Request.URL.Path,
+		e.Response.Header.Get("Content-Type"),
+		e.Expected)
+}
+
 // ValidationError is an individual validation error.
 type ValidationError struct {
 	Description string
@@ -0,0 +1,379 @@
+package recurlytest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// Card numbers with special behavior in the fake gateway.
+const (
+	// CardDeclined is declined when billing info is created or updated.
+	CardDeclined = 4000000000000002
+
+	// CardDeclinedOnCharge is accepted as billing info but declined when
+	// charged.
+	CardDeclinedOnCharge = 4000000000000341
+)
+
+// account holds an account and its billing info. Billing info is stored with
+// the card number so the gateway can decide whether charges succeed.
+type account struct {
+	recurly.Account
+	billing *recurly.Billing
+}
+
+func (s *Server) findAccount(code string) *account {
+	for _, a := range s.accounts {
+		if a.Code == code {
+			return a
+		}
+	}
+	return nil
+}
+
+// lookupAccount returns the account in the account_code parameter, or
+// writes a 404 and returns nil.
+func (s *Server) lookupAccount(c *call) *account {
+	code := c.param("account_code")
+	a := s.findAccount(code)
+	if a == nil {
+		c.notFound("Account", "account_code", code)
+	}
+	return a
+}
+
+// renderAccount returns a with the subscription and invoice flags
+// calculated from the state of the server.
+func (s *Server) renderAccount(a *account) *recurly.Account {
+	v := a.Account
+	v.XMLName = xml.Name{Local: "account"}
+
+	var live, active, future, canceled, paused, pastDue bool
+	for _, sub := range s.subscriptions {
+		if sub.AccountCode != a.Code {
+			continue
+		}
+		switch sub.State {
+		case recurly.SubscriptionStateActive, recurly.SubscriptionStateInTrial:
+			live, active = true, true
+		case recurly.SubscriptionStateFuture:
+			live, future = true, true
+		case recurly.SubscriptionStateCanceled:
+			live, canceled = true, true
+		case recurly.SubscriptionStatePaused:
+			live, paused = true, true
+		}
+	}
+	for _, inv := range s.invoices {
+		if inv.AccountCode == a.Code && inv.State == recurly.ChargeInvoiceStatePastDue {
+			pastDue = true
+		}
+	}
+
+	v.HasLiveSubscription = recurly.NewBool(live)
+	v.HasActiveSubscription = recurly.NewBool(active)
+	v.HasFutureSubscription = recurly.NewBool(future)
+	v.HasCanceledSubscription = recurly.NewBool(canceled)
+	v.HasPausedSubscription = recurly.NewBool(paused)
+	v.HasPastDueInvoice = recurly.NewBool(pastDue)
+	if a.billing != nil {
+		v.BillingInfo = billing(a.billing)
+	}
+	return &v
+}
+
+func (s *Server) listAccounts(c *call) {
+	c.writeList("accounts", filter(len(s.accounts), func(i int) (interface{}, bool) {
+		return s.renderAccount(s.accounts[i]), c.stateMatches(s.accounts[i].State)
+	}))
+}
+
+func (s *Server) getAccount(c *call) {
+	if a := s.lookupAccount(c); a != nil {
+		c.write(http.StatusOK, s.renderAccount(a))
+	}
+}
+
+func (s *Server) createAccount(c *call) {
+	var v recurly.Account
+	if !c.decode(&v) {
+		return
+	}
+
+	var errs validationErrors
+	if v.Code == "" {
+		errs.add("account.account_code", "blank", "can't be blank")
+	} else if s.findAccount(v.Code) != nil {
+		errs.add("account.account_code", "taken", "has already been taken")
+	}
+	if v.Email != "" && !strings.Contains(v.Email, "@") {
+		errs.add("account.email", "invalid_email", "is not a valid email address")
+	}
+	if errs.write(c) {
+		return
+	}
+
+	if a, ok := s.newAccount(c, v); ok {
+		s.accounts = append(s.accounts, a)
+		c.write(http.StatusCreated, s.renderAccount(a))
+	}
+}
+
+// newAccount returns an account for v, including its billing info if
+// provided. The account is not stored. If the billing info is invalid or
+// declined, the error is written and false is returned.
+func (s *Server) newAccount(c *call, v recurly.Account) (*account, bool) {
+	now := s.now()
+	a := &account{Account: v}
+	a.State = "active"
+	a.BillingInfo = nil
+	a.HostedLoginToken = newUUID()
+	a.CreatedAt, a.UpdatedAt = recurly.NewTime(now), recurly.NewTime(now)
+
+	if v.BillingInfo != nil {
+		b, ok := s.validateBilling(c, a, *v.BillingInfo)
+		if !ok {
+			return nil, false
+		}
+		a.billing = b
+	}
+	return a, true
+}
+
+func (s *Server) updateAccount(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	var v recurly.Account
+	if !c.decode(&v) {
+		return
+	}
+	if v.Email != "" && !strings.Contains(v.Email, "@") {
+		validationErrors{{Field: "account.email", Symbol: "invalid_email", Description: "is not a valid email address"}}.write(c)
+		return
+	}
+
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&a.Username, v.Username},
+		{&a.Email, v.Email},
+		{&a.FirstName, v.FirstName},
+		{&a.LastName, v.LastName},
+		{&a.CompanyName, v.CompanyName},
+		{&a.VATNumber, v.VATNumber},
+		{&a.AcceptLanguage, v.AcceptLanguage},
+		{&a.PreferredLocale, v.PreferredLocale},
+		{&a.ParentAccountCode, v.ParentAccountCode},
+	} {
+		if f.src != "" {
+			*f.dst = f.src
+		}
+	}
+	if v.CCEmails != nil {
+		a.CCEmails = v.CCEmails
+	}
+	if v.Address != nil {
+		a.Address = v.Address
+	}
+	if hasBool(v.TaxExempt) {
+		a.TaxExempt = v.TaxExempt
+	}
+	if v.CustomFields != nil {
+		a.CustomFields = v.CustomFields
+	}
+	a.UpdatedAt = recurly.NewTime(s.now())
+	c.write(http.StatusOK, s.renderAccount(a))
+}
+
+// closeAccount closes an account. Like Recurly, its live subscriptions are
+// expired immediately and its billing info is removed.
+func (s *Server) closeAccount(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	} else if a.State == "closed" {
+		c.invalidTransition("The account is already closed.")
+		return
+	}
+
+	now := recurly.NewTime(s.now())
+	for _, sub := range s.subscriptions {
+		if sub.AccountCode == a.Code && sub.State != recurly.SubscriptionStateExpired {
+			sub.State = recurly.SubscriptionStateExpired
+			sub.ExpiresAt = now
+		}
+	}
+	a.State, a.ClosedAt, a.UpdatedAt = "closed", now, now
+	a.billing = nil
+	c.w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) reopenAccount(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	} else if a.State != "closed" {
+		c.invalidTransition("The account is not closed.")
+		return
+	}
+
+	a.State, a.ClosedAt = "active", recurly.NullTime{}
+	a.UpdatedAt = recurly.NewTime(s.now())
+	c.write(http.StatusOK, s.renderAccount(a))
+}
+
+func (s *Server) getBilling(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	} else if a.billing == nil {
+		c.notFound("BillingInfo", "account_code", a.Code)
+		return
+	}
+	c.write(http.StatusOK, billing(a.billing))
+}
+
+func (s *Server) createBilling(c *call) {
+	s.storeBilling(c, http.StatusCreated)
+}
+
+func (s *Server) updateBilling(c *call) {
+	s.storeBilling(c, http.StatusOK)
+}
+
+func (s *Server) storeBilling(c *call, status int) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	var v recurly.Billing
+	if !c.decode(&v) {
+		return
+	}
+	b, ok := s.validateBilling(c, a, v)
+	if !ok {
+		return
+	}
+	a.billing = b
+	a.UpdatedAt = recurly.NewTime(s.now())
+	c.write(status, billing(b))
+}
+
+func (s *Server) clearBilling(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+	a.billing = nil
+	a.UpdatedAt = recurly.NewTime(s.now())
+	c.w.WriteHeader(http.StatusNoContent)
+}
+
+// validateBilling validates billing info and verifies cards with the
+// gateway. If the billing info is invalid, the error is written and false
+// is returned.
+func (s *Server) validateBilling(c *call, a *account, v recurly.Billing) (*recurly.Billing, bool) {
+	v.XMLName = xml.Name{Local: "billing_info"}
+	switch {
+	case v.PaypalAgreementID != "":
+		v.PaymentType = "paypal"
+		return &v, true
+	case v.AmazonAgreementID != "":
+		v.PaymentType = "amazon"
+		return &v, true
+	case v.RoutingNumber != "" || v.AccountNumber != "":
+		var errs validationErrors
+		if v.RoutingNumber == "" {
+			errs.add("billing_info.routing_number", "blank", "can't be blank")
+		}
+		if v.AccountNumber == "" {
+			errs.add("billing_info.account_number", "blank", "can't be blank")
+		}
+		if errs.write(c) {
+			return nil, false
+		}
+		v.PaymentType = "bank_account"
+		if n := len(v.AccountNumber); n > 4 {
+			v.LastFour = v.AccountNumber[n-4:]
+		}
+		return &v, true
+	case v.Number == 0 && v.Token != "":
+		// Tokens are treated as a valid Visa card.
+		v.Number, v.Month, v.Year = 4111111111111111, 12, s.now().Year()+5
+	}
+
+	var errs validationErrors
+	number := strconv.Itoa(v.Number)
+	if v.Number == 0 {
+		errs.add("billing_info.number", "required", "is required")
+	} else if !luhn(number) {
+		errs.add("billing_info.number", "invalid", "is not a valid credit card number")
+	}
+	now := s.now()
+	if v.Month < 1 || v.Month > 12 {
+		errs.add("billing_info.month", "invalid", "is not a valid month")
+	} else if v.Year < now.Year() || (v.Year == now.Year() && time.Month(v.Month) < now.Month()) {
+		errs.add("billing_info.year", "expired", "is expired or has an invalid expiration date")
+	}
+	if errs.write(c) {
+		return nil, false
+	}
+
+	v.PaymentType = "credit_card"
+	v.CardType = cardType(number)
+	v.FirstSix, v.LastFour = number[:6], number[len(number)-4:]
+	if v.Number == CardDeclined {
+		t := s.newTransaction(a, "verify", 0, v.Currency)
+		t.Account.BillingInfo = billing(&v)
+		s.decline(t)
+		c.declined(t)
+		return nil, false
+	}
+	return &v, true
+}
+
+// luhn returns true if number passes the Luhn checksum.
+func luhn(number string) bool {
+	if len(number) < 12 {
+		return false
+	}
+
+	var sum int
+	for i := range number {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// cardType returns the card type of number from its prefix.
+func cardType(number string) string {
+	switch {
+	case strings.HasPrefix(number, "4"):
+		return "visa"
+	case strings.HasPrefix(number, "34"), strings.HasPrefix(number, "37"):
+		return "american_express"
+	case strings.HasPrefix(number, "6011"), strings.HasPrefix(number, "65"):
+		return "discover"
+	case number[0] == '5' && number[1] >= '1' && number[1] <= '5', strings.HasPrefix(number, "2"):
+		return "master"
+	case strings.HasPrefix(number, "35"):
+		return "jcb"
+	}
+	return "unknown"
+}
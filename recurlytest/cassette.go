@@ -0,0 +1,332 @@
+package recurlytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/internal/xmlcanon"
+)
+
+// Filtered replaces scrubbed values in cassettes.
+const Filtered = "[FILTERED]"
+
+// ScrubbedHeaders are the headers whose values are replaced with Filtered
+// before an interaction is written to a cassette.
+var ScrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// ScrubbedElements are the XML elements whose contents are replaced with
+// Filtered in request and response bodies. They hold card and bank account
+// details.
+var ScrubbedElements = []string{
+	"number",
+	"verification_value",
+	"account_number",
+	"routing_number",
+	"iban",
+	"token_id",
+	"braintree_payment_nonce",
+}
+
+// Cassette is a recurly.HTTPDoer that records requests and responses to a
+// file, and replays them later without a network connection. Use Record to
+// capture interactions with the Recurly sandbox once:
+//
+//	cassette := recurlytest.Record("testdata/subscribe.json", http.DefaultClient)
+//	client := recurly.NewClient("subdomain", os.Getenv("RECURLY_API_KEY"))
+//	client.Client = cassette
+//
+// Then use Replay to serve the recorded responses in CI:
+//
+//	cassette, err := recurlytest.Replay("testdata/subscribe.json")
+//	client := recurly.NewClient("subdomain", "key")
+//	client.Client = cassette
+//
+// Credentials and card data are scrubbed before they are written. See
+// ScrubbedHeaders and ScrubbedElements. Only XML bodies are scrubbed and
+// stored as text; other bodies, such as invoice PDFs, are stored unchanged
+// in RawBody.
+type Cassette struct {
+	path string
+	doer recurly.HTTPDoer // nil when replaying
+
+	mu           sync.Mutex
+	interactions []*Interaction
+	used         []bool
+}
+
+// Interaction is a request and its response, as stored in a cassette.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is a request stored in a cassette.
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Query   string      `json:"query,omitempty"`
+	Header  http.Header `json:"header,omitempty"`
+	Body    string      `json:"body,omitempty"`     // XML bodies
+	RawBody []byte      `json:"raw_body,omitempty"` // other bodies
+}
+
+// RecordedResponse is a response stored in a cassette.
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`     // XML bodies
+	RawBody    []byte      `json:"raw_body,omitempty"` // other bodies
+}
+
+// Record returns a cassette that sends requests with doer and writes each
+// interaction to the file at path, replacing any previous recording.
+func Record(path string, doer recurly.HTTPDoer) *Cassette {
+	return &Cassette{path: path, doer: doer}
+}
+
+// Replay returns a cassette that serves the interactions recorded in the
+// file at path.
+func Replay(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cassette{path: path}
+	if err := json.Unmarshal(b, &c.interactions); err != nil {
+		return nil, fmt.Errorf("recurlytest: cannot read cassette %s: %v", path, err)
+	}
+	c.used = make([]bool, len(c.interactions))
+	return c, nil
+}
+
+// Do records or replays req.
+func (c *Cassette) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	var body []byte
+	if req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if c.doer == nil {
+		return c.replay(req, body)
+	}
+	return c.record(req, body)
+}
+
+// Unused returns the recorded interactions that have not been replayed.
+func (c *Cassette) Unused() []*Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var v []*Interaction
+	for i, used := range c.used {
+		if !used {
+			v = append(v, c.interactions[i])
+		}
+	}
+	return v
+}
+
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := c.doer.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	in := &Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  req.URL.RawQuery,
+			Header: scrubHeader(req.Header),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+		},
+	}
+	in.Request.Body, in.Request.RawBody = recordBody(req.Header, body)
+	in.Response.Body, in.Response.RawBody = recordBody(resp.Header, respBody)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, in)
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save writes the interactions to the cassette file.
+func (c *Cassette) save() error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c.interactions); err != nil {
+		return err
+	} else if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, buf.Bytes(), 0644)
+}
+
+// replay returns the response of the first unused interaction that matches
+// req by method, path, query and body.
+func (c *Cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	want, raw := recordBody(req.Header, body)
+	want, err := xmlcanon.Canonical([]byte(want))
+	if err != nil {
+		return nil, fmt.Errorf("recurlytest: cannot match %s %s: invalid body: %v", req.Method, req.URL.RequestURI(), err)
+	}
+
+	var reasons []string
+	for i, in := range c.interactions {
+		if c.used[i] || in.Request.Method != req.Method || in.Request.Path != req.URL.Path {
+			continue
+		} else if reason := in.Request.mismatch(req.URL.Query(), want, raw); reason != "" {
+			reasons = append(reasons, fmt.Sprintf("interaction %d: %s", i, reason))
+			continue
+		}
+
+		c.used[i] = true
+		return in.Response.response(req), nil
+	}
+
+	msg := fmt.Sprintf("recurlytest: no interaction in cassette %s matches %s %s", c.path, req.Method, req.URL.RequestURI())
+	if len(reasons) > 0 {
+		msg += " (" + strings.Join(reasons, "; ") + ")"
+	}
+	return nil, fmt.Errorf("%s", msg)
+}
+
+// mismatch returns the reason a request with query, the canonical XML body
+// and the raw body of any other type does not match r, or an empty string
+// if it matches.
+func (r RecordedRequest) mismatch(query url.Values, body string, raw []byte) string {
+	recorded, err := url.ParseQuery(r.Query)
+	if err != nil {
+		return fmt.Sprintf("invalid query: %v", err)
+	} else if len(recorded) > 0 || len(query) > 0 {
+		if !reflect.DeepEqual(recorded, query) {
+			return fmt.Sprintf("query: got %q, recorded %q", query.Encode(), recorded.Encode())
+		}
+	}
+
+	recordedBody, err := xmlcanon.Canonical([]byte(r.Body))
+	if err != nil {
+		return fmt.Sprintf("invalid body: %v", err)
+	} else if recordedBody != body {
+		return fmt.Sprintf("body: got %s, recorded %s", body, recordedBody)
+	} else if !bytes.Equal(r.RawBody, raw) {
+		return fmt.Sprintf("body: got %d bytes, recorded %d bytes", len(raw), len(r.RawBody))
+	}
+	return ""
+}
+
+func (r RecordedResponse) response(req *http.Request) *http.Response {
+	header := http.Header{}
+	for key, values := range r.Header {
+		header[key] = append([]string(nil), values...)
+	}
+	body := r.Body
+	if r.RawBody != nil {
+		body = string(r.RawBody)
+	}
+	return newResponse(req, r.StatusCode, header, body)
+}
+
+// newResponse returns a response to req.
+func newResponse(req *http.Request, status int, header http.Header, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// scrubHeader returns a copy of h with ScrubbedHeaders filtered.
+func scrubHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+
+	v := http.Header{}
+	for key, values := range h {
+		v[key] = append([]string(nil), values...)
+	}
+	for _, key := range ScrubbedHeaders {
+		if _, ok := v[http.CanonicalHeaderKey(key)]; ok {
+			v.Set(key, Filtered)
+		}
+	}
+	return v
+}
+
+// recordBody returns b as stored in a cassette. Bodies with an XML
+// Content-Type in h are scrubbed and returned as text. Other bodies are
+// returned unchanged as raw bytes.
+func recordBody(h http.Header, b []byte) (string, []byte) {
+	if len(b) == 0 {
+		return "", nil
+	} else if isXML(h) {
+		return scrubBody(b), nil
+	}
+	return "", b
+}
+
+// isXML returns true if the Content-Type in h is XML.
+func isXML(h http.Header) bool {
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml")
+}
+
+// scrubBody returns b with the contents of ScrubbedElements filtered.
+func scrubBody(b []byte) string {
+	for _, name := range ScrubbedElements {
+		re := regexp.MustCompile(`(<` + regexp.QuoteMeta(name) + `(?:\s[^>]*)?>)[^<]*(</` + regexp.QuoteMeta(name) + `>)`)
+		b = re.ReplaceAll(b, []byte("${1}"+Filtered+"${2}"))
+	}
+	return string(b)
+}
@@ -0,0 +1,128 @@
+package recurlytest_test
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/recurlytest"
+)
+
+func TestCassette(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "recurlytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	// Record interactions with a server.
+	s := NewTestServer()
+	client := recurly.NewClient("subdomain", "secret-api-key")
+	client.Client = recurlytest.Record(path, s.Client().Client)
+
+	MustCreateAccount(t, client, "1")
+	if _, err := client.Billing.Create(ctx, "1", TestCard); err != nil {
+		t.Fatal(err)
+	} else if _, err := client.Accounts.Update(ctx, "1", recurly.Account{FirstName: "Verena"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure credentials and card data are scrubbed.
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if str := string(b); strings.Contains(str, "4111111111111111") {
+		t.Fatalf("expected card number to be scrubbed: %s", str)
+	} else if strings.Contains(str, "Basic ") {
+		t.Fatalf("expected credentials to be scrubbed: %s", str)
+	} else if !strings.Contains(str, `<number>[FILTERED]</number>`) {
+		t.Fatalf("expected filtered card number: %s", str)
+	}
+
+	// Replay the interactions without the server.
+	cassette, err := recurlytest.Replay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = recurly.NewClient("subdomain", "another-api-key")
+	client.Client = cassette
+
+	if _, err := client.Accounts.Create(ctx, recurly.Account{Code: "1"}); err != nil {
+		t.Fatal(err)
+	} else if b, err := client.Billing.Create(ctx, "1", TestCard); err != nil {
+		t.Fatal(err)
+	} else if b.LastFour != "1111" {
+		t.Fatalf("unexpected billing info: %#v", b)
+	}
+
+	// Ensure requests must match the recorded body.
+	if _, err := client.Accounts.Update(ctx, "1", recurly.Account{FirstName: "Larry"}); err == nil {
+		t.Fatal("expected error")
+	} else if !strings.Contains(err.Error(), "no interaction in cassette") || !strings.Contains(err.Error(), "<first_name>Verena</first_name>") {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n := len(cassette.Unused()); n != 1 {
+		t.Fatalf("unexpected unused interactions: %d", n)
+	}
+
+	if a, err := client.Accounts.Update(ctx, "1", recurly.Account{FirstName: "Verena"}); err != nil {
+		t.Fatal(err)
+	} else if a.FirstName != "Verena" {
+		t.Fatalf("unexpected account: %#v", a)
+	} else if n := len(cassette.Unused()); n != 0 {
+		t.Fatalf("unexpected unused interactions: %d", n)
+	}
+
+	// Ensure each interaction is replayed once.
+	if _, err := client.Accounts.Create(ctx, recurly.Account{Code: "1"}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestCassette_BinaryBody(t *testing.T) {
+	ctx := context.Background()
+	dir, err := ioutil.TempDir("", "recurlytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	// Serve a PDF that is not valid UTF-8.
+	pdf := []byte("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	s := NewTestServer()
+	faults := recurlytest.NewFaultDoer(s.Client().Client, 1)
+	faults.Inject(func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		w := httptest.NewRecorder()
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write(pdf)
+		return w.Result(), nil
+	}).On("GET", "/v2/invoices/*")
+
+	client := recurly.NewClient("subdomain", "secret-api-key")
+	client.Client = recurlytest.Record(path, faults)
+	if b, err := client.Invoices.GetPDF(ctx, 1010, ""); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(b.Bytes(), pdf) {
+		t.Fatalf("unexpected pdf: %q", b.Bytes())
+	}
+
+	cassette, err := recurlytest.Replay(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.Client = cassette
+	if b, err := client.Invoices.GetPDF(ctx, 1010, ""); err != nil {
+		t.Fatal(err)
+	} else if !bytes.Equal(b.Bytes(), pdf) {
+		t.Fatalf("unexpected replayed pdf: %q", b.Bytes())
+	}
+}
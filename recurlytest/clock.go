@@ -0,0 +1,314 @@
+package recurlytest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+// DefaultDunningAttempts is the number of times a past due invoice is
+// retried when the server does not specify DunningAttempts.
+const DefaultDunningAttempts = 3
+
+// event is a change that falls due at a point in time. run applies the
+// change and returns the notifications it generates.
+type event struct {
+	at  time.Time
+	run func() []interface{}
+}
+
+// Advance moves the clock of s forward by d. See AdvanceTo.
+func (s *Server) Advance(d time.Duration) error {
+	s.mu.Lock()
+	t := s.now().Add(d)
+	s.mu.Unlock()
+	return s.AdvanceTo(t)
+}
+
+// AdvanceTo moves the clock of s to t, applying each change that falls due
+// on the way in order:
+//
+//   - Future subscriptions start and are invoiced.
+//   - Active subscriptions renew at the end of each billing period, applying
+//     pending changes, and trials convert when they end. Subscriptions
+//     with no remaining billing cycles expire unless they auto renew.
+//   - Canceled subscriptions expire.
+//   - Scheduled pauses start, paused subscriptions renew without being
+//     invoiced, and resume at their resume date.
+//   - Past due invoices are retried daily. After DunningAttempts retries
+//     the invoice fails and its subscriptions expire.
+//   - Invoices collected manually become past due on their due date.
+//
+// Invoices for renewals that are declined are kept as past due. The
+// resulting webhooks are delivered in order once the clock has been moved.
+// AdvanceTo returns the first error delivering webhooks to WebhookURL.
+//
+// Afterwards Now reports t until the clock is moved again.
+func (s *Server) AdvanceTo(t time.Time) error {
+	t = t.UTC().Truncate(time.Second)
+
+	s.mu.Lock()
+	var notifications []interface{}
+	for {
+		e, ok := s.nextEvent(t)
+		if !ok {
+			break
+		}
+		if e.at.After(s.now()) {
+			s.stopClock(e.at)
+		}
+		notifications = append(notifications, e.run()...)
+	}
+	s.stopClock(t)
+	s.mu.Unlock()
+
+	return s.deliver(notifications)
+}
+
+// stopClock sets the clock of s to t.
+func (s *Server) stopClock(t time.Time) {
+	s.Now = func() time.Time { return t }
+}
+
+// nextEvent returns the earliest event that falls due no later than t.
+func (s *Server) nextEvent(t time.Time) (event, bool) {
+	var next event
+	add := func(at recurly.NullTime, run func() []interface{}) {
+		if !hasTime(at) || at.Time().After(t) {
+			return
+		} else if next.run == nil || at.Time().Before(next.at) {
+			next = event{at: at.Time(), run: run}
+		}
+	}
+
+	for _, sub := range s.subscriptions {
+		sub := sub
+		switch sub.State {
+		case recurly.SubscriptionStateFuture:
+			add(sub.ActivatedAt, func() []interface{} { return s.activate(sub) })
+		case recurly.SubscriptionStateActive:
+			if hasTime(sub.PausedAt) && !sub.PausedAt.Time().After(sub.CurrentPeriodEndsAt.Time()) {
+				add(sub.PausedAt, func() []interface{} { return s.pause(sub) })
+			} else {
+				end := sub.CurrentPeriodEndsAt.Time()
+				add(sub.CurrentPeriodEndsAt, func() []interface{} { return s.renew(sub, end) })
+			}
+		case recurly.SubscriptionStateCanceled:
+			add(sub.ExpiresAt, func() []interface{} { return s.expire(sub, sub.ExpiresAt.Time()) })
+		case recurly.SubscriptionStatePaused:
+			end := sub.CurrentPeriodEndsAt.Time()
+			add(sub.CurrentPeriodEndsAt, func() []interface{} { return s.renewPaused(sub, end) })
+		}
+	}
+
+	for _, inv := range s.invoices {
+		inv := inv
+		switch {
+		case inv.State == recurly.ChargeInvoiceStatePastDue && inv.CollectionMethod == recurly.CollectionMethodAutomatic:
+			add(inv.AttemptNextCollectionAt, func() []interface{} { return s.retry(inv) })
+		case inv.State == recurly.ChargeInvoiceStatePending && inv.CollectionMethod == recurly.CollectionMethodManual:
+			add(inv.DueOn, func() []interface{} { return s.pastDue(inv) })
+		}
+	}
+	return next, next.run != nil
+}
+
+// activate starts a future subscription, beginning its trial if its plan
+// has one.
+func (s *Server) activate(sub *subscription) []interface{} {
+	start := sub.ActivatedAt.Time()
+	sub.State = recurly.SubscriptionStateActive
+
+	notifications := []interface{}{s.subscriptionNotification(webhooks.NewSubscription, sub)}
+	if p := s.findPlan(sub.Plan.Code); p != nil && p.TrialIntervalLength > 0 {
+		end := addInterval(start, p.TrialIntervalUnit, p.TrialIntervalLength)
+		sub.TrialStartedAt, sub.TrialEndsAt = recurly.NewTime(start), recurly.NewTime(end)
+		s.startPeriod(sub, start, end)
+		return notifications
+	}
+
+	s.startPeriod(sub, start, addInterval(start, sub.intervalUnit, sub.intervalLength))
+	return append(notifications, s.bill(sub, recurly.ChargeInvoiceOriginPurchase)...)
+}
+
+// renew starts the billing period of sub that begins at start, or expires
+// sub if it has no remaining billing cycles and does not auto renew.
+func (s *Server) renew(sub *subscription, start time.Time) []interface{} {
+	if hasInt(sub.RemainingBillingCycles) && sub.RemainingBillingCycles.Int() <= 0 {
+		if !sub.AutoRenew {
+			return s.expire(sub, start)
+		}
+		sub.RemainingBillingCycles = sub.RenewalBillingCycles
+	}
+
+	if p := sub.PendingSubscription; p != nil {
+		sub.Plan = p.Plan
+		sub.UnitAmountInCents = p.UnitAmountInCents
+		sub.Quantity = p.Quantity
+		sub.SubscriptionAddOns = p.SubscriptionAddOns
+		sub.TotalAmountInCents = subscriptionTotal(&sub.Subscription)
+		sub.PendingSubscription = nil
+		if plan := s.findPlan(p.Plan.Code); plan != nil {
+			sub.intervalUnit, sub.intervalLength = plan.IntervalUnit, plan.IntervalLength
+		}
+	}
+
+	s.startPeriod(sub, start, addInterval(start, sub.intervalUnit, sub.intervalLength))
+	notifications := []interface{}{s.subscriptionNotification(webhooks.RenewedSubscription, sub)}
+	return append(notifications, s.bill(sub, recurly.ChargeInvoiceOriginRenewal)...)
+}
+
+// expire expires sub at t.
+func (s *Server) expire(sub *subscription, t time.Time) []interface{} {
+	sub.State = recurly.SubscriptionStateExpired
+	sub.ExpiresAt = recurly.NewTime(t)
+	sub.PausedAt, sub.ResumeAt, sub.RemainingPauseCycles = recurly.NullTime{}, recurly.NullTime{}, 0
+	return []interface{}{s.subscriptionNotification(webhooks.ExpiredSubscription, sub)}
+}
+
+// pause starts the scheduled pause of sub. Paused billing periods are not
+// invoiced.
+func (s *Server) pause(sub *subscription) []interface{} {
+	start := sub.PausedAt.Time()
+	sub.State = recurly.SubscriptionStatePaused
+	s.startPeriod(sub, start, addInterval(start, sub.intervalUnit, sub.intervalLength))
+	return []interface{}{s.subscriptionNotification(webhooks.PausedSubscription, sub)}
+}
+
+// renewPaused starts the billing period of a paused subscription that begins
+// at start, resuming and invoicing it once it reaches its resume date.
+func (s *Server) renewPaused(sub *subscription, start time.Time) []interface{} {
+	s.startPeriod(sub, start, addInterval(start, sub.intervalUnit, sub.intervalLength))
+	if hasTime(sub.ResumeAt) && sub.ResumeAt.Time().After(start) {
+		sub.RemainingPauseCycles--
+		return []interface{}{s.subscriptionNotification(webhooks.PausedRenewalSubscription, sub)}
+	}
+
+	sub.State = recurly.SubscriptionStateActive
+	sub.PausedAt, sub.ResumeAt, sub.RemainingPauseCycles = recurly.NullTime{}, recurly.NullTime{}, 0
+	notifications := []interface{}{s.subscriptionNotification(webhooks.ResumedSubscription, sub)}
+	return append(notifications, s.bill(sub, recurly.ChargeInvoiceOriginRenewal)...)
+}
+
+// bill invoices the current billing period of sub and collects the invoice
+// if sub is collected automatically. Unlike purchases made through the API,
+// declined charges are kept on a past due invoice.
+func (s *Server) bill(sub *subscription, origin string) []interface{} {
+	a := s.findAccount(sub.AccountCode)
+	adjs := s.subscriptionCharges(sub)
+	reds := s.activeRedemptions(a.Code, sub.UUID)
+	discounts := s.discount(adjs, reds)
+	inv := s.newInvoice(a, adjs, origin, sub.CollectionMethod, sub.NetTerms)
+	inv.PONumber = sub.PONumber
+	s.commitInvoice(inv, adjs)
+	s.commitDiscounts(reds, discounts)
+	sub.InvoiceNumber = inv.InvoiceNumber
+	useBillingCycle(sub)
+
+	notifications := []interface{}{s.invoiceNotification(webhooks.NewChargeInvoice, inv)}
+	if sub.CollectionMethod != recurly.CollectionMethodAutomatic {
+		return notifications
+	}
+
+	t := s.collect(a, inv)
+	switch {
+	case t == nil:
+		return append(notifications, s.invoiceNotification(webhooks.PaidChargeInvoice, inv))
+	case t.Status == recurly.TransactionStatusSuccess:
+		return append(notifications,
+			s.paymentNotification(webhooks.SuccessfulPayment, inv, t),
+			s.invoiceNotification(webhooks.PaidChargeInvoice, inv),
+		)
+	}
+	return append(notifications,
+		s.paymentNotification(webhooks.FailedPayment, inv, t),
+		s.invoiceNotification(webhooks.PastDueChargeInvoice, inv),
+		s.dunningNotification(inv),
+	)
+}
+
+// useBillingCycle decrements the remaining billing cycles of sub, if it has
+// a limited number of billing cycles.
+func useBillingCycle(sub *subscription) {
+	if hasInt(sub.RemainingBillingCycles) && sub.RemainingBillingCycles.Int() > 0 {
+		sub.RemainingBillingCycles = recurly.NewInt(sub.RemainingBillingCycles.Int() - 1)
+	}
+}
+
+// retry collects a past due invoice. Once the invoice has been retried
+// DunningAttempts times it fails and its subscriptions expire.
+func (s *Server) retry(inv *recurly.Invoice) []interface{} {
+	s.attempts[inv.InvoiceNumber]++
+	t := s.collect(s.findAccount(inv.AccountCode), inv)
+	if t == nil || t.Status == recurly.TransactionStatusSuccess {
+		delete(s.attempts, inv.InvoiceNumber)
+		notifications := []interface{}{s.invoiceNotification(webhooks.PaidChargeInvoice, inv)}
+		if t != nil {
+			notifications = append([]interface{}{s.paymentNotification(webhooks.SuccessfulPayment, inv, t)}, notifications...)
+		}
+		return notifications
+	}
+
+	notifications := []interface{}{s.paymentNotification(webhooks.FailedPayment, inv, t)}
+	attempts := s.DunningAttempts
+	if attempts <= 0 {
+		attempts = DefaultDunningAttempts
+	}
+	if s.attempts[inv.InvoiceNumber] < attempts {
+		return append(notifications, s.dunningNotification(inv))
+	}
+
+	delete(s.attempts, inv.InvoiceNumber)
+	s.closeInvoice(inv, recurly.ChargeInvoiceStateFailed)
+	notifications = append(notifications, s.invoiceNotification(webhooks.FailedChargeInvoice, inv))
+	for _, sub := range s.subscriptions {
+		if sub.InvoiceNumber == inv.InvoiceNumber && sub.State != recurly.SubscriptionStateExpired {
+			notifications = append(notifications, s.expire(sub, s.now())...)
+		}
+	}
+	return notifications
+}
+
+// pastDue marks an unpaid invoice collected manually as past due.
+func (s *Server) pastDue(inv *recurly.Invoice) []interface{} {
+	inv.State = recurly.ChargeInvoiceStatePastDue
+	inv.UpdatedAt = recurly.NewTime(s.now())
+	return []interface{}{s.invoiceNotification(webhooks.PastDueChargeInvoice, inv)}
+}
+
+// deliver sends notifications to WebhookURL and the Webhooks channel.
+func (s *Server) deliver(notifications []interface{}) error {
+	for _, n := range notifications {
+		if s.WebhookURL != "" {
+			if err := s.post(n); err != nil {
+				return err
+			}
+		}
+		if s.Webhooks != nil {
+			s.Webhooks <- n
+		}
+	}
+	return nil
+}
+
+// post sends the XML webhook for n to WebhookURL.
+func (s *Server) post(n interface{}) error {
+	b, err := webhooks.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.Post(s.WebhookURL, "application/xml; charset=utf-8", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("recurlytest: webhook %T: %s", n, resp.Status)
+	}
+	return nil
+}
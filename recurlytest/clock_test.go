@@ -0,0 +1,348 @@
+package recurlytest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/recurlytest"
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+func TestServer_Advance(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Renewal", func(t *testing.T) {
+		s, ch := NewClockServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+		sub := MustCreateSubscription(t, client, "gold", TestCard)
+		periodEnd := sub.CurrentPeriodEndsAt.Time()
+
+		// Ensure nothing happens before the end of the billing period.
+		if err := s.AdvanceTo(periodEnd.Add(-time.Second)); err != nil {
+			t.Fatal(err)
+		} else if diff := NotificationTypes(ch); len(diff) != 0 {
+			t.Fatalf("unexpected notifications: %v", diff)
+		}
+
+		if err := s.Advance(time.Second); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{
+			webhooks.RenewedSubscription,
+			webhooks.NewChargeInvoice,
+			webhooks.SuccessfulPayment,
+			webhooks.PaidChargeInvoice,
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		}
+
+		renewed, err := client.Subscriptions.Get(ctx, sub.UUID)
+		if err != nil {
+			t.Fatal(err)
+		} else if !renewed.CurrentPeriodStartedAt.Time().Equal(periodEnd) || !renewed.CurrentPeriodEndsAt.Time().Equal(periodEnd.AddDate(0, 1, 0)) {
+			t.Fatalf("unexpected period: %s - %s", renewed.CurrentPeriodStartedAt, renewed.CurrentPeriodEndsAt)
+		} else if inv, err := client.Invoices.Get(ctx, renewed.InvoiceNumber); err != nil {
+			t.Fatal(err)
+		} else if inv.Origin != recurly.ChargeInvoiceOriginRenewal || inv.State != recurly.ChargeInvoiceStatePaid || !inv.CreatedAt.Time().Equal(periodEnd) {
+			t.Fatalf("unexpected invoice: %#v", inv)
+		}
+	})
+
+	t.Run("PendingChanges", func(t *testing.T) {
+		s, ch := NewClockServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "silver", 1000)
+		MustCreatePlan(t, client, "gold", 3000)
+		sub := MustCreateSubscription(t, client, "silver", TestCard)
+
+		if _, err := client.Subscriptions.Update(ctx, sub.UUID, recurly.UpdateSubscription{Timeframe: "renewal", PlanCode: "gold"}); err != nil {
+			t.Fatal(err)
+		} else if err := s.AdvanceTo(sub.CurrentPeriodEndsAt.Time()); err != nil {
+			t.Fatal(err)
+		}
+
+		n := (<-ch).(*webhooks.SubscriptionNotification)
+		if n.Subscription.Plan.Code != "gold" || n.Subscription.TotalAmountInCents != 3000 || n.Subscription.PendingSubscription != nil {
+			t.Fatalf("unexpected subscription: %#v", n.Subscription)
+		} else if n := (<-ch).(*webhooks.ChargeInvoiceNotification); n.Invoice.TotalInCents != 3000 || n.Invoice.SubscriptionUUIDs[0] != sub.UUID {
+			t.Fatalf("unexpected invoice: %#v", n.Invoice)
+		}
+	})
+
+	t.Run("TrialDunning", func(t *testing.T) {
+		s, ch := NewClockServer()
+		s.DunningAttempts = 2
+		client := s.Client()
+		if _, err := client.Plans.Create(ctx, recurly.Plan{
+			Code:                "gold",
+			Name:                "Gold",
+			UnitAmountInCents:   recurly.UnitAmount{USD: 1000},
+			TrialIntervalUnit:   "days",
+			TrialIntervalLength: 7,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		billing := TestCard
+		billing.Number = recurlytest.CardDeclinedOnCharge
+		sub := MustCreateSubscription(t, client, "gold", billing)
+
+		if err := s.AdvanceTo(sub.TrialEndsAt.Time()); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{
+			webhooks.RenewedSubscription,
+			webhooks.NewChargeInvoice,
+			webhooks.FailedPayment,
+			webhooks.PastDueChargeInvoice,
+			webhooks.NewDunningEvent,
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		}
+
+		var subs []recurly.Subscription
+		if err := client.Subscriptions.List(&recurly.PagerOptions{State: recurly.SubscriptionStatePastDue}).FetchAll(ctx, &subs); err != nil {
+			t.Fatal(err)
+		} else if len(subs) != 1 || subs[0].State != recurly.SubscriptionStateActive {
+			t.Fatalf("unexpected subscriptions: %#v", subs)
+		}
+
+		// Ensure retries are made daily until the invoice fails.
+		if err := s.Advance(24 * time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{webhooks.FailedPayment, webhooks.NewDunningEvent}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		} else if err := s.Advance(24 * time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{
+			webhooks.FailedPayment,
+			webhooks.FailedChargeInvoice,
+			webhooks.ExpiredSubscription,
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		}
+
+		if sub, err := client.Subscriptions.Get(ctx, sub.UUID); err != nil {
+			t.Fatal(err)
+		} else if sub.State != recurly.SubscriptionStateExpired {
+			t.Fatalf("unexpected state: %s", sub.State)
+		} else if inv, err := client.Invoices.Get(ctx, sub.InvoiceNumber); err != nil {
+			t.Fatal(err)
+		} else if inv.State != recurly.ChargeInvoiceStateFailed || len(inv.Transactions) != 3 {
+			t.Fatalf("unexpected invoice: %#v", inv)
+		}
+	})
+
+	t.Run("DunningRecovered", func(t *testing.T) {
+		s, ch := NewClockServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+		sub := MustCreateSubscription(t, client, "gold", TestCard)
+
+		billing := TestCard
+		billing.Number = recurlytest.CardDeclinedOnCharge
+		if _, err := client.Billing.Update(ctx, "1", billing); err != nil {
+			t.Fatal(err)
+		} else if err := s.AdvanceTo(sub.CurrentPeriodEndsAt.Time()); err != nil {
+			t.Fatal(err)
+		}
+		NotificationTypes(ch)
+
+		if _, err := client.Billing.Update(ctx, "1", TestCard); err != nil {
+			t.Fatal(err)
+		} else if err := s.Advance(24 * time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{webhooks.SuccessfulPayment, webhooks.PaidChargeInvoice}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		}
+	})
+
+	t.Run("BillingCycles", func(t *testing.T) {
+		s, ch := NewClockServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+
+		billing := TestCard
+		sub, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode:           "gold",
+			Currency:           "USD",
+			TotalBillingCycles: 2,
+			Account:            recurly.Account{Code: "1", BillingInfo: &billing},
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if sub.RemainingBillingCycles.Int() != 1 {
+			t.Fatalf("unexpected remaining billing cycles: %d", sub.RemainingBillingCycles.Int())
+		} else if _, err := client.Subscriptions.Update(ctx, sub.UUID, recurly.UpdateSubscription{AutoRenew: recurly.NewBool(false)}); err != nil {
+			t.Fatal(err)
+		}
+
+		end := sub.CurrentPeriodEndsAt.Time().AddDate(0, 1, 0)
+		if err := s.AdvanceTo(end); err != nil {
+			t.Fatal(err)
+		} else if got := NotificationTypes(ch); len(got) != 5 || got[0] != webhooks.RenewedSubscription || got[4] != webhooks.ExpiredSubscription {
+			t.Fatalf("unexpected notifications: %v", got)
+		} else if sub, err := client.Subscriptions.Get(ctx, sub.UUID); err != nil {
+			t.Fatal(err)
+		} else if sub.State != recurly.SubscriptionStateExpired || !sub.ExpiresAt.Time().Equal(end) || sub.RemainingBillingCycles.Int() != 0 {
+			t.Fatalf("unexpected subscription: %#v", sub)
+		}
+	})
+
+	t.Run("Pause", func(t *testing.T) {
+		s, ch := NewClockServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+		sub := MustCreateSubscription(t, client, "gold", TestCard)
+		periodEnd := sub.CurrentPeriodEndsAt.Time()
+
+		if _, err := client.Subscriptions.Pause(ctx, sub.UUID, 2); err != nil {
+			t.Fatal(err)
+		} else if err := s.AdvanceTo(periodEnd); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{webhooks.PausedSubscription}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		} else if sub, err := client.Subscriptions.Get(ctx, sub.UUID); err != nil {
+			t.Fatal(err)
+		} else if sub.State != recurly.SubscriptionStatePaused {
+			t.Fatalf("unexpected state: %s", sub.State)
+		}
+
+		if err := s.AdvanceTo(periodEnd.AddDate(0, 2, 0)); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{
+			webhooks.PausedRenewalSubscription,
+			webhooks.ResumedSubscription,
+			webhooks.NewChargeInvoice,
+			webhooks.SuccessfulPayment,
+			webhooks.PaidChargeInvoice,
+		}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		} else if sub, err := client.Subscriptions.Get(ctx, sub.UUID); err != nil {
+			t.Fatal(err)
+		} else if sub.State != recurly.SubscriptionStateActive || sub.PausedAt.Time() != (time.Time{}) {
+			t.Fatalf("unexpected subscription: %#v", sub)
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		s, ch := NewClockServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+		sub := MustCreateSubscription(t, client, "gold", TestCard)
+
+		if _, err := client.Subscriptions.Cancel(ctx, sub.UUID); err != nil {
+			t.Fatal(err)
+		} else if err := s.Advance(365 * 24 * time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{webhooks.ExpiredSubscription}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		}
+	})
+
+	t.Run("ManualPastDue", func(t *testing.T) {
+		s, ch := NewClockServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+		if _, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode:         "gold",
+			Currency:         "USD",
+			CollectionMethod: recurly.CollectionMethodManual,
+			NetTerms:         recurly.NewInt(10),
+			Account:          recurly.Account{Code: "1"},
+		}); err != nil {
+			t.Fatal(err)
+		} else if err := s.Advance(10 * 24 * time.Hour); err != nil {
+			t.Fatal(err)
+		} else if got, want := NotificationTypes(ch), []string{webhooks.PastDueChargeInvoice}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("unexpected notifications: %v", got)
+		}
+	})
+}
+
+func TestServer_WebhookURL(t *testing.T) {
+	var received []string
+	status := http.StatusOK
+	h := webhooks.NewHandler()
+	h.HandleSubscription(func(ctx context.Context, n *webhooks.SubscriptionNotification) error {
+		received = append(received, n.Type+":"+n.Account.Code)
+		return nil
+	}, webhooks.RenewedSubscription)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	s := NewTestServer()
+	s.WebhookURL = ts.URL
+	client := s.Client()
+	MustCreatePlan(t, client, "gold", 1000)
+	sub := MustCreateSubscription(t, client, "gold", TestCard)
+
+	if err := s.AdvanceTo(sub.CurrentPeriodEndsAt.Time()); err != nil {
+		t.Fatal(err)
+	} else if len(received) != 1 || received[0] != webhooks.RenewedSubscription+":1" {
+		t.Fatalf("unexpected webhooks: %v", received)
+	}
+
+	// Ensure delivery failures are returned.
+	status = http.StatusInternalServerError
+	if err := s.Advance(31 * 24 * time.Hour); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+// NewClockServer returns a server with a fixed clock that sends
+// notifications to the returned channel.
+func NewClockServer() (*recurlytest.Server, chan interface{}) {
+	ch := make(chan interface{}, 100)
+	s := NewTestServer()
+	s.Webhooks = ch
+	return s, ch
+}
+
+// MustCreateSubscription subscribes account "1" to plan, creating the
+// account with billing.
+func MustCreateSubscription(t *testing.T, client *recurly.Client, plan string, billing recurly.Billing) *recurly.Subscription {
+	t.Helper()
+	sub, err := client.Subscriptions.Create(context.Background(), recurly.NewSubscription{
+		PlanCode: plan,
+		Currency: "USD",
+		Account:  recurly.Account{Code: "1", BillingInfo: &billing},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+// NotificationTypes drains ch and returns the types of the notifications
+// received.
+func NotificationTypes(ch chan interface{}) []string {
+	var types []string
+	for {
+		select {
+		case n := <-ch:
+			switch n := n.(type) {
+			case *webhooks.SubscriptionNotification:
+				types = append(types, n.Type)
+			case *webhooks.ChargeInvoiceNotification:
+				types = append(types, n.Type)
+			case *webhooks.PaymentNotification:
+				types = append(types, n.Type)
+			case *webhooks.NewDunningEventNotification:
+				types = append(types, n.Type)
+			}
+		default:
+			return types
+		}
+	}
+}
@@ -0,0 +1,397 @@
+package recurlytest
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// Coupon states.
+const (
+	couponStateRedeemable = "redeemable"
+	couponStateExpired    = "expired"
+	couponStateMaxedOut   = "maxed_out"
+)
+
+// Redemption states.
+const (
+	redemptionStateActive   = "active"
+	redemptionStateInactive = "inactive"
+)
+
+func (s *Server) findCoupon(code string) *recurly.Coupon {
+	for _, cp := range s.coupons {
+		if cp.Code == code {
+			return cp
+		}
+	}
+	return nil
+}
+
+// lookupCoupon returns the coupon in the coupon_code parameter, or writes a
+// 404 and returns nil.
+func (s *Server) lookupCoupon(c *call) *recurly.Coupon {
+	code := c.param("coupon_code")
+	cp := s.findCoupon(code)
+	if cp == nil {
+		c.notFound("Coupon", "coupon_code", code)
+	}
+	return cp
+}
+
+func (s *Server) listCoupons(c *call) {
+	c.writeList("coupons", filter(len(s.coupons), func(i int) (interface{}, bool) {
+		return s.coupons[i], c.stateMatches(s.coupons[i].State)
+	}))
+}
+
+func (s *Server) getCoupon(c *call) {
+	if cp := s.lookupCoupon(c); cp != nil {
+		c.write(http.StatusOK, cp)
+	}
+}
+
+func (s *Server) createCoupon(c *call) {
+	var v recurly.Coupon
+	if !c.decode(&v) {
+		return
+	}
+
+	var errs validationErrors
+	if v.Code == "" {
+		errs.add("coupon.coupon_code", "blank", "can't be blank")
+	} else if s.findCoupon(v.Code) != nil {
+		errs.add("coupon.coupon_code", "taken", "has already been taken")
+	}
+	if v.Name == "" {
+		errs.add("coupon.name", "blank", "can't be blank")
+	}
+	switch v.DiscountType {
+	case "percent":
+		if !hasInt(v.DiscountPercent) || v.DiscountPercent.Int() < 1 || v.DiscountPercent.Int() > 100 {
+			errs.add("coupon.discount_percent", "invalid", "must be between 1 and 100")
+		}
+	case "dollars":
+		if v.DiscountInCents == nil || *v.DiscountInCents == (recurly.UnitAmount{}) {
+			errs.add("coupon.discount_in_cents", "blank", "can't be blank")
+		}
+	case "free_trial":
+	default:
+		errs.add("coupon.discount_type", "invalid", "is not included in the list")
+	}
+	if v.Duration != "" && v.Duration != "forever" && v.Duration != "single_use" && v.Duration != "temporal" {
+		errs.add("coupon.duration", "invalid", "is not included in the list")
+	}
+	if errs.write(c) {
+		return
+	}
+
+	s.couponID++
+	now := recurly.NewTime(s.now())
+	v.XMLName = xml.Name{Local: "coupon"}
+	v.ID = s.couponID
+	v.State = couponStateRedeemable
+	v.CreatedAt, v.UpdatedAt = now, now
+	if v.Type == "" {
+		v.Type = "single_code"
+	}
+	if v.Duration == "" {
+		v.Duration = "forever"
+	}
+	if v.RedemptionResource == "" {
+		v.RedemptionResource = "account"
+	}
+
+	s.coupons = append(s.coupons, &v)
+	c.write(http.StatusCreated, &v)
+}
+
+func (s *Server) updateCoupon(c *call) {
+	cp := s.lookupCoupon(c)
+	if cp == nil {
+		return
+	}
+
+	var v recurly.Coupon
+	if !c.decode(&v) {
+		return
+	}
+	s.updateCouponFields(cp, v)
+	c.write(http.StatusOK, cp)
+}
+
+// updateCouponFields updates the fields of cp that can be changed after it
+// is created.
+func (s *Server) updateCouponFields(cp *recurly.Coupon, v recurly.Coupon) {
+	if v.Name != "" {
+		cp.Name = v.Name
+	}
+	if v.Description != "" {
+		cp.Description = v.Description
+	}
+	if v.InvoiceDescription != "" {
+		cp.InvoiceDescription = v.InvoiceDescription
+	}
+	if hasTime(v.RedeemByDate) {
+		cp.RedeemByDate = v.RedeemByDate
+	}
+	if hasInt(v.MaxRedemptions) {
+		cp.MaxRedemptions = v.MaxRedemptions
+	}
+	if hasInt(v.MaxRedemptionsPerAccount) {
+		cp.MaxRedemptionsPerAccount = v.MaxRedemptionsPerAccount
+	}
+	cp.UpdatedAt = recurly.NewTime(s.now())
+}
+
+// deleteCoupon expires a coupon. Existing redemptions are unaffected.
+func (s *Server) deleteCoupon(c *call) {
+	cp := s.lookupCoupon(c)
+	if cp == nil {
+		return
+	}
+
+	now := recurly.NewTime(s.now())
+	cp.State, cp.DeletedAt, cp.UpdatedAt = couponStateExpired, now, now
+	c.w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) restoreCoupon(c *call) {
+	cp := s.lookupCoupon(c)
+	if cp == nil {
+		return
+	} else if cp.State != couponStateExpired {
+		c.invalidTransition("Only expired coupons can be restored.")
+		return
+	}
+
+	var v recurly.Coupon
+	if !c.decode(&v) {
+		return
+	}
+	s.updateCouponFields(cp, v)
+	cp.State, cp.DeletedAt = couponStateRedeemable, recurly.NullTime{}
+	c.write(http.StatusOK, cp)
+}
+
+func (s *Server) redeemCoupon(c *call) {
+	cp := s.lookupCoupon(c)
+	if cp == nil {
+		return
+	}
+
+	var v recurly.CouponRedemption
+	if !c.decode(&v) {
+		return
+	}
+
+	a := s.findAccount(v.AccountCode)
+	if a == nil {
+		c.notFound("Account", "account_code", v.AccountCode)
+		return
+	}
+
+	var errs validationErrors
+	if v.Currency == "" {
+		errs.add("redemption.currency", "blank", "can't be blank")
+	}
+	if v.SubscriptionUUID != "" {
+		if sub := s.findSubscription(v.SubscriptionUUID); sub == nil || sub.AccountCode != a.Code {
+			errs.add("redemption.subscription_uuid", "invalid", "is invalid")
+		}
+	}
+	if symbol, desc := s.checkRedeemable(cp, a.Code); symbol != "" {
+		errs.add("redemption.coupon", symbol, desc)
+	}
+	if errs.write(c) {
+		return
+	}
+
+	r := s.newRedemption(cp, a.Code, v.Currency, v.SubscriptionUUID)
+	s.addRedemption(r)
+	c.write(http.StatusCreated, c.redemption(r))
+}
+
+// checkRedeemable returns the symbol and description of the error if cp
+// cannot be redeemed by the account with accountCode.
+func (s *Server) checkRedeemable(cp *recurly.Coupon, accountCode string) (string, string) {
+	if hasTime(cp.RedeemByDate) && !s.now().Before(cp.RedeemByDate.Time()) {
+		cp.State = couponStateExpired
+	}
+
+	switch cp.State {
+	case couponStateExpired:
+		return "expired", "has expired"
+	case couponStateMaxedOut:
+		return "maxed_out", "has reached its maximum number of redemptions"
+	}
+
+	var n int
+	for _, r := range s.redemptions {
+		if r.CouponCode != cp.Code || r.AccountCode != accountCode {
+			continue
+		} else if r.State == redemptionStateActive {
+			return "taken", "has already been redeemed by this account"
+		}
+		n++
+	}
+	if hasInt(cp.MaxRedemptionsPerAccount) && n >= cp.MaxRedemptionsPerAccount.Int() {
+		return "maxed_out", "has reached its maximum number of redemptions for this account"
+	}
+	return "", ""
+}
+
+// newRedemption returns an active redemption of cp. It is not stored.
+func (s *Server) newRedemption(cp *recurly.Coupon, accountCode, currency, subscriptionUUID string) *recurly.Redemption {
+	now := recurly.NewTime(s.now())
+	return &recurly.Redemption{
+		UUID:             newUUID(),
+		SubscriptionUUID: subscriptionUUID,
+		AccountCode:      accountCode,
+		CouponCode:       cp.Code,
+		SingleUse:        cp.Duration == "single_use",
+		Currency:         currency,
+		State:            redemptionStateActive,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+}
+
+// addRedemption stores r and marks its coupon maxed out once it reaches
+// its maximum number of redemptions.
+func (s *Server) addRedemption(r *recurly.Redemption) {
+	s.redemptions = append(s.redemptions, r)
+
+	cp := s.findCoupon(r.CouponCode)
+	if !hasInt(cp.MaxRedemptions) {
+		return
+	}
+
+	var n int
+	for _, r := range s.redemptions {
+		if r.CouponCode == cp.Code {
+			n++
+		}
+	}
+	if n >= cp.MaxRedemptions.Int() {
+		cp.State = couponStateMaxedOut
+	}
+}
+
+// activeRedemptions returns the active redemptions of an account that apply
+// to the subscription with uuid, or to the account if uuid is empty.
+func (s *Server) activeRedemptions(accountCode, uuid string) []*recurly.Redemption {
+	var reds []*recurly.Redemption
+	for _, r := range s.redemptions {
+		if r.AccountCode == accountCode && r.State == redemptionStateActive && (r.SubscriptionUUID == "" || r.SubscriptionUUID == uuid) {
+			reds = append(reds, r)
+		}
+	}
+	return reds
+}
+
+// discount applies the coupons of reds to adjs and returns the amount
+// discounted by each redemption. Coupons only discount subscription charges
+// unless they apply to non-plan charges.
+func (s *Server) discount(adjs []*recurly.Adjustment, reds []*recurly.Redemption) []int {
+	discounts := make([]int, len(reds))
+	for i, r := range reds {
+		cp := s.findCoupon(r.CouponCode)
+		if cp == nil {
+			continue
+		}
+
+		var remaining int
+		if cp.DiscountType == "dollars" && cp.DiscountInCents != nil && len(adjs) > 0 {
+			remaining, _ = amount(*cp.DiscountInCents, adjs[0].Currency)
+		}
+		for _, adj := range adjs {
+			if adj.TotalInCents <= 0 {
+				continue
+			} else if adj.SubscriptionUUID == "" && !cp.AppliesToNonPlanCharges {
+				continue
+			} else if r.SubscriptionUUID != "" && adj.SubscriptionUUID != r.SubscriptionUUID {
+				continue
+			}
+
+			var d int
+			switch cp.DiscountType {
+			case "percent":
+				d = adj.TotalInCents * cp.DiscountPercent.Int() / 100
+			case "dollars":
+				if d = remaining; d > adj.TotalInCents {
+					d = adj.TotalInCents
+				}
+				remaining -= d
+			}
+			adj.DiscountInCents += d
+			adj.TotalInCents -= d
+			discounts[i] += d
+		}
+	}
+	return discounts
+}
+
+// commitDiscounts records the amounts discounted by reds. Single use
+// redemptions become inactive once they have been used.
+func (s *Server) commitDiscounts(reds []*recurly.Redemption, discounts []int) {
+	now := recurly.NewTime(s.now())
+	for i, r := range reds {
+		if discounts[i] == 0 {
+			continue
+		}
+		r.TotalDiscountedInCents += discounts[i]
+		r.UpdatedAt = now
+		if r.SingleUse {
+			r.State = redemptionStateInactive
+		}
+	}
+}
+
+func (s *Server) listAccountRedemptions(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	c.writeList("redemptions", filter(len(s.redemptions), func(i int) (interface{}, bool) {
+		r := s.redemptions[i]
+		return c.redemption(r), r.AccountCode == a.Code && c.stateMatches(r.State)
+	}))
+}
+
+func (s *Server) listSubscriptionRedemptions(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	}
+
+	c.writeList("redemptions", filter(len(s.redemptions), func(i int) (interface{}, bool) {
+		r := s.redemptions[i]
+		return c.redemption(r), r.SubscriptionUUID == sub.UUID && c.stateMatches(r.State)
+	}))
+}
+
+// deleteRedemption removes a coupon from an account by making its
+// redemption inactive.
+func (s *Server) deleteRedemption(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	uuid := c.param("uuid")
+	for _, r := range s.redemptions {
+		if r.UUID != uuid || r.AccountCode != a.Code {
+			continue
+		} else if r.State != redemptionStateActive {
+			c.invalidTransition("The redemption is not active.")
+			return
+		}
+		r.State, r.UpdatedAt = redemptionStateInactive, recurly.NewTime(s.now())
+		c.w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	c.notFound("Redemption", "uuid", uuid)
+}
@@ -0,0 +1,255 @@
+package recurlytest
+
+import (
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// Fault is a failure injected by a FaultDoer. It returns the result of req,
+// which may be sent with next.
+type Fault func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error)
+
+// FaultDoer is a recurly.HTTPDoer that wraps another doer and injects
+// faults into requests, to test how code handles failures of the API:
+//
+//	faults := recurlytest.NewFaultDoer(http.DefaultClient, 1)
+//	faults.Inject(recurlytest.RateLimited(time.Now().Add(time.Minute))).
+//		On("GET", "/v2/accounts/*").
+//		Probability(0.25)
+//	client.Client = faults
+//
+// Faults are chosen with a random source seeded by NewFaultDoer, so runs
+// that send the same requests in the same order inject the same faults.
+type FaultDoer struct {
+	doer recurly.HTTPDoer
+
+	mu    sync.Mutex
+	rand  *rand.Rand
+	rules []*FaultRule
+}
+
+// NewFaultDoer returns a FaultDoer that sends requests with doer, choosing
+// faults with seed.
+func NewFaultDoer(doer recurly.HTTPDoer, seed int64) *FaultDoer {
+	return &FaultDoer{
+		doer: doer,
+		rand: rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Inject returns a rule that injects f into every request. Rules are
+// checked in the order they are added, and at most one fault is injected
+// per request. Rules must be configured before requests are sent.
+func (d *FaultDoer) Inject(f Fault) *FaultRule {
+	r := &FaultRule{d: d, fault: f, probability: 1, times: -1}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.rules = append(d.rules, r)
+	return r
+}
+
+// Do sends req, injecting the fault of the first rule that applies.
+func (d *FaultDoer) Do(req *http.Request) (*http.Response, error) {
+	d.mu.Lock()
+	var fault Fault
+	for _, r := range d.rules {
+		if r.match(req) && (r.probability >= 1 || d.rand.Float64() < r.probability) {
+			r.injected++
+			fault = r.fault
+			break
+		}
+	}
+	d.mu.Unlock()
+
+	if fault == nil {
+		return d.doer.Do(req)
+	}
+	return fault(req, d.doer)
+}
+
+// FaultRule decides which requests a fault is injected into.
+type FaultRule struct {
+	d           *FaultDoer
+	fault       Fault
+	method      string
+	pattern     string
+	probability float64
+	times       int
+	injected    int
+}
+
+// On limits the rule to requests with method whose path matches pattern, as
+// in path.Match. An empty method or pattern matches all requests.
+func (r *FaultRule) On(method, pattern string) *FaultRule {
+	r.method, r.pattern = method, pattern
+	return r
+}
+
+// Probability sets the probability, from 0 to 1, of injecting the fault into
+// a matching request. Defaults to 1.
+func (r *FaultRule) Probability(p float64) *FaultRule {
+	r.probability = p
+	return r
+}
+
+// Times limits the rule to injecting the fault n times.
+func (r *FaultRule) Times(n int) *FaultRule {
+	r.times = n
+	return r
+}
+
+// Injected returns the number of times the fault has been injected.
+func (r *FaultRule) Injected() int {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	return r.injected
+}
+
+// match returns true if the fault may be injected into req.
+func (r *FaultRule) match(req *http.Request) bool {
+	if r.times >= 0 && r.injected >= r.times {
+		return false
+	} else if r.method != "" && r.method != req.Method {
+		return false
+	} else if r.pattern != "" {
+		if ok, _ := path.Match(r.pattern, req.URL.Path); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Latency delays requests by d before sending them.
+func Latency(d time.Duration) Fault {
+	return func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		if err := sleep(req.Context(), d); err != nil {
+			return nil, err
+		}
+		return next.Do(req)
+	}
+}
+
+// ConnectionReset fails requests as if the connection was reset by the
+// server. The error wraps syscall.ECONNRESET.
+func ConnectionReset() Fault {
+	return func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		return nil, urlError(req, &net.OpError{
+			Op:  "read",
+			Net: "tcp",
+			Err: os.NewSyscallError("read", syscall.ECONNRESET),
+		})
+	}
+}
+
+// Timeout fails requests with a net.Error that reports a timeout after d, as
+// an *http.Client does when its Timeout is exceeded.
+func Timeout(d time.Duration) Fault {
+	return func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		if err := sleep(req.Context(), d); err != nil {
+			return nil, err
+		}
+		return nil, urlError(req, timeoutError{})
+	}
+}
+
+// RateLimited responds with a 429 and rate limit headers with no requests
+// remaining until reset.
+func RateLimited(reset time.Time) Fault {
+	return func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("X-RateLimit-Limit", "2000")
+		header.Set("X-RateLimit-Remaining", "0")
+		header.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+		header.Set("Content-Length", "0")
+		return newResponse(req, http.StatusTooManyRequests, header, ""), nil
+	}
+}
+
+// ServerError responds with status, which should be a 5xx status code.
+func ServerError(status int) Fault {
+	return func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		return newResponse(req, status, header, http.StatusText(status)), nil
+	}
+}
+
+// TruncatedBody sends requests and cuts the response body in half, so that
+// XML responses cannot be decoded.
+func TruncatedBody() Fault {
+	return func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		resp, err := next.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		b, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		b = b[:len(b)/2]
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = int64(len(b))
+		resp.Body = ioutil.NopCloser(strings.NewReader(string(b)))
+		return resp, nil
+	}
+}
+
+//go:generate go run gen_fixtures.go
+
+// TransactionFailed responds with a 422 and the errors of the transaction
+// declined by the gateway in testdata/errors_transaction_failed.xml of the
+// recurly package, so that the client returns a
+// *recurly.TransactionFailedError.
+func TransactionFailed() Fault {
+	return func(req *http.Request, next recurly.HTTPDoer) (*http.Response, error) {
+		header := http.Header{}
+		header.Set("Content-Type", "application/xml; charset=utf-8")
+		return newResponse(req, http.StatusUnprocessableEntity, header, transactionFailedXML), nil
+	}
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// urlError returns err as returned by an *http.Client for req.
+func urlError(req *http.Request, err error) error {
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	op := method[:1] + strings.ToLower(method[1:])
+	return &url.Error{Op: op, URL: req.URL.String(), Err: err}
+}
+
+// timeoutError is the error of requests that time out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "recurlytest: request timed out" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,169 @@
+package recurlytest_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/recurlytest"
+)
+
+func TestFaultDoer(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	MustCreateAccount(t, s.Client(), "1")
+
+	newClient := func(f recurlytest.Fault) (*recurly.Client, *recurlytest.FaultRule) {
+		faults := recurlytest.NewFaultDoer(s.Client().Client, 1)
+		rule := faults.Inject(f).On("GET", "/v2/accounts/*")
+		client := s.Client()
+		client.Client = faults
+		return client, rule
+	}
+
+	t.Run("Latency", func(t *testing.T) {
+		client, _ := newClient(recurlytest.Latency(time.Hour))
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		if _, err := client.Accounts.Get(ctx, "1"); err != context.DeadlineExceeded {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ConnectionReset", func(t *testing.T) {
+		client, _ := newClient(recurlytest.ConnectionReset())
+		if _, err := client.Accounts.Get(ctx, "1"); !errors.Is(err, syscall.ECONNRESET) {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("EmptyMethod", func(t *testing.T) {
+		// An empty method means GET.
+		req, _ := http.NewRequest("GET", "https://recurlytest/v2/accounts/1", nil)
+		req.Method = ""
+		if _, err := recurlytest.ConnectionReset()(req, nil); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*url.Error); !ok || e.Op != "Get" {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		client, _ := newClient(recurlytest.Timeout(0))
+		if _, err := client.Accounts.Get(ctx, "1"); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(net.Error); !ok || !e.Timeout() {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("RateLimited", func(t *testing.T) {
+		reset := Now.Add(time.Minute)
+		client, _ := newClient(recurlytest.RateLimited(reset))
+		if _, err := client.Accounts.Get(ctx, "1"); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.RateLimitError); !ok {
+			t.Fatalf("unexpected error: %v", err)
+		} else if e.Rate.Remaining != 0 || !e.Rate.Reset.Equal(reset) {
+			t.Fatalf("unexpected rate: %#v", e.Rate)
+		}
+	})
+
+	t.Run("ServerError", func(t *testing.T) {
+		client, _ := newClient(recurlytest.ServerError(http.StatusServiceUnavailable))
+		if _, err := client.Accounts.Get(ctx, "1"); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.ServerError); !ok || e.Response.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("TruncatedBody", func(t *testing.T) {
+		client, _ := newClient(recurlytest.TruncatedBody())
+		if _, err := client.Accounts.Get(ctx, "1"); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+
+	t.Run("TransactionFailed", func(t *testing.T) {
+		faults := recurlytest.NewFaultDoer(s.Client().Client, 1)
+		faults.Inject(recurlytest.TransactionFailed()).On("POST", "/v2/accounts/*/billing_info")
+		client := s.Client()
+		client.Client = faults
+
+		if _, err := client.Billing.Create(ctx, "1", TestCard); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.TransactionFailedError); !ok {
+			t.Fatalf("unexpected error: %v", err)
+		} else if e.TransactionError.ErrorCode != "fraud_security_code" {
+			t.Fatalf("unexpected transaction error: %#v", e.TransactionError)
+		} else if e.Transaction == nil || e.Transaction.UUID != "3d1c6aa86e3d447eb0f3b4a6e3e074d9" {
+			t.Fatalf("unexpected transaction: %#v", e.Transaction)
+		}
+
+		// Ensure the response is the fixture of the recurly package. Run go
+		// generate if the fixture has changed.
+		req, _ := http.NewRequest("POST", "https://recurlytest/v2/accounts/1/billing_info", nil)
+		resp, _ := recurlytest.TransactionFailed()(req, nil)
+		if b, err := ioutil.ReadAll(resp.Body); err != nil {
+			t.Fatal(err)
+		} else if fixture, err := ioutil.ReadFile("../testdata/errors_transaction_failed.xml"); err != nil {
+			t.Fatal(err)
+		} else if !bytes.Equal(b, fixture) {
+			t.Fatalf("unexpected body: %s", b)
+		}
+	})
+
+	t.Run("Rules", func(t *testing.T) {
+		client, rule := newClient(recurlytest.ServerError(http.StatusInternalServerError))
+		rule.Times(1)
+
+		if _, err := client.Accounts.Get(ctx, "1"); err == nil {
+			t.Fatal("expected error")
+		} else if _, err := client.Accounts.Get(ctx, "1"); err != nil {
+			t.Fatal(err)
+		} else if _, err := client.Accounts.Update(ctx, "1", recurly.Account{FirstName: "Verena"}); err != nil {
+			t.Fatal(err)
+		} else if n := rule.Injected(); n != 1 {
+			t.Fatalf("unexpected injected: %d", n)
+		}
+	})
+
+	t.Run("Probability", func(t *testing.T) {
+		failures := func(seed int64) []bool {
+			faults := recurlytest.NewFaultDoer(s.Client().Client, seed)
+			faults.Inject(recurlytest.ServerError(http.StatusBadGateway)).Probability(0.5)
+			client := s.Client()
+			client.Client = faults
+
+			v := make([]bool, 20)
+			for i := range v {
+				_, err := client.Accounts.Get(ctx, "1")
+				v[i] = err != nil
+			}
+			return v
+		}
+
+		a, b := failures(1), failures(1)
+		var n int
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("expected the same faults for the same seed: %v, %v", a, b)
+			} else if a[i] {
+				n++
+			}
+		}
+		if n == 0 || n == len(a) {
+			t.Fatalf("unexpected faults: %v", a)
+		}
+	})
+}
@@ -0,0 +1,45 @@
+//go:build ignore
+// +build ignore
+
+// gen_fixtures writes fixtures.go with the recurly package fixtures used by
+// faults. Run with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"path/filepath"
+)
+
+var fixtures = []struct {
+	name string
+	file string
+}{
+	{"transactionFailedXML", "errors_transaction_failed.xml"},
+}
+
+func main() {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by gen_fixtures.go; DO NOT EDIT.\n\n")
+	buf.WriteString("package recurlytest\n\n")
+	for _, f := range fixtures {
+		b, err := ioutil.ReadFile(filepath.Join("..", "testdata", f.file))
+		if err != nil {
+			log.Fatal(err)
+		} else if bytes.Contains(b, []byte("`")) {
+			log.Fatalf("%s: cannot contain backquotes", f.file)
+		}
+		fmt.Fprintf(&buf, "// %s is testdata/%s of the recurly package.\n", f.name, f.file)
+		fmt.Fprintf(&buf, "const %s = `%s`\n\n", f.name, b)
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	} else if err := ioutil.WriteFile("fixtures.go", src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
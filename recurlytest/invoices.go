@@ -0,0 +1,554 @@
+package recurlytest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// TransactionStatusDeclined is the status of transactions declined by the
+// gateway.
+const TransactionStatusDeclined = "declined"
+
+// currencies are the currencies supported by the server.
+var currencies = []string{"USD", "EUR", "GBP", "CAD", "AUD"}
+
+func validCurrency(currency string) bool {
+	for _, c := range currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) findAdjustment(uuid string) *recurly.Adjustment {
+	for _, a := range s.adjustments {
+		if a.UUID == uuid {
+			return a
+		}
+	}
+	return nil
+}
+
+// lookupAdjustment returns the adjustment in the uuid parameter, or writes a
+// 404 and returns nil.
+func (s *Server) lookupAdjustment(c *call) *recurly.Adjustment {
+	uuid := c.param("uuid")
+	a := s.findAdjustment(uuid)
+	if a == nil {
+		c.notFound("Adjustment", "uuid", uuid)
+	}
+	return a
+}
+
+func (s *Server) listAccountAdjustments(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	typ := c.r.URL.Query().Get("type")
+	c.writeList("adjustments", filter(len(s.adjustments), func(i int) (interface{}, bool) {
+		adj := s.adjustments[i]
+		if adj.AccountCode != a.Code || !c.stateMatches(adj.State) {
+			return nil, false
+		} else if typ == "charge" && adj.UnitAmountInCents.Int() < 0 {
+			return nil, false
+		} else if typ == "credit" && adj.UnitAmountInCents.Int() >= 0 {
+			return nil, false
+		}
+		return c.adjustment(adj), true
+	}))
+}
+
+func (s *Server) getAdjustment(c *call) {
+	if a := s.lookupAdjustment(c); a != nil {
+		c.write(http.StatusOK, c.adjustment(a))
+	}
+}
+
+func (s *Server) createAdjustment(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	var v recurly.Adjustment
+	if !c.decode(&v) {
+		return
+	}
+
+	var errs validationErrors
+	if !hasInt(v.UnitAmountInCents) {
+		errs.add("adjustment.unit_amount_in_cents", "blank", "can't be blank")
+	}
+	if v.Currency == "" {
+		errs.add("adjustment.currency", "blank", "can't be blank")
+	} else if !validCurrency(v.Currency) {
+		errs.add("adjustment.currency", "invalid", "is not a valid currency")
+	}
+	if a.State == "closed" {
+		errs.add("adjustment.account", "invalid", "is closed")
+	}
+	if errs.write(c) {
+		return
+	}
+
+	origin := "debit"
+	if v.UnitAmountInCents.Int() < 0 {
+		origin = "credit"
+	}
+	adj := s.newAdjustment(a.Code, v.Currency, v.Description, origin, v.UnitAmountInCents.Int(), v.Quantity)
+	adj.AccountingCode = v.AccountingCode
+	adj.ProductCode = v.ProductCode
+	adj.TaxCode = v.TaxCode
+	adj.TaxExempt = v.TaxExempt
+	adj.RevenueScheduleType = v.RevenueScheduleType
+	if hasTime(v.StartDate) {
+		adj.StartDate = v.StartDate
+	}
+	adj.EndDate = v.EndDate
+
+	s.adjustments = append(s.adjustments, adj)
+	c.write(http.StatusCreated, c.adjustment(adj))
+}
+
+// newAdjustment returns a pending adjustment. It is not stored.
+func (s *Server) newAdjustment(accountCode, currency, description, origin string, unitAmount, quantity int) *recurly.Adjustment {
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	now := recurly.NewTime(s.now())
+	return &recurly.Adjustment{
+		XMLName:           xml.Name{Local: "adjustment"},
+		AccountCode:       accountCode,
+		UUID:              newUUID(),
+		State:             recurly.AdjustmentStatePending,
+		Description:       description,
+		Origin:            origin,
+		UnitAmountInCents: recurly.NewInt(unitAmount),
+		Quantity:          quantity,
+		TotalInCents:      unitAmount * quantity,
+		Currency:          currency,
+		StartDate:         now,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// deleteAdjustment deletes an adjustment. Only pending adjustments can be
+// deleted.
+func (s *Server) deleteAdjustment(c *call) {
+	a := s.lookupAdjustment(c)
+	if a == nil {
+		return
+	} else if a.State != recurly.AdjustmentStatePending {
+		c.invalidTransition("Only pending adjustments can be deleted.")
+		return
+	}
+
+	for i := range s.adjustments {
+		if s.adjustments[i] == a {
+			s.adjustments = append(s.adjustments[:i], s.adjustments[i+1:]...)
+			break
+		}
+	}
+	c.w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) findInvoice(number int) *recurly.Invoice {
+	for _, inv := range s.invoices {
+		if inv.InvoiceNumber == number {
+			return inv
+		}
+	}
+	return nil
+}
+
+// lookupInvoice returns the invoice in the invoice_number parameter, or
+// writes a 404 and returns nil.
+func (s *Server) lookupInvoice(c *call) *recurly.Invoice {
+	number := c.intParam("invoice_number")
+	inv := s.findInvoice(number)
+	if inv == nil {
+		c.notFound("Invoice", "invoice_number", c.params["invoice_number"])
+	}
+	return inv
+}
+
+// renderInvoice renders inv with its line items and transactions in an
+// element named name.
+func (s *Server) renderInvoice(c *call, name string, inv *recurly.Invoice) invoiceXML {
+	v := *inv
+	v.LineItems, v.Transactions = nil, nil
+	for _, adj := range s.adjustments {
+		if adj.InvoiceNumber == inv.InvoiceNumber {
+			v.LineItems = append(v.LineItems, *adj)
+		}
+	}
+	for _, t := range s.transactions {
+		if t.InvoiceNumber == inv.InvoiceNumber {
+			v.Transactions = append(v.Transactions, *t)
+		}
+	}
+	return c.invoice(name, &v)
+}
+
+func (s *Server) listInvoices(c *call) {
+	c.writeList("invoices", filter(len(s.invoices), func(i int) (interface{}, bool) {
+		return s.renderInvoice(c, "invoice", s.invoices[i]), c.stateMatches(s.invoices[i].State)
+	}))
+}
+
+func (s *Server) listAccountInvoices(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	c.writeList("invoices", filter(len(s.invoices), func(i int) (interface{}, bool) {
+		inv := s.invoices[i]
+		return s.renderInvoice(c, "invoice", inv), inv.AccountCode == a.Code && c.stateMatches(inv.State)
+	}))
+}
+
+func (s *Server) getInvoice(c *call) {
+	if inv := s.lookupInvoice(c); inv != nil {
+		c.write(http.StatusOK, s.renderInvoice(c, "invoice", inv))
+	}
+}
+
+// createInvoice invoices the pending adjustments of an account. Invoices
+// collected automatically are charged immediately. If the charge is
+// declined, the invoice is kept as past due.
+func (s *Server) createInvoice(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	var v recurly.Invoice
+	if !c.decode(&v) {
+		return
+	}
+
+	var adjs []*recurly.Adjustment
+	for _, adj := range s.adjustments {
+		if adj.AccountCode == a.Code && adj.State == recurly.AdjustmentStatePending {
+			adjs = append(adjs, adj)
+		}
+	}
+
+	method := v.CollectionMethod
+	if method == "" {
+		method = recurly.CollectionMethodAutomatic
+	}
+
+	var errs validationErrors
+	if len(adjs) == 0 {
+		errs.add("invoice.base", "will_not_invoice", "No charges to invoice")
+	}
+	if method != recurly.CollectionMethodAutomatic && method != recurly.CollectionMethodManual {
+		errs.add("invoice.collection_method", "invalid", "is not included in the list")
+	} else if method == recurly.CollectionMethodAutomatic && a.billing == nil {
+		errs.add("invoice.base", "billing_info_required", "Billing info is required for automatic collection")
+	}
+	if errs.write(c) {
+		return
+	}
+
+	reds := s.activeRedemptions(a.Code, "")
+	discounts := s.discount(adjs, reds)
+	inv := s.newInvoice(a, adjs, "debit", method, v.NetTerms)
+	inv.PONumber = v.PONumber
+	s.commitInvoice(inv, adjs)
+	s.commitDiscounts(reds, discounts)
+
+	if method == recurly.CollectionMethodAutomatic {
+		if t := s.collect(a, inv); t != nil && t.Status != recurly.TransactionStatusSuccess {
+			c.declined(t)
+			return
+		}
+	}
+	c.write(http.StatusCreated, c.invoiceCollectionOf(s.renderInvoice(c, "charge_invoice", inv)))
+}
+
+func (c *call) invoiceCollectionOf(inv invoiceXML) invoiceCollectionXML {
+	return invoiceCollectionXML{ChargeInvoice: inv}
+}
+
+// collectInvoice charges the account of an unpaid invoice.
+func (s *Server) collectInvoice(c *call) {
+	inv := s.lookupInvoice(c)
+	if inv == nil {
+		return
+	} else if inv.State != recurly.ChargeInvoiceStatePending && inv.State != recurly.ChargeInvoiceStatePastDue {
+		c.invalidTransition("Only pending or past due invoices can be collected.")
+		return
+	}
+
+	a := s.findAccount(inv.AccountCode)
+	if a.billing == nil {
+		validationErrors{{Field: "invoice.base", Symbol: "billing_info_required", Description: "Billing info is required to collect an invoice"}}.write(c)
+		return
+	}
+	if t := s.collect(a, inv); t != nil && t.Status != recurly.TransactionStatusSuccess {
+		c.declined(t)
+		return
+	}
+	c.write(http.StatusOK, s.renderInvoice(c, "invoice", inv))
+}
+
+func (s *Server) markInvoicePaid(c *call) {
+	inv := s.lookupInvoice(c)
+	if inv == nil {
+		return
+	} else if inv.State != recurly.ChargeInvoiceStatePending && inv.State != recurly.ChargeInvoiceStatePastDue {
+		c.invalidTransition("Only pending or past due invoices can be marked successful.")
+		return
+	}
+	s.closeInvoice(inv, recurly.ChargeInvoiceStatePaid)
+	c.write(http.StatusOK, s.renderInvoice(c, "invoice", inv))
+}
+
+func (s *Server) markInvoiceFailed(c *call) {
+	inv := s.lookupInvoice(c)
+	if inv == nil {
+		return
+	} else if inv.State != recurly.ChargeInvoiceStatePending && inv.State != recurly.ChargeInvoiceStatePastDue {
+		c.invalidTransition("Only pending or past due invoices can be marked failed.")
+		return
+	}
+	s.closeInvoice(inv, recurly.ChargeInvoiceStateFailed)
+	c.write(http.StatusOK, c.invoiceCollectionOf(s.renderInvoice(c, "charge_invoice", inv)))
+}
+
+// newInvoice returns an invoice for adjs. It is not stored and has no
+// invoice number until it is committed.
+func (s *Server) newInvoice(a *account, adjs []*recurly.Adjustment, origin, method string, netTerms recurly.NullInt) *recurly.Invoice {
+	now := s.now()
+	inv := &recurly.Invoice{
+		XMLName:          xml.Name{Local: "invoice"},
+		AccountCode:      a.Code,
+		UUID:             newUUID(),
+		State:            recurly.ChargeInvoiceStatePending,
+		VATNumber:        a.VATNumber,
+		CreatedAt:        recurly.NewTime(now),
+		UpdatedAt:        recurly.NewTime(now),
+		Type:             recurly.InvoiceTypeCharge,
+		Origin:           origin,
+		NetTerms:         netTerms,
+		CollectionMethod: method,
+		DueOn:            recurly.NewTime(now.AddDate(0, 0, netTerms.Int())),
+	}
+	if a.Address != nil {
+		inv.Address = *a.Address
+	}
+	for _, adj := range adjs {
+		inv.Currency = adj.Currency
+		inv.SubtotalInCents += adj.UnitAmountInCents.Int() * adj.Quantity
+		inv.DiscountInCents += adj.DiscountInCents
+		inv.TotalInCents += adj.TotalInCents
+	}
+	inv.BalanceInCents = inv.TotalInCents
+	if inv.TotalInCents <= 0 {
+		inv.State, inv.BalanceInCents = recurly.ChargeInvoiceStatePaid, 0
+		inv.ClosedAt = inv.CreatedAt
+	}
+	return inv
+}
+
+// commitInvoice numbers and stores inv, and marks adjs as invoiced.
+func (s *Server) commitInvoice(inv *recurly.Invoice, adjs []*recurly.Adjustment) {
+	s.invoiceNumber++
+	inv.InvoiceNumber = s.invoiceNumber
+	for _, adj := range adjs {
+		if s.findAdjustment(adj.UUID) == nil {
+			s.adjustments = append(s.adjustments, adj)
+		}
+		adj.State = recurly.AdjustmentStateInvoied
+		adj.InvoiceNumber = inv.InvoiceNumber
+		adj.UpdatedAt = inv.CreatedAt
+	}
+	s.invoices = append(s.invoices, inv)
+}
+
+// collect charges the account for the balance of inv. A successful charge
+// closes the invoice and a declined charge marks it past due. It returns
+// nil if nothing was charged.
+func (s *Server) collect(a *account, inv *recurly.Invoice) *recurly.Transaction {
+	if inv.BalanceInCents <= 0 {
+		return nil
+	}
+
+	t := s.charge(a, inv.BalanceInCents, inv.Currency)
+	t.InvoiceNumber = inv.InvoiceNumber
+	if t.Status == recurly.TransactionStatusSuccess {
+		s.closeInvoice(inv, recurly.ChargeInvoiceStatePaid)
+	} else {
+		inv.State = recurly.ChargeInvoiceStatePastDue
+		inv.UpdatedAt = t.CreatedAt
+		inv.AttemptNextCollectionAt = recurly.NewTime(s.now().Add(24 * time.Hour))
+	}
+	return t
+}
+
+func (s *Server) closeInvoice(inv *recurly.Invoice, state string) {
+	now := recurly.NewTime(s.now())
+	inv.State, inv.ClosedAt, inv.UpdatedAt = state, now, now
+	inv.AttemptNextCollectionAt = recurly.NullTime{}
+	if state == recurly.ChargeInvoiceStatePaid {
+		inv.BalanceInCents = 0
+	}
+}
+
+func (s *Server) findTransaction(uuid string) *recurly.Transaction {
+	for _, t := range s.transactions {
+		if t.UUID == uuid {
+			return t
+		}
+	}
+	return nil
+}
+
+// transactionMatches returns true if t matches the state and type query
+// parameters of c.
+func (c *call) transactionMatches(t *recurly.Transaction) bool {
+	q := c.r.URL.Query()
+	switch q.Get("state") {
+	case "successful":
+		if t.Status != recurly.TransactionStatusSuccess {
+			return false
+		}
+	case "failed":
+		if t.Status != TransactionStatusDeclined && t.Status != recurly.TransactionStatusFailed {
+			return false
+		}
+	case "voided":
+		if t.Status != recurly.TransactionStatusVoid {
+			return false
+		}
+	}
+	if typ := q.Get("type"); typ != "" && typ != t.Action {
+		return false
+	}
+	return true
+}
+
+func (s *Server) listTransactions(c *call) {
+	c.writeList("transactions", filter(len(s.transactions), func(i int) (interface{}, bool) {
+		t := s.transactions[i]
+		return c.transaction(t), c.transactionMatches(t)
+	}))
+}
+
+func (s *Server) listAccountTransactions(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	c.writeList("transactions", filter(len(s.transactions), func(i int) (interface{}, bool) {
+		t := s.transactions[i]
+		return c.transaction(t), t.Account.Code == a.Code && c.transactionMatches(t)
+	}))
+}
+
+func (s *Server) getTransaction(c *call) {
+	uuid := c.param("uuid")
+	if t := s.findTransaction(uuid); t == nil {
+		c.notFound("Transaction", "uuid", uuid)
+	} else {
+		c.write(http.StatusOK, c.transaction(t))
+	}
+}
+
+// newTransaction stores and returns a successful transaction for a.
+func (s *Server) newTransaction(a *account, action string, amount int, currency string) *recurly.Transaction {
+	if currency == "" {
+		currency = "USD"
+	}
+
+	details := recurly.Account{
+		XMLName:     xml.Name{Local: "account"},
+		Code:        a.Code,
+		Email:       a.Email,
+		FirstName:   a.FirstName,
+		LastName:    a.LastName,
+		CompanyName: a.CompanyName,
+	}
+	method := recurly.PaymentMethodCreditCard
+	if a.billing != nil {
+		details.BillingInfo = billing(a.billing)
+		if a.billing.PaymentType != "credit_card" {
+			method = a.billing.PaymentType
+		}
+	}
+
+	t := &recurly.Transaction{
+		UUID:          newUUID(),
+		Action:        action,
+		AmountInCents: amount,
+		Currency:      currency,
+		Status:        recurly.TransactionStatusSuccess,
+		PaymentMethod: method,
+		Reference:     newUUID()[:12],
+		Source:        "transaction",
+		Test:          true,
+		Voidable:      recurly.NewBool(action == "purchase"),
+		Refundable:    recurly.NewBool(action == "purchase"),
+		CreatedAt:     recurly.NewTime(s.now()),
+		Account:       details,
+		GatewayType:   "test",
+		Message:       "Successful test transaction",
+	}
+	s.transactions = append(s.transactions, t)
+	return t
+}
+
+// charge charges a with the fake gateway and returns the stored purchase
+// transaction. Charges are declined if a has no billing info.
+func (s *Server) charge(a *account, amount int, currency string) *recurly.Transaction {
+	t := s.newTransaction(a, "purchase", amount, currency)
+	if a.billing == nil || a.billing.Number == CardDeclinedOnCharge {
+		s.decline(t)
+	}
+	return t
+}
+
+// decline marks t as declined by the gateway.
+func (s *Server) decline(t *recurly.Transaction) {
+	t.Status = TransactionStatusDeclined
+	t.Voidable, t.Refundable = recurly.NewBool(false), recurly.NewBool(false)
+	t.Message = "The transaction was declined."
+	t.TransactionError = &recurly.TransactionError{
+		ErrorCode:        "declined",
+		ErrorCategory:    "soft",
+		MerchantMessage:  "The customer's bank has declined their card.",
+		CustomerMessage:  "The transaction was declined. Please use a different card or contact your bank.",
+		GatewayErrorCode: "2",
+	}
+}
+
+// declined writes a 422 for a declined transaction.
+func (c *call) declined(t *recurly.Transaction) {
+	tx := c.transaction(t)
+	c.write(http.StatusUnprocessableEntity, struct {
+		XMLName          xml.Name                  `xml:"errors"`
+		TransactionError *recurly.TransactionError `xml:"transaction_error"`
+		Error            fieldError                `xml:"error"`
+		Transaction      *transactionXML           `xml:"transaction"`
+	}{
+		TransactionError: t.TransactionError,
+		Error: fieldError{
+			Field:       "transaction.account.base",
+			Symbol:      "declined",
+			Description: t.TransactionError.CustomerMessage,
+		},
+		Transaction: &tx,
+	})
+}
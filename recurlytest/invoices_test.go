@@ -0,0 +1,141 @@
+package recurlytest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/recurlytest"
+)
+
+func TestServer_Invoices(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+	MustCreateAccount(t, client, "1")
+
+	if _, err := client.Coupons.Create(ctx, recurly.Coupon{
+		Code:                    "half",
+		Name:                    "Half off",
+		DiscountType:            "percent",
+		DiscountPercent:         recurly.NewInt(50),
+		Duration:                "single_use",
+		AppliesToNonPlanCharges: true,
+	}); err != nil {
+		t.Fatal(err)
+	} else if r, err := client.Redemptions.Redeem(ctx, "half", recurly.CouponRedemption{AccountCode: "1", Currency: "USD"}); err != nil {
+		t.Fatal(err)
+	} else if r.AccountCode != "1" || r.State != "active" || !r.SingleUse {
+		t.Fatalf("unexpected redemption: %#v", r)
+	}
+
+	// Ensure invoices require pending adjustments.
+	if _, err := client.Invoices.Create(ctx, "1", recurly.Invoice{CollectionMethod: recurly.CollectionMethodManual}); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("will_not_invoice") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	adj, err := client.Adjustments.Create(ctx, "1", recurly.Adjustment{
+		Description:       "Setup",
+		UnitAmountInCents: recurly.NewInt(2000),
+		Quantity:          2,
+		Currency:          "USD",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if adj.State != recurly.AdjustmentStatePending || adj.TotalInCents != 4000 || adj.AccountCode != "1" {
+		t.Fatalf("unexpected adjustment: %#v", adj)
+	}
+
+	inv, err := client.Invoices.Create(ctx, "1", recurly.Invoice{CollectionMethod: recurly.CollectionMethodManual})
+	if err != nil {
+		t.Fatal(err)
+	} else if inv.State != recurly.ChargeInvoiceStatePending || inv.SubtotalInCents != 4000 || inv.DiscountInCents != 2000 || inv.TotalInCents != 2000 {
+		t.Fatalf("unexpected invoice: %#v", inv)
+	}
+
+	// Ensure invoiced adjustments cannot be deleted and single use coupons
+	// are used up.
+	if err := client.Adjustments.Delete(ctx, adj.UUID); err == nil {
+		t.Fatal("expected error")
+	}
+	var redemptions []recurly.Redemption
+	if err := client.Redemptions.ListAccount("1", nil).FetchAll(ctx, &redemptions); err != nil {
+		t.Fatal(err)
+	} else if len(redemptions) != 1 || redemptions[0].State != "inactive" || redemptions[0].TotalDiscountedInCents != 2000 {
+		t.Fatalf("unexpected redemptions: %#v", redemptions)
+	}
+
+	// Ensure declined collections leave the invoice past due.
+	billing := TestCard
+	billing.Number = recurlytest.CardDeclinedOnCharge
+	if _, err := client.Billing.Create(ctx, "1", billing); err != nil {
+		t.Fatal(err)
+	} else if _, err := client.Invoices.Collect(ctx, inv.InvoiceNumber, recurly.CollectInvoice{}); err == nil {
+		t.Fatal("expected error")
+	} else if _, ok := err.(*recurly.TransactionFailedError); !ok {
+		t.Fatalf("unexpected error: %v", err)
+	} else if inv, err := client.Invoices.Get(ctx, inv.InvoiceNumber); err != nil {
+		t.Fatal(err)
+	} else if inv.State != recurly.ChargeInvoiceStatePastDue || len(inv.Transactions) != 1 {
+		t.Fatalf("unexpected invoice: %#v", inv)
+	}
+
+	if _, err := client.Billing.Update(ctx, "1", TestCard); err != nil {
+		t.Fatal(err)
+	} else if inv, err := client.Invoices.Collect(ctx, inv.InvoiceNumber, recurly.CollectInvoice{}); err != nil {
+		t.Fatal(err)
+	} else if inv.State != recurly.ChargeInvoiceStatePaid || inv.BalanceInCents != 0 || len(inv.Transactions) != 2 {
+		t.Fatalf("unexpected invoice: %#v", inv)
+	}
+
+	var invoices []recurly.Invoice
+	if err := client.Invoices.ListAccount("1", &recurly.PagerOptions{State: recurly.ChargeInvoiceStatePaid}).FetchAll(ctx, &invoices); err != nil {
+		t.Fatal(err)
+	} else if len(invoices) != 1 || invoices[0].InvoiceNumber != inv.InvoiceNumber {
+		t.Fatalf("unexpected invoices: %#v", invoices)
+	}
+}
+
+func TestServer_Coupons(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+	MustCreateAccount(t, client, "1")
+	MustCreateAccount(t, client, "2")
+
+	if _, err := client.Coupons.Create(ctx, recurly.Coupon{Code: "bad", Name: "Bad", DiscountType: "percent"}); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(*recurly.ClientError); !ok || e.ValidationErrors[0].Field != "coupon.discount_percent" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := client.Coupons.Create(ctx, recurly.Coupon{
+		Code:            "once",
+		Name:            "Once",
+		DiscountType:    "dollars",
+		DiscountInCents: &recurly.UnitAmount{USD: 500},
+		MaxRedemptions:  recurly.NewInt(1),
+	}); err != nil {
+		t.Fatal(err)
+	} else if _, err := client.Redemptions.Redeem(ctx, "once", recurly.CouponRedemption{AccountCode: "1", Currency: "USD"}); err != nil {
+		t.Fatal(err)
+	} else if c, err := client.Coupons.Get(ctx, "once"); err != nil {
+		t.Fatal(err)
+	} else if c.State != "maxed_out" {
+		t.Fatalf("unexpected coupon: %#v", c)
+	} else if _, err := client.Redemptions.Redeem(ctx, "once", recurly.CouponRedemption{AccountCode: "2", Currency: "USD"}); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("maxed_out") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := client.Coupons.Delete(ctx, "once"); err != nil {
+		t.Fatal(err)
+	} else if c, err := client.Coupons.Restore(ctx, "once", recurly.Coupon{Name: "Restored"}); err != nil {
+		t.Fatal(err)
+	} else if c.State != "redeemable" || c.Name != "Restored" {
+		t.Fatalf("unexpected coupon: %#v", c)
+	}
+}
@@ -0,0 +1,209 @@
+package recurlytest
+
+import (
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+// webhookAccount returns the account with code as sent in webhooks.
+func (s *Server) webhookAccount(code string) webhooks.Account {
+	a := s.findAccount(code)
+	if a == nil {
+		return webhooks.Account{Code: code}
+	}
+
+	v := webhooks.Account{
+		Code:        a.Code,
+		Username:    a.Username,
+		Email:       a.Email,
+		FirstName:   a.FirstName,
+		LastName:    a.LastName,
+		CompanyName: a.CompanyName,
+		VATNumber:   a.VATNumber,
+	}
+	if a.Address != nil {
+		v.Address = &webhooks.Address{
+			Address:  a.Address.Address,
+			Address2: a.Address.Address2,
+			City:     a.Address.City,
+			State:    a.Address.State,
+			Zip:      a.Address.Zip,
+			Country:  a.Address.Country,
+			Phone:    a.Address.Phone,
+		}
+	}
+	if b := a.billing; b != nil {
+		v.BillingInfo = &webhooks.BillingInfo{
+			FirstName:   b.FirstName,
+			LastName:    b.LastName,
+			Company:     b.Company,
+			PaymentType: b.PaymentType,
+			CardType:    b.CardType,
+			FirstSix:    b.FirstSix,
+			LastFour:    b.LastFour,
+			Month:       b.Month,
+			Year:        b.Year,
+		}
+	}
+	return v
+}
+
+// webhookSubscription returns sub as sent in webhooks.
+func webhookSubscription(sub *subscription) webhooks.Subscription {
+	v := webhooks.Subscription{
+		Plan:                   webhooks.Plan{Code: sub.Plan.Code, Name: sub.Plan.Name},
+		UUID:                   sub.UUID,
+		State:                  sub.State,
+		UnitAmountInCents:      sub.UnitAmountInCents,
+		Currency:               sub.Currency,
+		Quantity:               sub.Quantity,
+		TotalAmountInCents:     sub.TotalAmountInCents,
+		SubscriptionAddOns:     webhookAddOns(sub.SubscriptionAddOns),
+		ActivatedAt:            sub.ActivatedAt,
+		CanceledAt:             sub.CanceledAt,
+		ExpiresAt:              sub.ExpiresAt,
+		CurrentPeriodStartedAt: sub.CurrentPeriodStartedAt,
+		CurrentPeriodEndsAt:    sub.CurrentPeriodEndsAt,
+		TrialStartedAt:         sub.TrialStartedAt,
+		TrialEndsAt:            sub.TrialEndsAt,
+		PausedAt:               sub.PausedAt,
+		ResumeAt:               sub.ResumeAt,
+		RemainingPauseCycles:   sub.RemainingPauseCycles,
+		RemainingBillingCycles: sub.RemainingBillingCycles,
+		AutoRenew:              recurly.NewBool(sub.AutoRenew),
+		CollectionMethod:       sub.CollectionMethod,
+	}
+	if p := sub.PendingSubscription; p != nil {
+		v.PendingSubscription = &webhooks.PendingSubscription{
+			Plan:               webhooks.Plan{Code: p.Plan.Code, Name: p.Plan.Name},
+			UnitAmountInCents:  p.UnitAmountInCents,
+			Quantity:           p.Quantity,
+			SubscriptionAddOns: webhookAddOns(p.SubscriptionAddOns),
+		}
+	}
+	return v
+}
+
+func webhookAddOns(addOns []recurly.SubscriptionAddOn) []webhooks.SubscriptionAddOn {
+	if len(addOns) == 0 {
+		return nil
+	}
+
+	v := make([]webhooks.SubscriptionAddOn, len(addOns))
+	for i, ao := range addOns {
+		v[i] = webhooks.SubscriptionAddOn{
+			Code:              ao.Code,
+			AddOnType:         ao.Type,
+			Quantity:          ao.Quantity,
+			UnitAmountInCents: ao.UnitAmountInCents.Int(),
+		}
+	}
+	return v
+}
+
+// webhookInvoice returns inv as sent in webhooks.
+func (s *Server) webhookInvoice(inv *recurly.Invoice) webhooks.ChargeInvoice {
+	v := webhooks.ChargeInvoice{
+		UUID:                          inv.UUID,
+		State:                         inv.State,
+		Origin:                        inv.Origin,
+		InvoiceNumber:                 inv.InvoiceNumber,
+		PONumber:                      inv.PONumber,
+		VATNumber:                     inv.VATNumber,
+		BalanceInCents:                inv.BalanceInCents,
+		TotalInCents:                  inv.TotalInCents,
+		TaxInCents:                    inv.TaxInCents,
+		DiscountInCents:               inv.DiscountInCents,
+		SubtotalInCents:               inv.SubtotalInCents - inv.DiscountInCents,
+		SubTotalBeforeDiscountInCents: inv.SubtotalInCents,
+		Currency:                      inv.Currency,
+		CreatedAt:                     inv.CreatedAt,
+		UpdatedAt:                     inv.UpdatedAt,
+		ClosedAt:                      inv.ClosedAt,
+		DueOn:                         inv.DueOn,
+		NetTerms:                      inv.NetTerms,
+		CollectionMethod:              inv.CollectionMethod,
+	}
+	for _, adj := range s.adjustments {
+		if adj.InvoiceNumber != inv.InvoiceNumber || adj.SubscriptionUUID == "" {
+			continue
+		} else if n := len(v.SubscriptionUUIDs); n == 0 || v.SubscriptionUUIDs[n-1] != adj.SubscriptionUUID {
+			v.SubscriptionUUIDs = append(v.SubscriptionUUIDs, adj.SubscriptionUUID)
+		}
+	}
+	return v
+}
+
+// webhookTransaction returns t, a payment for inv, as sent in webhooks.
+func (s *Server) webhookTransaction(inv *recurly.Invoice, t *recurly.Transaction) webhooks.Transaction {
+	v := webhooks.Transaction{
+		UUID:          t.UUID,
+		InvoiceUUID:   inv.UUID,
+		InvoiceNumber: inv.InvoiceNumber,
+		Action:        t.Action,
+		PaymentMethod: t.PaymentMethod,
+		AmountInCents: t.AmountInCents,
+		Currency:      t.Currency,
+		Status:        t.Status,
+		Message:       t.Message,
+		Reference:     t.Reference,
+		Source:        t.Source,
+		Gateway:       t.GatewayType,
+		Test:          recurly.NewBool(t.Test),
+		Voidable:      t.Voidable,
+		Refundable:    t.Refundable,
+		CreatedAt:     t.CreatedAt,
+	}
+	if t.Status == recurly.TransactionStatusSuccess {
+		v.CollectedAt = t.CreatedAt
+	}
+	if e := t.TransactionError; e != nil {
+		v.GatewayErrorCodes = e.GatewayErrorCode
+		v.FailureType = webhooks.TransactionFailureTypeDeclined
+	}
+	if subs := s.webhookInvoice(inv).SubscriptionUUIDs; len(subs) > 0 {
+		v.SubscriptionUUID = subs[0]
+	}
+	return v
+}
+
+func (s *Server) subscriptionNotification(typ string, sub *subscription) *webhooks.SubscriptionNotification {
+	return &webhooks.SubscriptionNotification{
+		Type:         typ,
+		Account:      s.webhookAccount(sub.AccountCode),
+		Subscription: webhookSubscription(sub),
+	}
+}
+
+func (s *Server) invoiceNotification(typ string, inv *recurly.Invoice) *webhooks.ChargeInvoiceNotification {
+	return &webhooks.ChargeInvoiceNotification{
+		Type:    typ,
+		Account: s.webhookAccount(inv.AccountCode),
+		Invoice: s.webhookInvoice(inv),
+	}
+}
+
+func (s *Server) paymentNotification(typ string, inv *recurly.Invoice, t *recurly.Transaction) *webhooks.PaymentNotification {
+	return &webhooks.PaymentNotification{
+		Type:        typ,
+		Account:     s.webhookAccount(inv.AccountCode),
+		Transaction: s.webhookTransaction(inv, t),
+	}
+}
+
+// dunningNotification returns the notification sent when inv enters or
+// continues dunning.
+func (s *Server) dunningNotification(inv *recurly.Invoice) *webhooks.NewDunningEventNotification {
+	n := &webhooks.NewDunningEventNotification{
+		Type:    webhooks.NewDunningEvent,
+		Account: s.webhookAccount(inv.AccountCode),
+		Invoice: s.webhookInvoice(inv),
+	}
+	for _, sub := range s.subscriptions {
+		if sub.InvoiceNumber == inv.InvoiceNumber {
+			n.Subscription = webhookSubscription(sub)
+			break
+		}
+	}
+	return n
+}
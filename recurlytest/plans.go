@@ -0,0 +1,279 @@
+package recurlytest
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/blacklightcms/recurly"
+)
+
+func (s *Server) findPlan(code string) *recurly.Plan {
+	for _, p := range s.plans {
+		if p.Code == code {
+			return p
+		}
+	}
+	return nil
+}
+
+// lookupPlan returns the plan in the plan_code parameter, or writes a 404
+// and returns nil.
+func (s *Server) lookupPlan(c *call) *recurly.Plan {
+	code := c.param("plan_code")
+	p := s.findPlan(code)
+	if p == nil {
+		c.notFound("Plan", "plan_code", code)
+	}
+	return p
+}
+
+func (s *Server) listPlans(c *call) {
+	c.writeList("plans", filter(len(s.plans), func(i int) (interface{}, bool) {
+		return s.plans[i], true
+	}))
+}
+
+func (s *Server) getPlan(c *call) {
+	if p := s.lookupPlan(c); p != nil {
+		c.write(http.StatusOK, p)
+	}
+}
+
+func (s *Server) createPlan(c *call) {
+	var v recurly.Plan
+	if !c.decode(&v) {
+		return
+	}
+
+	var errs validationErrors
+	if v.Code == "" {
+		errs.add("plan.plan_code", "blank", "can't be blank")
+	} else if s.findPlan(v.Code) != nil {
+		errs.add("plan.plan_code", "taken", "has already been taken")
+	}
+	if v.Name == "" {
+		errs.add("plan.name", "blank", "can't be blank")
+	}
+	if v.IntervalUnit != "" && v.IntervalUnit != "days" && v.IntervalUnit != "months" {
+		errs.add("plan.plan_interval_unit", "invalid", "is not included in the list")
+	}
+	if v.TrialIntervalUnit != "" && v.TrialIntervalUnit != "days" && v.TrialIntervalUnit != "months" {
+		errs.add("plan.trial_interval_unit", "invalid", "is not included in the list")
+	}
+	if errs.write(c) {
+		return
+	}
+
+	if v.IntervalUnit == "" {
+		v.IntervalUnit = "months"
+	}
+	if v.IntervalLength == 0 {
+		v.IntervalLength = 1
+	}
+	if v.TrialIntervalUnit == "" {
+		v.TrialIntervalUnit = "days"
+	}
+	v.XMLName = xml.Name{Local: "plan"}
+	v.CreatedAt = recurly.NewTime(s.now())
+
+	s.plans = append(s.plans, &v)
+	c.write(http.StatusCreated, &v)
+}
+
+func (s *Server) updatePlan(c *call) {
+	p := s.lookupPlan(c)
+	if p == nil {
+		return
+	}
+
+	var v recurly.Plan
+	if !c.decode(&v) {
+		return
+	}
+	if v.Name != "" {
+		p.Name = v.Name
+	}
+	if v.Description != "" {
+		p.Description = v.Description
+	}
+	if v.AccountingCode != "" {
+		p.AccountingCode = v.AccountingCode
+	}
+	if v.UnitAmountInCents != (recurly.UnitAmount{}) {
+		p.UnitAmountInCents = v.UnitAmountInCents
+	}
+	if v.SetupFeeInCents != (recurly.UnitAmount{}) {
+		p.SetupFeeInCents = v.SetupFeeInCents
+	}
+	if v.TrialIntervalUnit != "" {
+		p.TrialIntervalUnit = v.TrialIntervalUnit
+	}
+	if v.TrialIntervalLength != 0 {
+		p.TrialIntervalLength = v.TrialIntervalLength
+	}
+	if hasInt(v.TotalBillingCycles) {
+		p.TotalBillingCycles = v.TotalBillingCycles
+	}
+	c.write(http.StatusOK, p)
+}
+
+// deletePlan deletes a plan and its add-ons. Existing subscriptions to the
+// plan are unaffected.
+func (s *Server) deletePlan(c *call) {
+	p := s.lookupPlan(c)
+	if p == nil {
+		return
+	}
+	for i := range s.plans {
+		if s.plans[i] == p {
+			s.plans = append(s.plans[:i], s.plans[i+1:]...)
+			break
+		}
+	}
+	delete(s.addOns, p.Code)
+	c.w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) findAddOn(planCode, code string) *recurly.AddOn {
+	for _, a := range s.addOns[planCode] {
+		if a.Code == code {
+			return a
+		}
+	}
+	return nil
+}
+
+// lookupAddOn returns the add-on in the add_on_code parameter, or writes a
+// 404 and returns nil.
+func (s *Server) lookupAddOn(c *call) *recurly.AddOn {
+	p := s.lookupPlan(c)
+	if p == nil {
+		return nil
+	}
+
+	code := c.param("add_on_code")
+	a := s.findAddOn(p.Code, code)
+	if a == nil {
+		c.notFound("AddOn", "add_on_code", code)
+	}
+	return a
+}
+
+func (s *Server) listAddOns(c *call) {
+	p := s.lookupPlan(c)
+	if p == nil {
+		return
+	}
+
+	addOns := s.addOns[p.Code]
+	c.writeList("add_ons", filter(len(addOns), func(i int) (interface{}, bool) {
+		return addOns[i], true
+	}))
+}
+
+func (s *Server) getAddOn(c *call) {
+	if a := s.lookupAddOn(c); a != nil {
+		c.write(http.StatusOK, a)
+	}
+}
+
+func (s *Server) createAddOn(c *call) {
+	p := s.lookupPlan(c)
+	if p == nil {
+		return
+	}
+
+	var v recurly.AddOn
+	if !c.decode(&v) {
+		return
+	}
+
+	var errs validationErrors
+	if v.Code == "" {
+		errs.add("add_on.add_on_code", "blank", "can't be blank")
+	} else if s.findAddOn(p.Code, v.Code) != nil {
+		errs.add("add_on.add_on_code", "taken", "has already been taken")
+	}
+	if v.Name == "" {
+		errs.add("add_on.name", "blank", "can't be blank")
+	}
+	if errs.write(c) {
+		return
+	}
+
+	if !hasInt(v.DefaultQuantity) {
+		v.DefaultQuantity = recurly.NewInt(1)
+	}
+	if v.AddOnType == "" {
+		v.AddOnType = "fixed"
+	}
+	v.XMLName = xml.Name{Local: "add_on"}
+	v.CreatedAt = recurly.NewTime(s.now())
+
+	s.addOns[p.Code] = append(s.addOns[p.Code], &v)
+	c.write(http.StatusCreated, &v)
+}
+
+func (s *Server) updateAddOn(c *call) {
+	a := s.lookupAddOn(c)
+	if a == nil {
+		return
+	}
+
+	var v recurly.AddOn
+	if !c.decode(&v) {
+		return
+	}
+	if v.Name != "" {
+		a.Name = v.Name
+	}
+	if v.AccountingCode != "" {
+		a.AccountingCode = v.AccountingCode
+	}
+	if hasInt(v.DefaultQuantity) {
+		a.DefaultQuantity = v.DefaultQuantity
+	}
+	if v.UnitAmountInCents != (recurly.UnitAmount{}) {
+		a.UnitAmountInCents = v.UnitAmountInCents
+	}
+	c.write(http.StatusOK, a)
+}
+
+func (s *Server) deleteAddOn(c *call) {
+	a := s.lookupAddOn(c)
+	if a == nil {
+		return
+	}
+
+	planCode := c.param("plan_code")
+	addOns := s.addOns[planCode]
+	for i := range addOns {
+		if addOns[i] == a {
+			s.addOns[planCode] = append(addOns[:i], addOns[i+1:]...)
+			break
+		}
+	}
+	c.w.WriteHeader(http.StatusNoContent)
+}
+
+// amount returns the amount of u in currency, and false if u has no amount
+// in currency. Zero amounts are free in every currency.
+func amount(u recurly.UnitAmount, currency string) (int, bool) {
+	if u == (recurly.UnitAmount{}) {
+		return 0, true
+	}
+
+	switch currency {
+	case "USD":
+		return u.USD, u.USD != 0
+	case "EUR":
+		return u.EUR, u.EUR != 0
+	case "GBP":
+		return u.GBP, u.GBP != 0
+	case "CAD":
+		return u.CAD, u.CAD != 0
+	case "AUD":
+		return u.AUD, u.AUD != 0
+	}
+	return 0, false
+}
@@ -0,0 +1,503 @@
+// Package recurlytest provides a stateful, in-memory fake of the Recurly v2
+// API for integration tests.
+//
+// Server implements the XML API for accounts, billing info, plans, add-ons,
+// subscriptions, adjustments, invoices, transactions, coupons and coupon
+// redemptions. Unlike mock and recurly.TestServer, requests change the state
+// of the server, so complete flows can be tested with a normal
+// *recurly.Client:
+//
+//	s := recurlytest.NewServer()
+//	client := s.Client()
+//
+//	client.Accounts.Create(ctx, recurly.Account{Code: "1"})
+//	client.Billing.Create(ctx, "1", recurly.Billing{Number: 4111111111111111, Month: 12, Year: 2030})
+//	client.Subscriptions.Create(ctx, recurly.NewSubscription{...})
+//
+// Server is an http.Handler, so it can also be served with httptest.
+//
+// Payments are processed by a fake gateway. The following card numbers
+// trigger failures; all other valid card numbers succeed:
+//
+//	4000000000000002  declined when billing info is created or updated
+//	4000000000000341  accepted as billing info, but declined when charged
+//
+// Endpoints that are not implemented respond with a 501.
+//
+// The clock of the server can be advanced to simulate renewals, trial
+// conversions, pauses, expirations and dunning. Each change is sent as a
+// webhook to WebhookURL or the Webhooks channel:
+//
+//	s.Webhooks = notifications
+//	s.Advance(31 * 24 * time.Hour) // renews monthly subscriptions
+//
+// Cassette records interactions with the Recurly sandbox to a file and
+// replays them without a network connection. FaultDoer injects latency,
+// connection resets, timeouts, rate limits, server errors, truncated bodies
+// and failed transactions into requests.
+package recurlytest
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// DefaultPerPage is the number of records returned per page when a request
+// does not specify per_page.
+const DefaultPerPage = 50
+
+// Server is a stateful fake of the Recurly v2 API. It is safe for
+// concurrent use.
+type Server struct {
+	// APIKey, if set, is required as the HTTP Basic Authentication username
+	// of every request. Requests with another key receive a 401.
+	APIKey string
+
+	// Now returns the current time. Defaults to time.Now. Advance and
+	// AdvanceTo replace it with a stopped clock.
+	Now func() time.Time
+
+	// WebhookURL, if set, receives the webhooks generated by Advance and
+	// AdvanceTo as XML POST requests, like those sent by Recurly.
+	WebhookURL string
+
+	// Webhooks, if set, receives the notifications generated by Advance and
+	// AdvanceTo, as returned by webhooks.Parse. Sends block, so the channel
+	// should be buffered or drained by another goroutine.
+	Webhooks chan<- interface{}
+
+	// DunningAttempts is the number of times a past due invoice is retried
+	// before it fails and its subscriptions expire. Retries are made daily.
+	// Defaults to DefaultDunningAttempts.
+	DunningAttempts int
+
+	mu     sync.Mutex
+	routes []route
+
+	accounts      []*account
+	plans         []*recurly.Plan
+	addOns        map[string][]*recurly.AddOn
+	subscriptions []*subscription
+	adjustments   []*recurly.Adjustment
+	invoices      []*recurly.Invoice
+	transactions  []*recurly.Transaction
+	coupons       []*recurly.Coupon
+	redemptions   []*recurly.Redemption
+
+	invoiceNumber int
+	couponID      int64
+
+	// attempts holds the number of times each past due invoice has been
+	// retried, by invoice number.
+	attempts map[int]int
+}
+
+// NewServer returns an empty Server.
+func NewServer() *Server {
+	s := &Server{
+		addOns:        make(map[string][]*recurly.AddOn),
+		invoiceNumber: 1000,
+		attempts:      make(map[int]int),
+	}
+
+	s.routes = []route{
+		{"GET", "accounts", s.listAccounts},
+		{"POST", "accounts", s.createAccount},
+		{"GET", "accounts/:account_code", s.getAccount},
+		{"PUT", "accounts/:account_code", s.updateAccount},
+		{"DELETE", "accounts/:account_code", s.closeAccount},
+		{"PUT", "accounts/:account_code/reopen", s.reopenAccount},
+		{"GET", "accounts/:account_code/billing_info", s.getBilling},
+		{"POST", "accounts/:account_code/billing_info", s.createBilling},
+		{"PUT", "accounts/:account_code/billing_info", s.updateBilling},
+		{"DELETE", "accounts/:account_code/billing_info", s.clearBilling},
+		{"GET", "accounts/:account_code/subscriptions", s.listAccountSubscriptions},
+		{"GET", "accounts/:account_code/adjustments", s.listAccountAdjustments},
+		{"POST", "accounts/:account_code/adjustments", s.createAdjustment},
+		{"GET", "accounts/:account_code/invoices", s.listAccountInvoices},
+		{"POST", "accounts/:account_code/invoices", s.createInvoice},
+		{"GET", "accounts/:account_code/transactions", s.listAccountTransactions},
+		{"GET", "accounts/:account_code/redemptions", s.listAccountRedemptions},
+		{"DELETE", "accounts/:account_code/redemptions/:uuid", s.deleteRedemption},
+
+		{"GET", "plans", s.listPlans},
+		{"POST", "plans", s.createPlan},
+		{"GET", "plans/:plan_code", s.getPlan},
+		{"PUT", "plans/:plan_code", s.updatePlan},
+		{"DELETE", "plans/:plan_code", s.deletePlan},
+		{"GET", "plans/:plan_code/add_ons", s.listAddOns},
+		{"POST", "plans/:plan_code/add_ons", s.createAddOn},
+		{"GET", "plans/:plan_code/add_ons/:add_on_code", s.getAddOn},
+		{"PUT", "plans/:plan_code/add_ons/:add_on_code", s.updateAddOn},
+		{"DELETE", "plans/:plan_code/add_ons/:add_on_code", s.deleteAddOn},
+
+		{"GET", "subscriptions", s.listSubscriptions},
+		{"POST", "subscriptions", s.createSubscription},
+		{"GET", "subscriptions/:uuid", s.getSubscription},
+		{"PUT", "subscriptions/:uuid", s.updateSubscription},
+		{"PUT", "subscriptions/:uuid/cancel", s.cancelSubscription},
+		{"PUT", "subscriptions/:uuid/reactivate", s.reactivateSubscription},
+		{"PUT", "subscriptions/:uuid/terminate", s.terminateSubscription},
+		{"PUT", "subscriptions/:uuid/pause", s.pauseSubscription},
+		{"PUT", "subscriptions/:uuid/resume", s.resumeSubscription},
+		{"PUT", "subscriptions/:uuid/postpone", s.postponeSubscription},
+		{"PUT", "subscriptions/:uuid/convert_trial", s.convertTrial},
+		{"GET", "subscriptions/:uuid/redemptions", s.listSubscriptionRedemptions},
+
+		{"GET", "adjustments/:uuid", s.getAdjustment},
+		{"DELETE", "adjustments/:uuid", s.deleteAdjustment},
+
+		{"GET", "invoices", s.listInvoices},
+		{"GET", "invoices/:invoice_number", s.getInvoice},
+		{"PUT", "invoices/:invoice_number/collect", s.collectInvoice},
+		{"PUT", "invoices/:invoice_number/mark_successful", s.markInvoicePaid},
+		{"PUT", "invoices/:invoice_number/mark_failed", s.markInvoiceFailed},
+
+		{"GET", "transactions", s.listTransactions},
+		{"GET", "transactions/:uuid", s.getTransaction},
+
+		{"GET", "coupons", s.listCoupons},
+		{"POST", "coupons", s.createCoupon},
+		{"GET", "coupons/:coupon_code", s.getCoupon},
+		{"PUT", "coupons/:coupon_code", s.updateCoupon},
+		{"DELETE", "coupons/:coupon_code", s.deleteCoupon},
+		{"PUT", "coupons/:coupon_code/restore", s.restoreCoupon},
+		{"POST", "coupons/:coupon_code/redeem", s.redeemCoupon},
+	}
+	return s
+}
+
+// Client returns a *recurly.Client that sends requests directly to s
+// without opening a network connection.
+func (s *Server) Client() *recurly.Client {
+	apiKey := s.APIKey
+	if apiKey == "" {
+		apiKey = "recurlytest"
+	}
+
+	client := recurly.NewClient("recurlytest", apiKey)
+	client.Client = handlerDoer{s}
+	return client
+}
+
+// handlerDoer implements recurly.HTTPDoer by serving requests with an
+// http.Handler.
+type handlerDoer struct {
+	h http.Handler
+}
+
+func (d handlerDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := req.Context().Err(); err != nil {
+		return nil, err
+	}
+
+	w := httptest.NewRecorder()
+	d.h.ServeHTTP(w, req)
+	resp := w.Result()
+	resp.Request = req
+	return resp, nil
+}
+
+// route maps a method and path pattern to a handler. Pattern segments
+// beginning with a colon match any value.
+type route struct {
+	method  string
+	pattern string
+	handle  func(c *call)
+}
+
+// match returns the parameters of path if it matches the route.
+func (rt route) match(method string, path []string) (map[string]string, bool) {
+	if method != rt.method && !(method == "HEAD" && rt.method == "GET") {
+		return nil, false
+	}
+
+	pattern := strings.Split(rt.pattern, "/")
+	if len(pattern) != len(path) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, p := range pattern {
+		if strings.HasPrefix(p, ":") {
+			params[p[1:]] = path[i]
+		} else if p != path[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.APIKey != "" {
+		if apiKey(r) != s.APIKey {
+			writeError(w, http.StatusUnauthorized, "unauthorized", "Please provide a valid API key.")
+			return
+		}
+	}
+
+	path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v2/"), "/"), "/")
+	for _, rt := range s.routes {
+		params, ok := rt.match(r.Method, path)
+		if !ok {
+			continue
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		rt.handle(&call{w: w, r: r, params: params, base: baseURL(r)})
+		return
+	}
+	writeError(w, http.StatusNotImplemented, "not_implemented", fmt.Sprintf("%s %s is not implemented by recurlytest", r.Method, r.URL.Path))
+}
+
+// call holds the state of a single request.
+type call struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	params map[string]string
+	base   string
+}
+
+// param returns the path parameter name. Dashes are removed from UUIDs.
+func (c *call) param(name string) string {
+	v, _ := url.PathUnescape(c.params[name])
+	if name == "uuid" {
+		v = strings.Replace(v, "-", "", -1)
+	}
+	return v
+}
+
+// intParam returns the path parameter name as an int.
+func (c *call) intParam(name string) int {
+	i, _ := strconv.Atoi(c.params[name])
+	return i
+}
+
+// decode decodes the XML request body into v. If the body cannot be
+// decoded, a 400 is written and false is returned.
+func (c *call) decode(v interface{}) bool {
+	var b []byte
+	var err error
+	if c.r.Body != nil {
+		b, err = ioutil.ReadAll(c.r.Body)
+	}
+	if err != nil || len(bytes.TrimSpace(b)) == 0 {
+		writeError(c.w, http.StatusBadRequest, "bad_request", "The request body is empty.")
+		return false
+	} else if err := xml.Unmarshal(b, v); err != nil {
+		writeError(c.w, http.StatusBadRequest, "bad_request", "The provided XML was invalid.")
+		return false
+	}
+	return true
+}
+
+// href returns a link to the resource at path.
+func (c *call) href(format string, args ...interface{}) *href {
+	return &href{HREF: c.base + "/v2/" + fmt.Sprintf(format, args...)}
+}
+
+// write writes v as the XML response body.
+func (c *call) write(status int, v interface{}) {
+	b, err := xml.Marshal(v)
+	if err != nil {
+		writeError(c.w, http.StatusInternalServerError, "internal_server_error", err.Error())
+		return
+	}
+	c.w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.w.WriteHeader(status)
+	io.WriteString(c.w, xml.Header)
+	c.w.Write(b)
+}
+
+// writeList writes a page of items in an element named name. Pages are
+// selected with the cursor and per_page query parameters, and a Link header
+// is set when there are more results. HEAD requests only receive the
+// X-Records header with the total count.
+func (c *call) writeList(name string, items []interface{}) {
+	q := c.r.URL.Query()
+	if q.Get("order") != "asc" {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	c.w.Header().Set("X-Records", strconv.Itoa(len(items)))
+	if c.r.Method == "HEAD" {
+		c.w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	perPage, _ := strconv.Atoi(q.Get("per_page"))
+	if perPage <= 0 {
+		perPage = DefaultPerPage
+	}
+	start, _ := strconv.Atoi(q.Get("cursor"))
+	if start < 0 || start > len(items) {
+		start = len(items)
+	}
+	end := start + perPage
+	if end >= len(items) {
+		end = len(items)
+	} else {
+		q.Set("cursor", strconv.Itoa(end))
+		q.Set("per_page", strconv.Itoa(perPage))
+		next := url.URL{Path: c.r.URL.Path, RawQuery: q.Encode()}
+		c.w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="next"`, c.base, next.String()))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	root := xml.StartElement{Name: xml.Name{Local: name}}
+	enc.EncodeToken(root)
+	for _, item := range items[start:end] {
+		if err := enc.Encode(item); err != nil {
+			writeError(c.w, http.StatusInternalServerError, "internal_server_error", err.Error())
+			return
+		}
+	}
+	enc.EncodeToken(root.End())
+	enc.Flush()
+
+	c.w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	c.w.WriteHeader(http.StatusOK)
+	c.w.Write(buf.Bytes())
+}
+
+// notFound writes a 404 for a missing resource.
+func (c *call) notFound(resource, field, value string) {
+	writeError(c.w, http.StatusNotFound, "not_found", fmt.Sprintf("Couldn't find %s with %s = %s", resource, field, value))
+}
+
+// invalidTransition writes a 400 for a state change that is not allowed.
+func (c *call) invalidTransition(description string) {
+	writeError(c.w, http.StatusBadRequest, "invalid_transition", description)
+}
+
+// fieldError is a validation error for a single field.
+type fieldError struct {
+	Field       string `xml:"field,attr"`
+	Symbol      string `xml:"symbol,attr"`
+	Description string `xml:",chardata"`
+}
+
+// validationErrors collects field errors.
+type validationErrors []fieldError
+
+func (v *validationErrors) add(field, symbol, description string) {
+	*v = append(*v, fieldError{Field: field, Symbol: symbol, Description: description})
+}
+
+// write writes the errors with a 422 and returns true if there are any.
+func (v validationErrors) write(c *call) bool {
+	if len(v) == 0 {
+		return false
+	}
+	c.write(http.StatusUnprocessableEntity, struct {
+		XMLName xml.Name     `xml:"errors"`
+		Errors  []fieldError `xml:"error"`
+	}{Errors: v})
+	return true
+}
+
+// writeError writes a single error.
+func writeError(w http.ResponseWriter, status int, symbol, description string) {
+	b, _ := xml.Marshal(struct {
+		XMLName     xml.Name `xml:"error"`
+		Symbol      string   `xml:"symbol"`
+		Description string   `xml:"description"`
+	}{Symbol: symbol, Description: description})
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header)
+	w.Write(b)
+}
+
+// apiKey returns the API key in the Authorization header of r. The client
+// encodes the key without a password, so the colon is optional.
+func apiKey(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Basic ") {
+		return ""
+	}
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "Basic "))
+	if err != nil {
+		return ""
+	}
+	return strings.SplitN(string(b), ":", 2)[0]
+}
+
+// baseURL returns the scheme and host of r.
+func baseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil || r.URL.Scheme == "https" {
+		scheme = "https"
+	}
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	return scheme + "://" + host
+}
+
+// now returns the current time truncated to the second, which is the
+// precision of Recurly timestamps.
+func (s *Server) now() time.Time {
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	return now().UTC().Truncate(time.Second)
+}
+
+// newUUID returns a random UUID in the format used by Recurly.
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// filter returns the items for which fn returns true, as []interface{} for
+// writeList.
+func filter(n int, fn func(i int) (interface{}, bool)) []interface{} {
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		if v, ok := fn(i); ok {
+			items = append(items, v)
+		}
+	}
+	return items
+}
+
+// stateMatches returns true if the state query parameter of c is empty or
+// matches state.
+func (c *call) stateMatches(states ...string) bool {
+	want := c.r.URL.Query().Get("state")
+	if want == "" {
+		return true
+	}
+	for _, s := range states {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,258 @@
+package recurlytest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/recurlytest"
+)
+
+func TestServer_Accounts(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+
+	if a, err := client.Accounts.Create(ctx, recurly.Account{Code: "1", Email: "verena@example.com"}); err != nil {
+		t.Fatal(err)
+	} else if a.Code != "1" || a.State != "active" || a.CreatedAt.Time() != Now {
+		t.Fatalf("unexpected account: %#v", a)
+	}
+
+	// Ensure validation errors are returned.
+	if _, err := client.Accounts.Create(ctx, recurly.Account{Code: "1"}); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("taken") || e.ValidationErrors[0].Field != "account.account_code" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if a, err := client.Accounts.Update(ctx, "1", recurly.Account{FirstName: "Verena"}); err != nil {
+		t.Fatal(err)
+	} else if a.FirstName != "Verena" || a.Email != "verena@example.com" {
+		t.Fatalf("unexpected account: %#v", a)
+	}
+
+	if err := client.Accounts.Close(ctx, "1"); err != nil {
+		t.Fatal(err)
+	} else if a, err := client.Accounts.Get(ctx, "1"); err != nil {
+		t.Fatal(err)
+	} else if a.State != "closed" || !a.ClosedAt.Time().Equal(Now) {
+		t.Fatalf("unexpected account: %#v", a)
+	}
+
+	if err := client.Accounts.Reopen(ctx, "1"); err != nil {
+		t.Fatal(err)
+	} else if err := client.Accounts.Reopen(ctx, "1"); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("invalid_transition") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Ensure missing resources return nil.
+	if a, err := client.Accounts.Get(ctx, "2"); err != nil {
+		t.Fatal(err)
+	} else if a != nil {
+		t.Fatalf("unexpected account: %#v", a)
+	}
+}
+
+func TestServer_Billing(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+	MustCreateAccount(t, client, "1")
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		if _, err := client.Billing.Create(ctx, "1", recurly.Billing{Number: 4111111111111112, Month: 12, Year: 2030}); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("invalid") || e.ValidationErrors[0].Field != "billing_info.number" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := client.Billing.Create(ctx, "1", recurly.Billing{Number: 4111111111111111, Month: 1, Year: 2020}); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("expired") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrDeclined", func(t *testing.T) {
+		if _, err := client.Billing.Create(ctx, "1", recurly.Billing{Number: recurlytest.CardDeclined, Month: 12, Year: 2030}); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.TransactionFailedError); !ok {
+			t.Fatalf("unexpected error: %v", err)
+		} else if e.TransactionError.ErrorCode != "declined" || e.Transaction == nil || e.Transaction.Status != recurlytest.TransactionStatusDeclined {
+			t.Fatalf("unexpected error: %#v", e)
+		}
+
+		if b, err := client.Billing.Get(ctx, "1"); err != nil {
+			t.Fatal(err)
+		} else if b != nil {
+			t.Fatalf("unexpected billing info: %#v", b)
+		}
+	})
+
+	t.Run("OK", func(t *testing.T) {
+		if _, err := client.Billing.Create(ctx, "1", recurly.Billing{FirstName: "Verena", Number: 5555555555554444, Month: 12, Year: 2030, VerificationValue: 123}); err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := client.Billing.Get(ctx, "1")
+		if err != nil {
+			t.Fatal(err)
+		} else if b.CardType != "master" || b.FirstSix != "555555" || b.LastFour != "4444" || b.PaymentType != "credit_card" {
+			t.Fatalf("unexpected billing info: %#v", b)
+		} else if b.Number != 0 || b.VerificationValue != 0 {
+			t.Fatalf("unexpected card details: %#v", b)
+		}
+
+		if err := client.Billing.Clear(ctx, "1"); err != nil {
+			t.Fatal(err)
+		} else if b, err := client.Billing.Get(ctx, "1"); err != nil {
+			t.Fatal(err)
+		} else if b != nil {
+			t.Fatalf("unexpected billing info: %#v", b)
+		}
+	})
+}
+
+func TestServer_Plans(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+	MustCreatePlan(t, client, "gold", 1000)
+
+	if _, err := client.AddOns.Create(ctx, "gold", recurly.AddOn{Code: "seat", Name: "Seat", UnitAmountInCents: recurly.UnitAmount{USD: 200}}); err != nil {
+		t.Fatal(err)
+	} else if a, err := client.AddOns.Get(ctx, "gold", "seat"); err != nil {
+		t.Fatal(err)
+	} else if a.Name != "Seat" || a.DefaultQuantity.Int() != 1 || a.UnitAmountInCents.USD != 200 {
+		t.Fatalf("unexpected add-on: %#v", a)
+	}
+
+	if p, err := client.Plans.Update(ctx, "gold", recurly.Plan{Name: "Gold Plan"}); err != nil {
+		t.Fatal(err)
+	} else if p.Name != "Gold Plan" || p.IntervalUnit != "months" || p.IntervalLength != 1 {
+		t.Fatalf("unexpected plan: %#v", p)
+	}
+
+	if err := client.Plans.Delete(ctx, "gold"); err != nil {
+		t.Fatal(err)
+	} else if p, err := client.Plans.Get(ctx, "gold"); err != nil {
+		t.Fatal(err)
+	} else if p != nil {
+		t.Fatalf("unexpected plan: %#v", p)
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+	for _, code := range []string{"1", "2", "3", "4", "5"} {
+		MustCreateAccount(t, client, code)
+	}
+	if err := client.Accounts.Close(ctx, "3"); err != nil {
+		t.Fatal(err)
+	}
+
+	pager := client.Accounts.List(&recurly.PagerOptions{PerPage: 2, Order: "asc"})
+	if n, err := pager.Count(ctx); err != nil {
+		t.Fatal(err)
+	} else if n != 5 {
+		t.Fatalf("unexpected count: %d", n)
+	}
+
+	var pages [][]string
+	for pager.Next() {
+		var accounts []recurly.Account
+		if err := pager.Fetch(ctx, &accounts); err != nil {
+			t.Fatal(err)
+		}
+
+		var codes []string
+		for _, a := range accounts {
+			codes = append(codes, a.Code)
+		}
+		pages = append(pages, codes)
+	}
+	if len(pages) != 3 || len(pages[0]) != 2 || pages[0][0] != "1" || pages[1][0] != "3" || len(pages[2]) != 1 || pages[2][0] != "5" {
+		t.Fatalf("unexpected pages: %v", pages)
+	}
+
+	// Ensure results are newest first by default and filtered by state.
+	var accounts []recurly.Account
+	if err := client.Accounts.List(&recurly.PagerOptions{State: "active"}).FetchAll(ctx, &accounts); err != nil {
+		t.Fatal(err)
+	} else if len(accounts) != 4 || accounts[0].Code != "5" || accounts[3].Code != "1" {
+		t.Fatalf("unexpected accounts: %#v", accounts)
+	}
+}
+
+func TestServer_ServeHTTP(t *testing.T) {
+	s := NewTestServer()
+	s.APIKey = "secret"
+
+	t.Run("ErrUnauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/v2/accounts", nil)
+		r.SetBasicAuth("wrong", "")
+		s.ServeHTTP(w, r)
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	})
+
+	t.Run("ErrNotImplemented", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/v2/gift_cards", nil)
+		r.SetBasicAuth("secret", "")
+		s.ServeHTTP(w, r)
+		if w.Code != http.StatusNotImplemented {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	})
+
+	t.Run("Client", func(t *testing.T) {
+		if _, err := s.Client().Accounts.Create(context.Background(), recurly.Account{Code: "1"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Now is the time returned by the clock of servers returned by
+// NewTestServer.
+var Now = time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+
+// NewTestServer returns a server with a fixed clock.
+func NewTestServer() *recurlytest.Server {
+	s := recurlytest.NewServer()
+	s.Now = func() time.Time { return Now }
+	return s
+}
+
+// MustCreateAccount creates an account with code.
+func MustCreateAccount(t *testing.T, client *recurly.Client, code string) {
+	t.Helper()
+	if _, err := client.Accounts.Create(context.Background(), recurly.Account{Code: code}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// MustCreatePlan creates a monthly plan with code that costs amount USD.
+func MustCreatePlan(t *testing.T, client *recurly.Client, code string, amount int) {
+	t.Helper()
+	if _, err := client.Plans.Create(context.Background(), recurly.Plan{
+		Code:              code,
+		Name:              code,
+		UnitAmountInCents: recurly.UnitAmount{USD: amount},
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCard is billing info for a card that is always accepted.
+var TestCard = recurly.Billing{Number: 4111111111111111, Month: 12, Year: 2030}
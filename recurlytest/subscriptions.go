@@ -0,0 +1,711 @@
+package recurlytest
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// subscription holds a subscription and the billing interval of its plan,
+// which is kept if the plan is deleted.
+type subscription struct {
+	recurly.Subscription
+	intervalUnit   string
+	intervalLength int
+}
+
+func (s *Server) findSubscription(uuid string) *subscription {
+	for _, sub := range s.subscriptions {
+		if sub.UUID == uuid {
+			return sub
+		}
+	}
+	return nil
+}
+
+// lookupSubscription returns the subscription in the uuid parameter, or
+// writes a 404 and returns nil.
+func (s *Server) lookupSubscription(c *call) *subscription {
+	uuid := c.param("uuid")
+	sub := s.findSubscription(uuid)
+	if sub == nil {
+		c.notFound("Subscription", "uuid", uuid)
+	}
+	return sub
+}
+
+// subscriptionMatches returns true if sub matches the state query parameter
+// of c, including the live, in_trial and past_due filters.
+func (s *Server) subscriptionMatches(c *call, sub *subscription) bool {
+	switch c.r.URL.Query().Get("state") {
+	case "":
+		return true
+	case recurly.SubscriptionStateLive:
+		return sub.State == recurly.SubscriptionStateActive || sub.State == recurly.SubscriptionStateCanceled || sub.State == recurly.SubscriptionStatePaused
+	case recurly.SubscriptionStateInTrial:
+		return sub.State == recurly.SubscriptionStateActive && s.inTrial(sub)
+	case recurly.SubscriptionStatePastDue:
+		inv := s.findInvoice(sub.InvoiceNumber)
+		return inv != nil && inv.State == recurly.ChargeInvoiceStatePastDue
+	}
+	return c.stateMatches(sub.State)
+}
+
+// inTrial returns true if sub is in its trial period.
+func (s *Server) inTrial(sub *subscription) bool {
+	return hasTime(sub.TrialEndsAt) && s.now().Before(sub.TrialEndsAt.Time())
+}
+
+func (s *Server) listSubscriptions(c *call) {
+	c.writeList("subscriptions", filter(len(s.subscriptions), func(i int) (interface{}, bool) {
+		sub := s.subscriptions[i]
+		return c.subscription(&sub.Subscription), s.subscriptionMatches(c, sub)
+	}))
+}
+
+func (s *Server) listAccountSubscriptions(c *call) {
+	a := s.lookupAccount(c)
+	if a == nil {
+		return
+	}
+
+	c.writeList("subscriptions", filter(len(s.subscriptions), func(i int) (interface{}, bool) {
+		sub := s.subscriptions[i]
+		return c.subscription(&sub.Subscription), sub.AccountCode == a.Code && s.subscriptionMatches(c, sub)
+	}))
+}
+
+func (s *Server) getSubscription(c *call) {
+	if sub := s.lookupSubscription(c); sub != nil {
+		c.write(http.StatusOK, c.subscription(&sub.Subscription))
+	}
+}
+
+// createSubscription creates a subscription, creating the account if it
+// does not exist. Subscriptions without a trial or start date are invoiced
+// immediately. If the charge is declined, nothing is created except the
+// declined transaction.
+func (s *Server) createSubscription(c *call) {
+	var v recurly.NewSubscription
+	if !c.decode(&v) {
+		return
+	}
+
+	currency := v.Currency
+	if currency == "" {
+		currency = "USD"
+	}
+	method := v.CollectionMethod
+	if method == "" {
+		method = recurly.CollectionMethodAutomatic
+	}
+
+	var errs validationErrors
+	plan := s.findPlan(v.PlanCode)
+	if v.PlanCode == "" {
+		errs.add("subscription.plan_code", "blank", "can't be blank")
+	} else if plan == nil {
+		errs.add("subscription.plan_code", "invalid", "is invalid")
+	}
+	if v.Account.Code == "" {
+		errs.add("subscription.account.account_code", "blank", "can't be blank")
+	}
+
+	var unitAmount int
+	if !validCurrency(currency) {
+		errs.add("subscription.currency", "invalid", "is not a valid currency")
+	} else if plan != nil {
+		var ok bool
+		if unitAmount, ok = amount(plan.UnitAmountInCents, currency); !ok {
+			errs.add("subscription.currency", "invalid", "is not supported by the plan")
+		}
+	}
+	if hasInt(v.UnitAmountInCents) {
+		unitAmount = v.UnitAmountInCents.Int()
+	}
+
+	var addOns []recurly.SubscriptionAddOn
+	if plan != nil && v.SubscriptionAddOns != nil {
+		var ok bool
+		if addOns, ok = s.subscriptionAddOns(plan.Code, currency, *v.SubscriptionAddOns); !ok {
+			errs.add("subscription.subscription_add_ons.add_on_code", "invalid", "is invalid")
+		}
+	}
+
+	var coupon *recurly.Coupon
+	if v.CouponCode != "" {
+		if coupon = s.findCoupon(v.CouponCode); coupon == nil {
+			errs.add("subscription.coupon_code", "invalid", "is invalid")
+		} else if symbol, desc := s.checkRedeemable(coupon, v.Account.Code); symbol != "" {
+			errs.add("subscription.coupon_code", symbol, desc)
+		}
+	}
+
+	a := s.findAccount(v.Account.Code)
+	if method != recurly.CollectionMethodAutomatic && method != recurly.CollectionMethodManual {
+		errs.add("subscription.collection_method", "invalid", "is not included in the list")
+	} else if method == recurly.CollectionMethodAutomatic && v.Account.BillingInfo == nil && (a == nil || a.billing == nil) && (unitAmount > 0 || len(addOns) > 0) {
+		errs.add("subscription.account.base", "billing_info_required", "Billing info is required for automatic collection")
+	}
+	if a != nil && a.State == "closed" {
+		errs.add("subscription.account.base", "invalid", "Account is closed")
+	}
+	if errs.write(c) {
+		return
+	}
+
+	// Create the account or update its billing info, restoring it if the
+	// purchase fails.
+	created := a == nil
+	var prevBilling *recurly.Billing
+	if created {
+		var ok bool
+		if a, ok = s.newAccount(c, v.Account); !ok {
+			return
+		}
+	} else if v.Account.BillingInfo != nil {
+		b, ok := s.validateBilling(c, a, *v.Account.BillingInfo)
+		if !ok {
+			return
+		}
+		prevBilling, a.billing = a.billing, b
+	}
+
+	now := s.now()
+	sub := &subscription{
+		Subscription: recurly.Subscription{
+			XMLName:              xml.Name{Local: "subscription"},
+			Plan:                 recurly.NestedPlan{Code: plan.Code, Name: plan.Name},
+			AccountCode:          a.Code,
+			UUID:                 newUUID(),
+			State:                recurly.SubscriptionStateActive,
+			UnitAmountInCents:    unitAmount,
+			Currency:             currency,
+			Quantity:             v.Quantity,
+			SubscriptionAddOns:   addOns,
+			PONumber:             v.PONumber,
+			NetTerms:             v.NetTerms,
+			CollectionMethod:     method,
+			CustomerNotes:        v.CustomerNotes,
+			AutoRenew:            true,
+			RenewalBillingCycles: v.RenewalBillingCycles,
+			CustomFields:         v.CustomFields,
+		},
+		intervalUnit:   plan.IntervalUnit,
+		intervalLength: plan.IntervalLength,
+	}
+	if sub.Quantity <= 0 {
+		sub.Quantity = 1
+	}
+	if v.TotalBillingCycles > 0 {
+		sub.RemainingBillingCycles = recurly.NewInt(v.TotalBillingCycles)
+	} else if hasInt(plan.TotalBillingCycles) {
+		sub.RemainingBillingCycles = plan.TotalBillingCycles
+	}
+	sub.TotalAmountInCents = subscriptionTotal(&sub.Subscription)
+
+	var adjs []*recurly.Adjustment
+	switch {
+	case hasTime(v.StartsAt) && v.StartsAt.Time().After(now):
+		sub.State = recurly.SubscriptionStateFuture
+		sub.ActivatedAt = v.StartsAt
+	case hasTime(v.TrialEndsAt) || plan.TrialIntervalLength > 0:
+		trialEndsAt := addInterval(now, plan.TrialIntervalUnit, plan.TrialIntervalLength)
+		if hasTime(v.TrialEndsAt) {
+			trialEndsAt = v.TrialEndsAt.Time()
+		}
+		sub.ActivatedAt = recurly.NewTime(now)
+		sub.TrialStartedAt, sub.TrialEndsAt = recurly.NewTime(now), recurly.NewTime(trialEndsAt)
+		s.startPeriod(sub, now, trialEndsAt)
+	default:
+		end := addInterval(now, sub.intervalUnit, sub.intervalLength)
+		if hasTime(v.NextBillDate) {
+			end = v.NextBillDate.Time()
+		}
+		sub.ActivatedAt = recurly.NewTime(now)
+		s.startPeriod(sub, now, end)
+		adjs = s.subscriptionCharges(sub)
+		useBillingCycle(sub)
+	}
+	if setupFee, _ := amount(plan.SetupFeeInCents, currency); setupFee > 0 && sub.State != recurly.SubscriptionStateFuture {
+		adj := s.newAdjustment(a.Code, currency, plan.Name+" setup fee", "setup_fee", setupFee, 1)
+		adj.ProductCode, adj.SubscriptionUUID = plan.Code, sub.UUID
+		adjs = append(adjs, adj)
+	}
+
+	var reds []*recurly.Redemption
+	if !created {
+		reds = s.activeRedemptions(a.Code, sub.UUID)
+	}
+	var redemption *recurly.Redemption
+	if coupon != nil {
+		redemption = s.newRedemption(coupon, a.Code, currency, sub.UUID)
+		reds = append(reds, redemption)
+	}
+
+	if len(adjs) > 0 {
+		if t, ok := s.chargeSubscription(a, sub, adjs, recurly.ChargeInvoiceOriginPurchase, reds); !ok {
+			if !created {
+				a.billing = prevBilling
+			}
+			c.declined(t)
+			return
+		}
+	}
+
+	if created {
+		s.accounts = append(s.accounts, a)
+	} else if prevBilling != nil || v.Account.BillingInfo != nil {
+		a.UpdatedAt = recurly.NewTime(now)
+	}
+	if redemption != nil {
+		s.addRedemption(redemption)
+	}
+	s.subscriptions = append(s.subscriptions, sub)
+	c.write(http.StatusCreated, c.subscription(&sub.Subscription))
+}
+
+// subscriptionAddOns resolves the add-ons of a subscription to plan. Add-ons
+// without a unit amount use the amount of the plan add-on.
+func (s *Server) subscriptionAddOns(planCode, currency string, in []recurly.SubscriptionAddOn) ([]recurly.SubscriptionAddOn, bool) {
+	addOns := make([]recurly.SubscriptionAddOn, 0, len(in))
+	for _, v := range in {
+		ao := s.findAddOn(planCode, v.Code)
+		if ao == nil {
+			return nil, false
+		}
+
+		v.XMLName = xml.Name{Local: "subscription_add_on"}
+		if !hasInt(v.UnitAmountInCents) {
+			unit, _ := amount(ao.UnitAmountInCents, currency)
+			v.UnitAmountInCents = recurly.NewInt(unit)
+		}
+		if v.Quantity <= 0 {
+			v.Quantity = 1
+			if hasInt(ao.DefaultQuantity) {
+				v.Quantity = ao.DefaultQuantity.Int()
+			}
+		}
+		if v.Type == "" {
+			v.Type = ao.AddOnType
+		}
+		addOns = append(addOns, v)
+	}
+	return addOns, true
+}
+
+// subscriptionTotal returns the amount charged for each billing period of
+// sub.
+func subscriptionTotal(sub *recurly.Subscription) int {
+	total := sub.UnitAmountInCents * sub.Quantity
+	for _, ao := range sub.SubscriptionAddOns {
+		total += ao.UnitAmountInCents.Int() * ao.Quantity
+	}
+	return total
+}
+
+// startPeriod starts a new billing period for sub.
+func (s *Server) startPeriod(sub *subscription, start, end time.Time) {
+	sub.CurrentPeriodStartedAt = recurly.NewTime(start)
+	sub.CurrentPeriodEndsAt = recurly.NewTime(end)
+	if !hasTime(sub.CurrentTermStartedAt) || !start.Before(sub.CurrentTermEndsAt.Time()) {
+		sub.CurrentTermStartedAt = recurly.NewTime(start)
+		sub.CurrentTermEndsAt = recurly.NewTime(end)
+	}
+}
+
+// subscriptionCharges returns adjustments for the current period of sub.
+// They are not stored.
+func (s *Server) subscriptionCharges(sub *subscription) []*recurly.Adjustment {
+	adj := s.newAdjustment(sub.AccountCode, sub.Currency, sub.Plan.Name, "plan", sub.UnitAmountInCents, sub.Quantity)
+	adj.ProductCode, adj.SubscriptionUUID = sub.Plan.Code, sub.UUID
+	adj.StartDate, adj.EndDate = sub.CurrentPeriodStartedAt, sub.CurrentPeriodEndsAt
+	adjs := []*recurly.Adjustment{adj}
+
+	for _, ao := range sub.SubscriptionAddOns {
+		adj := s.newAdjustment(sub.AccountCode, sub.Currency, ao.Code, "add_on", ao.UnitAmountInCents.Int(), ao.Quantity)
+		adj.ProductCode, adj.SubscriptionUUID = ao.Code, sub.UUID
+		adj.StartDate, adj.EndDate = sub.CurrentPeriodStartedAt, sub.CurrentPeriodEndsAt
+		adjs = append(adjs, adj)
+	}
+	return adjs
+}
+
+// chargeSubscription invoices adjs for sub, applying the discounts of reds,
+// and collects the invoice if sub is collected automatically. If the charge
+// is declined, nothing is stored except the declined transaction, which is
+// returned with false.
+func (s *Server) chargeSubscription(a *account, sub *subscription, adjs []*recurly.Adjustment, origin string, reds []*recurly.Redemption) (*recurly.Transaction, bool) {
+	discounts := s.discount(adjs, reds)
+	inv := s.newInvoice(a, adjs, origin, sub.CollectionMethod, sub.NetTerms)
+	inv.PONumber = sub.PONumber
+
+	var t *recurly.Transaction
+	if sub.CollectionMethod == recurly.CollectionMethodAutomatic && inv.BalanceInCents > 0 {
+		if t = s.charge(a, inv.BalanceInCents, inv.Currency); t.Status != recurly.TransactionStatusSuccess {
+			return t, false
+		}
+	}
+
+	s.commitInvoice(inv, adjs)
+	s.commitDiscounts(reds, discounts)
+	if t != nil {
+		t.InvoiceNumber = inv.InvoiceNumber
+		s.closeInvoice(inv, recurly.ChargeInvoiceStatePaid)
+	}
+	sub.InvoiceNumber = inv.InvoiceNumber
+	return t, true
+}
+
+// updateSubscription changes a subscription now or at renewal. Changes made
+// now are prorated: increases are charged immediately and decreases are
+// credited to the account.
+func (s *Server) updateSubscription(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	} else if sub.State != recurly.SubscriptionStateActive && sub.State != recurly.SubscriptionStateFuture {
+		c.invalidTransition("Only active or future subscriptions can be changed.")
+		return
+	}
+
+	var v recurly.UpdateSubscription
+	if !c.decode(&v) {
+		return
+	}
+
+	var errs validationErrors
+	plan := s.findPlan(sub.Plan.Code)
+	if v.PlanCode != "" {
+		if plan = s.findPlan(v.PlanCode); plan == nil {
+			errs.add("subscription.plan_code", "invalid", "is invalid")
+		}
+	}
+	if v.Timeframe != "" && v.Timeframe != "now" && v.Timeframe != "renewal" && v.Timeframe != "bill_date" {
+		errs.add("subscription.timeframe", "invalid", "is not included in the list")
+	}
+	if v.CollectionMethod != "" && v.CollectionMethod != recurly.CollectionMethodAutomatic && v.CollectionMethod != recurly.CollectionMethodManual {
+		errs.add("subscription.collection_method", "invalid", "is not included in the list")
+	}
+
+	// Calculate the subscription after the change.
+	next := sub.Subscription
+	if plan != nil && v.PlanCode != "" && v.PlanCode != sub.Plan.Code {
+		next.Plan = recurly.NestedPlan{Code: plan.Code, Name: plan.Name}
+		next.SubscriptionAddOns = nil
+		var ok bool
+		if next.UnitAmountInCents, ok = amount(plan.UnitAmountInCents, sub.Currency); !ok {
+			errs.add("subscription.currency", "invalid", "is not supported by the plan")
+		}
+	}
+	if hasInt(v.UnitAmountInCents) {
+		next.UnitAmountInCents = v.UnitAmountInCents.Int()
+	}
+	if v.Quantity > 0 {
+		next.Quantity = v.Quantity
+	}
+	if v.SubscriptionAddOns != nil {
+		if plan == nil {
+			errs.add("subscription.subscription_add_ons.add_on_code", "invalid", "is invalid")
+		} else if addOns, ok := s.subscriptionAddOns(plan.Code, sub.Currency, *v.SubscriptionAddOns); !ok {
+			errs.add("subscription.subscription_add_ons.add_on_code", "invalid", "is invalid")
+		} else {
+			next.SubscriptionAddOns = addOns
+		}
+	}
+
+	var coupon *recurly.Coupon
+	if v.CouponCode != "" {
+		if coupon = s.findCoupon(v.CouponCode); coupon == nil {
+			errs.add("subscription.coupon_code", "invalid", "is invalid")
+		} else if symbol, desc := s.checkRedeemable(coupon, sub.AccountCode); symbol != "" {
+			errs.add("subscription.coupon_code", symbol, desc)
+		}
+	}
+	if errs.write(c) {
+		return
+	}
+
+	now := s.now()
+	if v.Timeframe == "renewal" || v.Timeframe == "bill_date" {
+		sub.PendingSubscription = &recurly.PendingSubscription{
+			XMLName:            xml.Name{Local: "pending_subscription"},
+			Plan:               next.Plan,
+			UnitAmountInCents:  next.UnitAmountInCents,
+			Quantity:           next.Quantity,
+			SubscriptionAddOns: next.SubscriptionAddOns,
+		}
+	} else {
+		a := s.findAccount(sub.AccountCode)
+		delta := subscriptionTotal(&next) - sub.TotalAmountInCents
+		if sub.State == recurly.SubscriptionStateActive && !s.inTrial(sub) && delta != 0 {
+			start, end := sub.CurrentPeriodStartedAt.Time(), sub.CurrentPeriodEndsAt.Time()
+			prorated := int(int64(delta) * int64(end.Sub(now)) / int64(end.Sub(start)))
+
+			adj := s.newAdjustment(sub.AccountCode, sub.Currency, next.Plan.Name, "plan", prorated, 1)
+			adj.ProductCode, adj.SubscriptionUUID = next.Plan.Code, sub.UUID
+			adj.EndDate = sub.CurrentPeriodEndsAt
+			if prorated > 0 {
+				if t, ok := s.chargeSubscription(a, sub, []*recurly.Adjustment{adj}, recurly.ChargeInvoiceOriginImmediateChange, nil); !ok {
+					c.declined(t)
+					return
+				}
+			} else if prorated < 0 {
+				adj.Origin = "credit"
+				s.adjustments = append(s.adjustments, adj)
+			}
+		}
+
+		sub.Plan = next.Plan
+		sub.UnitAmountInCents = next.UnitAmountInCents
+		sub.Quantity = next.Quantity
+		sub.SubscriptionAddOns = next.SubscriptionAddOns
+		sub.TotalAmountInCents = subscriptionTotal(&sub.Subscription)
+		sub.PendingSubscription = nil
+		if plan != nil {
+			sub.intervalUnit, sub.intervalLength = plan.IntervalUnit, plan.IntervalLength
+		}
+	}
+
+	if v.CollectionMethod != "" {
+		sub.CollectionMethod = v.CollectionMethod
+	}
+	if hasInt(v.NetTerms) {
+		sub.NetTerms = v.NetTerms
+	}
+	if v.PONumber != "" {
+		sub.PONumber = v.PONumber
+	}
+	if hasBool(v.AutoRenew) {
+		sub.AutoRenew = v.AutoRenew.Bool()
+	}
+	if hasInt(v.RemainingBillingCycles) {
+		sub.RemainingBillingCycles = v.RemainingBillingCycles
+	}
+	if hasInt(v.RenewalBillingCycles) {
+		sub.RenewalBillingCycles = v.RenewalBillingCycles
+	}
+	if v.CustomFields != nil {
+		sub.CustomFields = v.CustomFields
+	}
+	if coupon != nil {
+		s.addRedemption(s.newRedemption(coupon, sub.AccountCode, sub.Currency, sub.UUID))
+	}
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+// cancelSubscription cancels a subscription at the end of its billing
+// period.
+func (s *Server) cancelSubscription(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	} else if sub.State != recurly.SubscriptionStateActive {
+		c.invalidTransition("Only active subscriptions can be canceled.")
+		return
+	}
+
+	sub.State = recurly.SubscriptionStateCanceled
+	sub.CanceledAt = recurly.NewTime(s.now())
+	sub.ExpiresAt = sub.CurrentPeriodEndsAt
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+func (s *Server) reactivateSubscription(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	} else if sub.State != recurly.SubscriptionStateCanceled {
+		c.invalidTransition("Only canceled subscriptions can be reactivated.")
+		return
+	}
+
+	sub.State = recurly.SubscriptionStateActive
+	sub.CanceledAt, sub.ExpiresAt = recurly.NullTime{}, recurly.NullTime{}
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+// terminateSubscription expires a subscription immediately, optionally
+// refunding the last payment in full or for the unused part of the billing
+// period.
+func (s *Server) terminateSubscription(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	} else if sub.State == recurly.SubscriptionStateExpired {
+		c.invalidTransition("The subscription has already expired.")
+		return
+	}
+
+	refund := c.r.URL.Query().Get("refund")
+	if refund == "" {
+		refund = "none"
+	} else if refund != "none" && refund != "partial" && refund != "full" {
+		validationErrors{{Field: "refund", Symbol: "invalid", Description: "is not included in the list"}}.write(c)
+		return
+	}
+
+	now := s.now()
+	if refund != "none" {
+		for _, t := range s.transactions {
+			if t.InvoiceNumber != sub.InvoiceNumber || t.Action != "purchase" || t.Status != recurly.TransactionStatusSuccess || !t.Refundable.Bool() {
+				continue
+			}
+
+			refunded := t.AmountInCents
+			if start, end := sub.CurrentPeriodStartedAt.Time(), sub.CurrentPeriodEndsAt.Time(); refund == "partial" && end.After(now) {
+				refunded = int(int64(refunded) * int64(end.Sub(now)) / int64(end.Sub(start)))
+			} else if refund == "partial" {
+				refunded = 0
+			}
+			if refunded > 0 {
+				r := s.newTransaction(s.findAccount(sub.AccountCode), "refund", refunded, t.Currency)
+				r.InvoiceNumber, r.OriginalTransactionUUID = t.InvoiceNumber, t.UUID
+				r.Voidable, r.Refundable = recurly.NewBool(false), recurly.NewBool(false)
+				t.Refundable, t.Voidable = recurly.NewBool(false), recurly.NewBool(false)
+			}
+		}
+	}
+
+	sub.State = recurly.SubscriptionStateExpired
+	sub.ExpiresAt = recurly.NewTime(now)
+	if !hasTime(sub.CanceledAt) {
+		sub.CanceledAt = recurly.NewTime(now)
+	}
+	sub.CurrentPeriodEndsAt = recurly.NewTime(now)
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+// pauseSubscription schedules a pause at the end of the billing period for
+// remaining_pause_cycles billing periods. Zero cycles removes a scheduled
+// pause.
+func (s *Server) pauseSubscription(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	} else if sub.State != recurly.SubscriptionStateActive && sub.State != recurly.SubscriptionStatePaused {
+		c.invalidTransition("Only active or paused subscriptions can be paused.")
+		return
+	}
+
+	var v struct {
+		XMLName              xml.Name `xml:"subscription"`
+		RemainingPauseCycles int      `xml:"remaining_pause_cycles"`
+	}
+	if !c.decode(&v) {
+		return
+	} else if v.RemainingPauseCycles < 0 {
+		validationErrors{{Field: "subscription.remaining_pause_cycles", Symbol: "invalid", Description: "must be greater than or equal to 0"}}.write(c)
+		return
+	}
+
+	switch {
+	case v.RemainingPauseCycles == 0 && sub.State == recurly.SubscriptionStateActive:
+		sub.PausedAt, sub.ResumeAt, sub.RemainingPauseCycles = recurly.NullTime{}, recurly.NullTime{}, 0
+	case sub.State == recurly.SubscriptionStateActive:
+		sub.PausedAt = sub.CurrentPeriodEndsAt
+		fallthrough
+	default:
+		sub.RemainingPauseCycles = v.RemainingPauseCycles
+		sub.ResumeAt = recurly.NewTime(addInterval(sub.PausedAt.Time(), sub.intervalUnit, sub.intervalLength*v.RemainingPauseCycles))
+	}
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+// resumeSubscription resumes a paused subscription immediately, starting and
+// charging a new billing period, or removes a scheduled pause.
+func (s *Server) resumeSubscription(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	}
+
+	switch {
+	case sub.State == recurly.SubscriptionStatePaused:
+		now := s.now()
+		prev := sub.Subscription
+		sub.State = recurly.SubscriptionStateActive
+		s.startPeriod(sub, now, addInterval(now, sub.intervalUnit, sub.intervalLength))
+		useBillingCycle(sub)
+		a := s.findAccount(sub.AccountCode)
+		if t, ok := s.chargeSubscription(a, sub, s.subscriptionCharges(sub), recurly.ChargeInvoiceOriginRenewal, s.activeRedemptions(a.Code, sub.UUID)); !ok {
+			sub.Subscription = prev
+			c.declined(t)
+			return
+		}
+	case sub.State == recurly.SubscriptionStateActive && hasTime(sub.PausedAt):
+	default:
+		c.invalidTransition("Only paused subscriptions can be resumed.")
+		return
+	}
+
+	sub.PausedAt, sub.ResumeAt, sub.RemainingPauseCycles = recurly.NullTime{}, recurly.NullTime{}, 0
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+// postponeSubscription moves the next renewal date of a subscription.
+func (s *Server) postponeSubscription(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	} else if sub.State == recurly.SubscriptionStateExpired {
+		c.invalidTransition("Expired subscriptions cannot be postponed.")
+		return
+	}
+
+	date, err := time.Parse(recurly.DateTimeFormat, c.r.URL.Query().Get("next_renewal_date"))
+	if err != nil || !date.After(s.now()) {
+		validationErrors{{Field: "subscription.next_renewal_date", Symbol: "invalid", Description: "must be in the future"}}.write(c)
+		return
+	}
+
+	d := recurly.NewTime(date.UTC())
+	switch {
+	case sub.State == recurly.SubscriptionStateFuture:
+		sub.ActivatedAt = d
+	case s.inTrial(sub):
+		sub.TrialEndsAt = d
+	}
+	if sub.State == recurly.SubscriptionStateCanceled {
+		sub.ExpiresAt = d
+	}
+	if hasTime(sub.CurrentPeriodEndsAt) {
+		sub.CurrentPeriodEndsAt = d
+	}
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+// convertTrial ends the trial of a subscription and charges the first
+// billing period.
+func (s *Server) convertTrial(c *call) {
+	sub := s.lookupSubscription(c)
+	if sub == nil {
+		return
+	} else if sub.State != recurly.SubscriptionStateActive || !s.inTrial(sub) {
+		c.invalidTransition("Only subscriptions in a trial can be converted.")
+		return
+	}
+
+	now := s.now()
+	prev := sub.Subscription
+	sub.TrialEndsAt = recurly.NewTime(now)
+	s.startPeriod(sub, now, addInterval(now, sub.intervalUnit, sub.intervalLength))
+	useBillingCycle(sub)
+	a := s.findAccount(sub.AccountCode)
+	if t, ok := s.chargeSubscription(a, sub, s.subscriptionCharges(sub), recurly.ChargeInvoiceOriginPurchase, s.activeRedemptions(a.Code, sub.UUID)); !ok {
+		sub.Subscription = prev
+		c.declined(t)
+		return
+	}
+	c.write(http.StatusOK, c.subscription(&sub.Subscription))
+}
+
+// addInterval adds length units of days or months to t.
+func addInterval(t time.Time, unit string, length int) time.Time {
+	if unit == "days" {
+		return t.AddDate(0, 0, length)
+	}
+	return t.AddDate(0, length, 0)
+}
@@ -0,0 +1,278 @@
+package recurlytest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/recurlytest"
+)
+
+func TestServer_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("OK", func(t *testing.T) {
+		s := NewTestServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+
+		billing := TestCard
+		sub, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode: "gold",
+			Currency: "USD",
+			Quantity: 2,
+			Account:  recurly.Account{Code: "1", BillingInfo: &billing},
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if sub.State != recurly.SubscriptionStateActive || sub.AccountCode != "1" || sub.TotalAmountInCents != 2000 {
+			t.Fatalf("unexpected subscription: %#v", sub)
+		} else if !sub.CurrentPeriodEndsAt.Time().Equal(Now.AddDate(0, 1, 0)) {
+			t.Fatalf("unexpected period end: %s", sub.CurrentPeriodEndsAt)
+		}
+
+		inv, err := client.Invoices.Get(ctx, sub.InvoiceNumber)
+		if err != nil {
+			t.Fatal(err)
+		} else if inv.State != recurly.ChargeInvoiceStatePaid || inv.TotalInCents != 2000 || inv.Origin != recurly.ChargeInvoiceOriginPurchase {
+			t.Fatalf("unexpected invoice: %#v", inv)
+		} else if len(inv.LineItems) != 1 || inv.LineItems[0].SubscriptionUUID != sub.UUID || inv.LineItems[0].Quantity != 2 {
+			t.Fatalf("unexpected line items: %#v", inv.LineItems)
+		} else if len(inv.Transactions) != 1 || inv.Transactions[0].Status != recurly.TransactionStatusSuccess || inv.Transactions[0].AmountInCents != 2000 {
+			t.Fatalf("unexpected transactions: %#v", inv.Transactions)
+		}
+
+		if a, err := client.Accounts.Get(ctx, "1"); err != nil {
+			t.Fatal(err)
+		} else if !a.HasActiveSubscription.Bool() || a.BillingInfo == nil {
+			t.Fatalf("unexpected account: %#v", a)
+		}
+	})
+
+	t.Run("ErrDeclined", func(t *testing.T) {
+		s := NewTestServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+
+		billing := TestCard
+		billing.Number = recurlytest.CardDeclinedOnCharge
+		if _, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode: "gold",
+			Currency: "USD",
+			Account:  recurly.Account{Code: "1", BillingInfo: &billing},
+		}); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.TransactionFailedError); !ok || e.Transaction.AmountInCents != 1000 {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		// Ensure only the declined transaction is recorded.
+		var transactions []recurly.Transaction
+		if a, err := client.Accounts.Get(ctx, "1"); err != nil {
+			t.Fatal(err)
+		} else if a != nil {
+			t.Fatalf("unexpected account: %#v", a)
+		} else if err := client.Transactions.List(nil).FetchAll(ctx, &transactions); err != nil {
+			t.Fatal(err)
+		} else if len(transactions) != 1 || transactions[0].Status != recurlytest.TransactionStatusDeclined || transactions[0].Account.Code != "1" {
+			t.Fatalf("unexpected transactions: %#v", transactions)
+		} else if n, err := client.Subscriptions.List(nil).Count(ctx); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("unexpected subscriptions: %d", n)
+		}
+	})
+
+	t.Run("ErrValidation", func(t *testing.T) {
+		s := NewTestServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+
+		if _, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode: "silver",
+			Currency: "EUR",
+			Account:  recurly.Account{Code: "1"},
+		}); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.ClientError); !ok || len(e.ValidationErrors) != 1 || e.ValidationErrors[0].Field != "subscription.plan_code" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if _, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode: "gold",
+			Currency: "USD",
+			Account:  recurly.Account{Code: "1"},
+		}); err == nil {
+			t.Fatal("expected error")
+		} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("billing_info_required") {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("Trial", func(t *testing.T) {
+		s := NewTestServer()
+		client := s.Client()
+		if _, err := client.Plans.Create(ctx, recurly.Plan{
+			Code:                "gold",
+			Name:                "Gold",
+			UnitAmountInCents:   recurly.UnitAmount{USD: 1000},
+			TrialIntervalUnit:   "days",
+			TrialIntervalLength: 14,
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		billing := TestCard
+		sub, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode: "gold",
+			Currency: "USD",
+			Account:  recurly.Account{Code: "1", BillingInfo: &billing},
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if sub.InvoiceNumber != 0 || !sub.TrialEndsAt.Time().Equal(Now.AddDate(0, 0, 14)) || !sub.CurrentPeriodEndsAt.Time().Equal(sub.TrialEndsAt.Time()) {
+			t.Fatalf("unexpected subscription: %#v", sub)
+		}
+
+		var subs []recurly.Subscription
+		if err := client.Subscriptions.List(&recurly.PagerOptions{State: recurly.SubscriptionStateInTrial}).FetchAll(ctx, &subs); err != nil {
+			t.Fatal(err)
+		} else if len(subs) != 1 {
+			t.Fatalf("unexpected subscriptions: %d", len(subs))
+		}
+
+		if sub, err := client.Subscriptions.ConvertTrial(ctx, sub.UUID); err != nil {
+			t.Fatal(err)
+		} else if sub.InvoiceNumber == 0 || !sub.TrialEndsAt.Time().Equal(Now) || !sub.CurrentPeriodEndsAt.Time().Equal(Now.AddDate(0, 1, 0)) {
+			t.Fatalf("unexpected subscription: %#v", sub)
+		}
+	})
+
+	t.Run("Manual", func(t *testing.T) {
+		s := NewTestServer()
+		client := s.Client()
+		MustCreatePlan(t, client, "gold", 1000)
+
+		sub, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+			PlanCode:         "gold",
+			Currency:         "USD",
+			CollectionMethod: recurly.CollectionMethodManual,
+			NetTerms:         recurly.NewInt(30),
+			Account:          recurly.Account{Code: "1"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if inv, err := client.Invoices.Get(ctx, sub.InvoiceNumber); err != nil {
+			t.Fatal(err)
+		} else if inv.State != recurly.ChargeInvoiceStatePending || inv.BalanceInCents != 1000 || !inv.DueOn.Time().Equal(Now.AddDate(0, 0, 30)) {
+			t.Fatalf("unexpected invoice: %#v", inv)
+		} else if len(inv.Transactions) != 0 {
+			t.Fatalf("unexpected transactions: %#v", inv.Transactions)
+		}
+	})
+}
+
+func TestServer_SubscriptionTransitions(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+	MustCreatePlan(t, client, "gold", 3000)
+
+	billing := TestCard
+	sub, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+		PlanCode: "gold",
+		Currency: "USD",
+		Account:  recurly.Account{Code: "1", BillingInfo: &billing},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	periodEnd := sub.CurrentPeriodEndsAt.Time()
+
+	if sub, err := client.Subscriptions.Cancel(ctx, sub.UUID); err != nil {
+		t.Fatal(err)
+	} else if sub.State != recurly.SubscriptionStateCanceled || !sub.ExpiresAt.Time().Equal(periodEnd) {
+		t.Fatalf("unexpected subscription: %#v", sub)
+	} else if _, err := client.Subscriptions.Cancel(ctx, sub.UUID); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(*recurly.ClientError); !ok || !e.Is("invalid_transition") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sub, err := client.Subscriptions.Reactivate(ctx, sub.UUID); err != nil {
+		t.Fatal(err)
+	} else if sub.State != recurly.SubscriptionStateActive || sub.ExpiresAt.Time() != (time.Time{}) {
+		t.Fatalf("unexpected subscription: %#v", sub)
+	}
+
+	if sub, err := client.Subscriptions.Pause(ctx, sub.UUID, 2); err != nil {
+		t.Fatal(err)
+	} else if !sub.PausedAt.Time().Equal(periodEnd) || !sub.ResumeAt.Time().Equal(periodEnd.AddDate(0, 2, 0)) || sub.RemainingPauseCycles != 2 {
+		t.Fatalf("unexpected subscription: %#v", sub)
+	} else if sub, err := client.Subscriptions.Resume(ctx, sub.UUID); err != nil {
+		t.Fatal(err)
+	} else if sub.PausedAt.Time() != (time.Time{}) || sub.State != recurly.SubscriptionStateActive {
+		t.Fatalf("unexpected subscription: %#v", sub)
+	}
+
+	next := periodEnd.AddDate(0, 0, 7)
+	if sub, err := client.Subscriptions.Postpone(ctx, sub.UUID, next, false); err != nil {
+		t.Fatal(err)
+	} else if !sub.CurrentPeriodEndsAt.Time().Equal(next) {
+		t.Fatalf("unexpected subscription: %#v", sub)
+	}
+
+	if sub, err := client.Subscriptions.Terminate(ctx, sub.UUID, "full"); err != nil {
+		t.Fatal(err)
+	} else if sub.State != recurly.SubscriptionStateExpired || !sub.ExpiresAt.Time().Equal(Now) {
+		t.Fatalf("unexpected subscription: %#v", sub)
+	}
+
+	var refunds []recurly.Transaction
+	if err := client.Transactions.ListAccount("1", &recurly.PagerOptions{Type: "refund"}).FetchAll(ctx, &refunds); err != nil {
+		t.Fatal(err)
+	} else if len(refunds) != 1 || refunds[0].AmountInCents != 3000 || refunds[0].OriginalTransactionUUID == "" {
+		t.Fatalf("unexpected refunds: %#v", refunds)
+	}
+}
+
+func TestServer_UpdateSubscription(t *testing.T) {
+	ctx := context.Background()
+	s := NewTestServer()
+	client := s.Client()
+	MustCreatePlan(t, client, "silver", 1000)
+	MustCreatePlan(t, client, "gold", 3000)
+
+	billing := TestCard
+	sub, err := client.Subscriptions.Create(ctx, recurly.NewSubscription{
+		PlanCode: "silver",
+		Currency: "USD",
+		Account:  recurly.Account{Code: "1", BillingInfo: &billing},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure changes at renewal are pending.
+	if sub, err := client.Subscriptions.Update(ctx, sub.UUID, recurly.UpdateSubscription{Timeframe: "renewal", PlanCode: "gold"}); err != nil {
+		t.Fatal(err)
+	} else if sub.Plan.Code != "silver" || sub.PendingSubscription == nil || sub.PendingSubscription.Plan.Code != "gold" {
+		t.Fatalf("unexpected subscription: %#v", sub)
+	}
+
+	// Ensure changes now are prorated. Half of the billing period remains.
+	s.Now = func() time.Time { return Now.Add(sub.CurrentPeriodEndsAt.Time().Sub(Now) / 2) }
+	updated, err := client.Subscriptions.Update(ctx, sub.UUID, recurly.UpdateSubscription{Timeframe: "now", PlanCode: "gold"})
+	if err != nil {
+		t.Fatal(err)
+	} else if updated.Plan.Code != "gold" || updated.UnitAmountInCents != 3000 || updated.PendingSubscription != nil {
+		t.Fatalf("unexpected subscription: %#v", updated)
+	} else if updated.InvoiceNumber == sub.InvoiceNumber {
+		t.Fatal("expected proration invoice")
+	} else if inv, err := client.Invoices.Get(ctx, updated.InvoiceNumber); err != nil {
+		t.Fatal(err)
+	} else if inv.Origin != recurly.ChargeInvoiceOriginImmediateChange || inv.TotalInCents != 1000 || inv.State != recurly.ChargeInvoiceStatePaid {
+		t.Fatalf("unexpected invoice: %#v", inv)
+	}
+}
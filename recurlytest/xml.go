@@ -0,0 +1,258 @@
+package recurlytest
+
+import (
+	"encoding/xml"
+	"net"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// href links a resource to another resource.
+type href struct {
+	HREF string `xml:"href,attr"`
+}
+
+// The response types below render resources with the links Recurly
+// includes in responses, which the recurly package types read but do not
+// write.
+
+type subscriptionAlias recurly.Subscription
+
+type subscriptionXML struct {
+	XMLName xml.Name `xml:"subscription"`
+	subscriptionAlias
+	Account *href `xml:"account,omitempty"`
+	Invoice *href `xml:"invoice,omitempty"`
+}
+
+func (c *call) subscription(sub *recurly.Subscription) subscriptionXML {
+	v := subscriptionXML{
+		subscriptionAlias: subscriptionAlias(*sub),
+		Account:           c.href("accounts/%s", sub.AccountCode),
+	}
+	if sub.InvoiceNumber > 0 {
+		v.Invoice = c.href("invoices/%d", sub.InvoiceNumber)
+	}
+	return v
+}
+
+type adjustmentAlias recurly.Adjustment
+
+type adjustmentXML struct {
+	XMLName xml.Name `xml:"adjustment"`
+	adjustmentAlias
+	Account      *href `xml:"account,omitempty"`
+	Invoice      *href `xml:"invoice,omitempty"`
+	Subscription *href `xml:"subscription,omitempty"`
+}
+
+func (c *call) adjustment(a *recurly.Adjustment) adjustmentXML {
+	v := adjustmentXML{
+		adjustmentAlias: adjustmentAlias(*a),
+		Account:         c.href("accounts/%s", a.AccountCode),
+	}
+	if a.InvoiceNumber > 0 {
+		v.Invoice = c.href("invoices/%d", a.InvoiceNumber)
+	}
+	if a.SubscriptionUUID != "" {
+		v.Subscription = c.href("subscriptions/%s", a.SubscriptionUUID)
+	}
+	return v
+}
+
+type transactionXML struct {
+	XMLName             xml.Name                  `xml:"transaction"`
+	Invoice             *href                     `xml:"invoice,omitempty"`
+	OriginalTransaction *href                     `xml:"original_transaction,omitempty"`
+	UUID                string                    `xml:"uuid"`
+	Action              string                    `xml:"action,omitempty"`
+	AmountInCents       int                       `xml:"amount_in_cents"`
+	TaxInCents          int                       `xml:"tax_in_cents"`
+	Currency            string                    `xml:"currency"`
+	Status              string                    `xml:"status,omitempty"`
+	Description         string                    `xml:"description,omitempty"`
+	PaymentMethod       string                    `xml:"payment_method,omitempty"`
+	Reference           string                    `xml:"reference,omitempty"`
+	Source              string                    `xml:"source,omitempty"`
+	Recurring           recurly.NullBool          `xml:"recurring,omitempty"`
+	Test                bool                      `xml:"test"`
+	Voidable            recurly.NullBool          `xml:"voidable,omitempty"`
+	Refundable          recurly.NullBool          `xml:"refundable,omitempty"`
+	IPAddress           net.IP                    `xml:"ip_address,omitempty"`
+	TransactionError    *recurly.TransactionError `xml:"transaction_error,omitempty"`
+	CreatedAt           recurly.NullTime          `xml:"created_at,omitempty"`
+	Account             recurly.Account           `xml:"details>account"`
+	GatewayType         string                    `xml:"gateway_type,omitempty"`
+	Origin              string                    `xml:"origin,omitempty"`
+	Message             string                    `xml:"message,omitempty"`
+	ApprovalCode        string                    `xml:"approval_code,omitempty"`
+}
+
+func (c *call) transaction(t *recurly.Transaction) transactionXML {
+	v := transactionXML{
+		UUID:             t.UUID,
+		Action:           t.Action,
+		AmountInCents:    t.AmountInCents,
+		TaxInCents:       t.TaxInCents,
+		Currency:         t.Currency,
+		Status:           t.Status,
+		Description:      t.Description,
+		PaymentMethod:    t.PaymentMethod,
+		Reference:        t.Reference,
+		Source:           t.Source,
+		Recurring:        t.Recurring,
+		Test:             t.Test,
+		Voidable:         t.Voidable,
+		Refundable:       t.Refundable,
+		IPAddress:        t.IPAddress,
+		TransactionError: t.TransactionError,
+		CreatedAt:        t.CreatedAt,
+		Account:          t.Account,
+		GatewayType:      t.GatewayType,
+		Origin:           t.Origin,
+		Message:          t.Message,
+		ApprovalCode:     t.ApprovalCode,
+	}
+	if t.InvoiceNumber > 0 {
+		v.Invoice = c.href("invoices/%d", t.InvoiceNumber)
+	}
+	if t.OriginalTransactionUUID != "" {
+		v.OriginalTransaction = c.href("transactions/%s", t.OriginalTransactionUUID)
+	}
+	return v
+}
+
+type invoiceXML struct {
+	XMLName                 xml.Name
+	Account                 *href            `xml:"account,omitempty"`
+	Address                 recurly.Address  `xml:"address"`
+	Subscription            *href            `xml:"subscription,omitempty"`
+	UUID                    string           `xml:"uuid"`
+	State                   string           `xml:"state"`
+	InvoiceNumberPrefix     string           `xml:"invoice_number_prefix"`
+	InvoiceNumber           int              `xml:"invoice_number"`
+	PONumber                string           `xml:"po_number,omitempty"`
+	VATNumber               string           `xml:"vat_number,omitempty"`
+	DiscountInCents         int              `xml:"discount_in_cents"`
+	SubtotalInCents         int              `xml:"subtotal_in_cents"`
+	TaxInCents              int              `xml:"tax_in_cents"`
+	TotalInCents            int              `xml:"total_in_cents"`
+	BalanceInCents          int              `xml:"balance_in_cents"`
+	Currency                string           `xml:"currency"`
+	DueOn                   recurly.NullTime `xml:"due_on,omitempty"`
+	CreatedAt               recurly.NullTime `xml:"created_at,omitempty"`
+	UpdatedAt               recurly.NullTime `xml:"updated_at,omitempty"`
+	AttemptNextCollectionAt recurly.NullTime `xml:"attempt_next_collection_at,omitempty"`
+	ClosedAt                recurly.NullTime `xml:"closed_at,omitempty"`
+	Type                    string           `xml:"type"`
+	Origin                  string           `xml:"origin"`
+	NetTerms                recurly.NullInt  `xml:"net_terms,omitempty"`
+	CollectionMethod        string           `xml:"collection_method"`
+	LineItems               []adjustmentXML  `xml:"line_items>adjustment"`
+	Transactions            []transactionXML `xml:"transactions>transaction"`
+}
+
+// invoice renders inv in an element named name.
+func (c *call) invoice(name string, inv *recurly.Invoice) invoiceXML {
+	v := invoiceXML{
+		XMLName:                 xml.Name{Local: name},
+		Account:                 c.href("accounts/%s", inv.AccountCode),
+		Address:                 inv.Address,
+		UUID:                    inv.UUID,
+		State:                   inv.State,
+		InvoiceNumberPrefix:     inv.InvoiceNumberPrefix,
+		InvoiceNumber:           inv.InvoiceNumber,
+		PONumber:                inv.PONumber,
+		VATNumber:               inv.VATNumber,
+		DiscountInCents:         inv.DiscountInCents,
+		SubtotalInCents:         inv.SubtotalInCents,
+		TaxInCents:              inv.TaxInCents,
+		TotalInCents:            inv.TotalInCents,
+		BalanceInCents:          inv.BalanceInCents,
+		Currency:                inv.Currency,
+		DueOn:                   inv.DueOn,
+		CreatedAt:               inv.CreatedAt,
+		UpdatedAt:               inv.UpdatedAt,
+		AttemptNextCollectionAt: inv.AttemptNextCollectionAt,
+		ClosedAt:                inv.ClosedAt,
+		Type:                    inv.Type,
+		Origin:                  inv.Origin,
+		NetTerms:                inv.NetTerms,
+		CollectionMethod:        inv.CollectionMethod,
+	}
+	for i := range inv.LineItems {
+		v.LineItems = append(v.LineItems, c.adjustment(&inv.LineItems[i]))
+		if uuid := inv.LineItems[i].SubscriptionUUID; uuid != "" && v.Subscription == nil {
+			v.Subscription = c.href("subscriptions/%s", uuid)
+		}
+	}
+	for i := range inv.Transactions {
+		v.Transactions = append(v.Transactions, c.transaction(&inv.Transactions[i]))
+	}
+	return v
+}
+
+type invoiceCollectionXML struct {
+	XMLName        xml.Name     `xml:"invoice_collection"`
+	ChargeInvoice  invoiceXML   `xml:"charge_invoice"`
+	CreditInvoices []invoiceXML `xml:"credit_invoices>credit_invoice"`
+}
+
+type redemptionXML struct {
+	XMLName                xml.Name         `xml:"redemption"`
+	Coupon                 *href            `xml:"coupon"`
+	Account                *href            `xml:"account"`
+	Subscription           *href            `xml:"subscription,omitempty"`
+	UUID                   string           `xml:"uuid"`
+	SingleUse              bool             `xml:"single_use"`
+	CouponCode             string           `xml:"coupon_code"`
+	TotalDiscountedInCents int              `xml:"total_discounted_in_cents"`
+	Currency               string           `xml:"currency"`
+	State                  string           `xml:"state"`
+	CreatedAt              recurly.NullTime `xml:"created_at,omitempty"`
+	UpdatedAt              recurly.NullTime `xml:"updated_at,omitempty"`
+}
+
+func (c *call) redemption(r *recurly.Redemption) redemptionXML {
+	v := redemptionXML{
+		Coupon:                 c.href("coupons/%s", r.CouponCode),
+		Account:                c.href("accounts/%s", r.AccountCode),
+		UUID:                   r.UUID,
+		SingleUse:              r.SingleUse,
+		CouponCode:             r.CouponCode,
+		TotalDiscountedInCents: r.TotalDiscountedInCents,
+		Currency:               r.Currency,
+		State:                  r.State,
+		CreatedAt:              r.CreatedAt,
+		UpdatedAt:              r.UpdatedAt,
+	}
+	if r.SubscriptionUUID != "" {
+		v.Subscription = c.href("subscriptions/%s", r.SubscriptionUUID)
+	}
+	return v
+}
+
+// billing returns b without the write-only card fields.
+func billing(b *recurly.Billing) *recurly.Billing {
+	v := *b
+	v.Number, v.VerificationValue = 0, 0
+	v.Token, v.Currency, v.TransactionType = "", "", ""
+	v.ThreeDSecureActionResultTokenID = ""
+	return &v
+}
+
+// hasInt, hasBool and hasTime return true if n is not null.
+func hasInt(n recurly.NullInt) bool {
+	_, ok := n.Value()
+	return ok
+}
+
+func hasBool(n recurly.NullBool) bool {
+	_, ok := n.Value()
+	return ok
+}
+
+func hasTime(n recurly.NullTime) bool {
+	_, ok := n.Value()
+	return ok
+}
@@ -124,6 +124,7 @@ type Subscription struct {
 	XMLName                xml.Name             `xml:"subscription"`
 	Plan                   NestedPlan           `xml:"plan,omitempty"`
 	AccountCode            string               `xml:"-"`
+	BillForAccountCode     string               `xml:"-"` // Account responsible for payment (parent account for child accounts)
 	InvoiceNumber          int                  `xml:"-"`
 	UUID                   string               `xml:"uuid,omitempty"`
 	State                  string               `xml:"state,omitempty"`
@@ -202,9 +203,10 @@ func (s *Subscription) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 	type subscriptionAlias Subscription
 	var v struct {
 		subscriptionAlias
-		XMLName       xml.Name `xml:"subscription"`
-		AccountCode   href     `xml:"account"`
-		InvoiceNumber href     `xml:"invoice"`
+		XMLName            xml.Name `xml:"subscription"`
+		AccountCode        href     `xml:"account"`
+		BillForAccountCode href     `xml:"bill_for_account"`
+		InvoiceNumber      href     `xml:"invoice"`
 	}
 	if err := d.DecodeElement(&v, &start); err != nil {
 		return err
@@ -213,6 +215,7 @@ func (s *Subscription) UnmarshalXML(d *xml.Decoder, start xml.StartElement) erro
 	*s = Subscription(v.subscriptionAlias)
 	s.XMLName = v.XMLName
 	s.AccountCode = v.AccountCode.LastPartOfPath()
+	s.BillForAccountCode = v.BillForAccountCode.LastPartOfPath()
 	s.InvoiceNumber, _ = strconv.Atoi(v.InvoiceNumber.LastPartOfPath())
 	return nil
 }
@@ -231,6 +234,9 @@ type SubscriptionAddOn struct {
 	UnitAmountInCents NullInt  `xml:"unit_amount_in_cents,omitempty"`
 	Quantity          int      `xml:"quantity,omitempty"`
 	AddOnSource       string   `xml:"add_on_source,omitempty"`
+	UsageType         string   `xml:"usage_type,omitempty"`
+	UsagePercentage   float64  `xml:"usage_percentage,omitempty"`
+	MeasuredUnitID    int64    `xml:"measured_unit_id,omitempty"`
 }
 
 // PendingSubscription are updates to the subscription or subscription add ons that
@@ -89,6 +89,36 @@ func TestSubscriptions_NewSubscription_Encoding(t *testing.T) {
 				</subscription>
 			`),
 		},
+		{
+			v: recurly.NewSubscription{
+				PlanCode: "gold",
+				Currency: "USD",
+				Account: recurly.Account{
+					Code: "123",
+				},
+				SubscriptionAddOns: &[]recurly.SubscriptionAddOn{
+					{
+						Code:            "storage",
+						UsagePercentage: 2.5,
+					},
+				},
+			},
+			expected: MustCompactString(`
+				<subscription>
+					<plan_code>gold</plan_code>
+					<account>
+						<account_code>123</account_code>
+					</account>
+					<subscription_add_ons>
+						<subscription_add_on>
+							<add_on_code>storage</add_on_code>
+							<usage_percentage>2.5</usage_percentage>
+						</subscription_add_on>
+					</subscription_add_ons>
+					<currency>USD</currency>
+				</subscription>
+			`),
+		},
 		{
 			v: recurly.NewSubscription{
 				PlanCode: "gold",
@@ -770,6 +800,36 @@ func TestSubscriptions_Get(t *testing.T) {
 		}
 	})
 
+	// Subscriptions on child accounts billed to their parent reference the
+	// paying account separately.
+	t.Run("ChildAccount", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/subscriptions/44f83d7cba354d5b84812419f923ea96", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustCompact([]byte(`
+				<?xml version="1.0" encoding="UTF-8"?>
+				<subscription href="https://your-subdomain.recurly.com/v2/subscriptions/44f83d7cba354d5b84812419f923ea96">
+					<account href="https://your-subdomain.recurly.com/v2/accounts/2" />
+					<bill_for_account href="https://your-subdomain.recurly.com/v2/accounts/1" />
+					<uuid>44f83d7cba354d5b84812419f923ea96</uuid>
+					<state>active</state>
+				</subscription>
+			`)))
+		}, t)
+
+		if subscription, err := client.Subscriptions.Get(context.Background(), "44f83d7c-ba35-4d5b-8481-2419f923ea96"); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if subscription.AccountCode != "2" {
+			t.Fatalf("unexpected account code: %q", subscription.AccountCode)
+		} else if subscription.BillForAccountCode != "1" {
+			t.Fatalf("unexpected bill for account code: %q", subscription.BillForAccountCode)
+		}
+	})
+
 	// Ensure a 404 returns nil values.
 	t.Run("ErrNotFound", func(t *testing.T) {
 		client, s := recurly.NewTestServer()
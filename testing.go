@@ -1,14 +1,25 @@
 package recurly
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/blacklightcms/recurly/internal/xmlcanon"
 )
 
 // TestServer is a server used for testing when mocks are not sufficient.
@@ -18,6 +29,14 @@ type TestServer struct {
 	server *httptest.Server
 	mux    *http.ServeMux
 
+	// FixtureDir is the directory of the files used by RespondFile and
+	// WithBodyFile. Defaults to "testdata".
+	FixtureDir string
+
+	mu           sync.Mutex
+	expectations []*Expectation
+	unexpected   []string
+
 	Invoked bool
 }
 
@@ -25,9 +44,10 @@ type TestServer struct {
 // client resolving to the test server.
 func NewTestServer() (*Client, *TestServer) {
 	s := &TestServer{
-		mux: http.NewServeMux(),
+		mux:        http.NewServeMux(),
+		FixtureDir: "testdata",
 	}
-	s.server = httptest.NewTLSServer(s.mux)
+	s.server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
 
 	client := NewClient("test", "foo")
 	client.Client = &http.Client{
@@ -63,3 +83,254 @@ func (s *TestServer) HandleFunc(method string, pattern string, fn http.HandlerFu
 func (s *TestServer) Close() {
 	s.server.Close()
 }
+
+// Expectation is a request expected by a TestServer and the response
+// served for it. Expectations are created with TestServer.Expect and
+// configured by chaining methods:
+//
+//	s.Expect("PUT", "/v2/accounts/1").
+//		WithAPIVersion().
+//		WithBody(recurly.Account{FirstName: "Verena"}).
+//		RespondFile(http.StatusOK, "account.xml")
+//
+// Expectations are checked with TestServer.Verify.
+type Expectation struct {
+	s      *TestServer
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+	times  int
+
+	status     int
+	respBody   []byte
+	respHeader http.Header
+	nextCursor string
+
+	calls  int
+	errors []string
+}
+
+// Expect returns an expectation of one request with method to path, which
+// is responded to with a 200 and no body. Requests that match an
+// expectation are not passed to handlers registered with HandleFunc.
+func (s *TestServer) Expect(method, path string) *Expectation {
+	e := &Expectation{
+		s:          s,
+		method:     method,
+		path:       path,
+		query:      url.Values{},
+		header:     http.Header{},
+		times:      1,
+		status:     http.StatusOK,
+		respHeader: http.Header{},
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expectations = append(s.expectations, e)
+	return e
+}
+
+// WithQuery expects the query parameter key to be set to value.
+func (e *Expectation) WithQuery(key, value string) *Expectation {
+	e.query.Add(key, value)
+	return e
+}
+
+// WithHeader expects the request header key to be set to value.
+func (e *Expectation) WithHeader(key, value string) *Expectation {
+	e.header.Add(key, value)
+	return e
+}
+
+// WithAPIVersion expects the X-Api-Version header of the client.
+func (e *Expectation) WithAPIVersion() *Expectation {
+	return e.WithHeader("X-Api-Version", apiVersion)
+}
+
+// WithBody expects the request body to be the XML for v. v may be XML as a
+// string or []byte, or a value to marshal. Bodies are compared ignoring
+// formatting and the order of attributes.
+func (e *Expectation) WithBody(v interface{}) *Expectation {
+	switch v := v.(type) {
+	case string:
+		e.body = []byte(v)
+	case []byte:
+		e.body = v
+	default:
+		b, err := xml.Marshal(v)
+		if err != nil {
+			e.errorf("cannot marshal expected body: %v", err)
+		}
+		e.body = b
+	}
+	return e
+}
+
+// WithBodyFile expects the request body to be the XML in the fixture file
+// name. See WithBody.
+func (e *Expectation) WithBodyFile(name string) *Expectation {
+	return e.WithBody(e.fixture(name))
+}
+
+// Times expects n requests. A negative n allows any number of requests.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// Respond sets the status and body of the response.
+func (e *Expectation) Respond(status int, body []byte) *Expectation {
+	e.status, e.respBody = status, body
+	return e
+}
+
+// RespondFile responds with status and the fixture file name as the body.
+func (e *Expectation) RespondFile(status int, name string) *Expectation {
+	return e.Respond(status, e.fixture(name))
+}
+
+// RespondHeader sets the response header key to value.
+func (e *Expectation) RespondHeader(key, value string) *Expectation {
+	e.respHeader.Add(key, value)
+	return e
+}
+
+// RespondNextCursor sets a Link header with the next page at cursor.
+func (e *Expectation) RespondNextCursor(cursor string) *Expectation {
+	e.nextCursor = cursor
+	return e
+}
+
+// RespondRateLimit sets the rate limit headers of the response.
+func (e *Expectation) RespondRateLimit(limit, remaining int, reset time.Time) *Expectation {
+	e.respHeader.Set("X-RateLimit-Limit", strconv.Itoa(limit))
+	e.respHeader.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	e.respHeader.Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+	return e
+}
+
+// fixture returns the contents of the fixture file name.
+func (e *Expectation) fixture(name string) []byte {
+	b, err := ioutil.ReadFile(filepath.Join(e.s.FixtureDir, name))
+	if err != nil {
+		e.errorf("cannot read fixture: %v", err)
+	}
+	return b
+}
+
+func (e *Expectation) String() string {
+	return e.method + " " + e.path
+}
+
+func (e *Expectation) errorf(format string, args ...interface{}) {
+	e.s.mu.Lock()
+	defer e.s.mu.Unlock()
+	e.errors = append(e.errors, fmt.Sprintf(format, args...))
+}
+
+// mismatch returns the reason r does not match e, or an empty string if it
+// matches.
+func (e *Expectation) mismatch(r *http.Request, body []byte) string {
+	q := r.URL.Query()
+	for key, values := range e.query {
+		if got := q[key]; !reflect.DeepEqual(got, values) {
+			return fmt.Sprintf("query %s: got %q, want %q", key, got, values)
+		}
+	}
+	for key, values := range e.header {
+		if got := r.Header[key]; !reflect.DeepEqual(got, values) {
+			return fmt.Sprintf("header %s: got %q, want %q", key, got, values)
+		}
+	}
+	if e.body != nil {
+		got, err := xmlcanon.Canonical(body)
+		if err != nil {
+			return fmt.Sprintf("body: %v", err)
+		}
+		want, err := xmlcanon.Canonical(e.body)
+		if err != nil {
+			return fmt.Sprintf("expected body: %v", err)
+		} else if got != want {
+			return fmt.Sprintf("body: got %s, want %s", got, want)
+		}
+	}
+	return ""
+}
+
+// serveHTTP serves requests with the first matching expectation that has
+// not been exhausted, or with the handlers registered with HandleFunc.
+func (s *TestServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	s.mu.Lock()
+	var match *Expectation
+	var reasons []string
+	for _, e := range s.expectations {
+		if e.method != r.Method || e.path != r.URL.Path || (e.times >= 0 && e.calls >= e.times) {
+			continue
+		} else if reason := e.mismatch(r, body); reason != "" {
+			reasons = append(reasons, e.String()+": "+reason)
+			continue
+		}
+		match = e
+		break
+	}
+	if match != nil {
+		match.calls++
+		s.Invoked = true
+	} else if _, pattern := s.mux.Handler(r); pattern == "" || len(reasons) > 0 {
+		msg := fmt.Sprintf("unexpected request: %s %s", r.Method, r.URL.RequestURI())
+		if len(reasons) > 0 {
+			msg += " (" + strings.Join(reasons, "; ") + ")"
+		}
+		s.unexpected = append(s.unexpected, msg)
+	}
+	s.mu.Unlock()
+
+	if match == nil {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	for key, values := range match.respHeader {
+		w.Header()[key] = values
+	}
+	if match.nextCursor != "" {
+		u := *r.URL
+		u.Scheme, u.Host = "https", r.Host
+		q := u.Query()
+		q.Set("cursor", match.nextCursor)
+		u.RawQuery = q.Encode()
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, u.String()))
+	}
+	if len(match.respBody) > 0 {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	}
+	w.WriteHeader(match.status)
+	w.Write(match.respBody)
+}
+
+// Verify reports unexpected requests, requests that did not match an
+// expectation, and expectations that were not called the expected number
+// of times.
+func (s *TestServer) Verify(t testing.TB) {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, msg := range s.unexpected {
+		t.Errorf("%s", msg)
+	}
+	for _, e := range s.expectations {
+		for _, msg := range e.errors {
+			t.Errorf("%s: %s", e, msg)
+		}
+		if e.times >= 0 && e.calls != e.times {
+			t.Errorf("%s: expected %d request(s), got %d", e, e.times, e.calls)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package recurly_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestTestServer_Expect(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.Expect("GET", "/v2/accounts/1").WithAPIVersion().RespondFile(http.StatusOK, "account.xml")
+	s.Expect("PUT", "/v2/accounts/1").
+		WithBody(`
+			<account>
+				<first_name>Verena</first_name>
+			</account>
+		`).
+		RespondFile(http.StatusOK, "account.xml")
+
+	if a, err := client.Accounts.Get(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	} else if a.Code != "1" {
+		t.Fatalf("unexpected account: %s", a.Code)
+	} else if _, err := client.Accounts.Update(context.Background(), "1", recurly.Account{FirstName: "Verena"}); err != nil {
+		t.Fatal(err)
+	} else if !s.Invoked {
+		t.Fatal("expected fn invocation")
+	}
+	s.Verify(t)
+}
+
+func TestTestServer_Pagination(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.Expect("GET", "/v2/accounts").
+		WithQuery("per_page", "1").
+		RespondFile(http.StatusOK, "accounts.xml").
+		RespondNextCursor("2")
+	s.Expect("GET", "/v2/accounts").
+		WithQuery("cursor", "2").
+		RespondFile(http.StatusOK, "accounts.xml")
+
+	pager := client.Accounts.List(&recurly.PagerOptions{PerPage: 1})
+	var cursors []string
+	for pager.Next() {
+		var accounts []recurly.Account
+		if err := pager.Fetch(context.Background(), &accounts); err != nil {
+			t.Fatal(err)
+		}
+		cursors = append(cursors, pager.Cursor())
+	}
+	if diff := cmp.Diff(cursors, []string{"2", ""}); diff != "" {
+		t.Fatal(diff)
+	}
+	s.Verify(t)
+}
+
+func TestTestServer_RateLimit(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	reset := time.Date(2019, 4, 1, 12, 0, 0, 0, time.UTC)
+	s.Expect("GET", "/v2/accounts/1").
+		RespondRateLimit(2000, 0, reset).
+		Respond(http.StatusTooManyRequests, nil)
+
+	if _, err := client.Accounts.Get(context.Background(), "1"); err == nil {
+		t.Fatal("expected error")
+	} else if e, ok := err.(*recurly.RateLimitError); !ok {
+		t.Fatalf("unexpected error: %T", err)
+	} else if diff := cmp.Diff(e.Rate, recurly.Rate{Limit: 2000, Remaining: 0, Reset: reset.Local()}); diff != "" {
+		t.Fatal(diff)
+	}
+	s.Verify(t)
+}
+
+func TestTestServer_Verify(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.Expect("PUT", "/v2/accounts/1").
+		WithBody(recurly.Account{FirstName: "Verena"}).
+		RespondFile(http.StatusOK, "account.xml")
+	s.Expect("DELETE", "/v2/accounts/1").Times(2)
+	s.Expect("GET", "/v2/accounts/1").RespondFile(http.StatusOK, "missing.xml")
+
+	client.Accounts.Update(context.Background(), "1", recurly.Account{FirstName: "Larry"})
+	client.Accounts.Close(context.Background(), "1")
+	client.Accounts.Reopen(context.Background(), "1")
+
+	var tb testTB
+	s.Verify(&tb)
+	if diff := cmp.Diff(tb.errors, []string{
+		"unexpected request: PUT /v2/accounts/1 (PUT /v2/accounts/1: body: got <account><first_name>Larry</first_name></account>, want <account><first_name>Verena</first_name></account>)",
+		"unexpected request: PUT /v2/accounts/1/reopen",
+		"PUT /v2/accounts/1: expected 1 request(s), got 0",
+		"DELETE /v2/accounts/1: expected 2 request(s), got 1",
+		"GET /v2/accounts/1: cannot read fixture: open testdata/missing.xml: no such file or directory",
+		"GET /v2/accounts/1: expected 1 request(s), got 0",
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+// testTB records the errors reported by Verify.
+type testTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *testTB) Helper() {}
+
+func (tb *testTB) Errorf(format string, args ...interface{}) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
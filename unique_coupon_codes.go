@@ -0,0 +1,132 @@
+package recurly
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// UniqueCouponCodesService manages the interactions for unique coupon codes
+// generated from bulk coupons.
+type UniqueCouponCodesService interface {
+	// List returns a pager to paginate the unique codes of a bulk coupon.
+	// PagerOptions are used to optionally filter the results (e.g. State,
+	// BeginTime and EndTime).
+	//
+	// https://dev.recurly.com/docs/list-unique-coupon-codes
+	List(couponCode string, opts *PagerOptions) Pager
+
+	// Get retrieves a unique coupon code. If the code does not exist,
+	// a nil unique coupon code and nil error are returned.
+	//
+	// https://dev.recurly.com/docs/lookup-a-unique-coupon-code
+	Get(ctx context.Context, code string) (*UniqueCouponCode, error)
+
+	// Deactivate expires a unique coupon code so it can no longer be redeemed.
+	//
+	// https://dev.recurly.com/docs/deactivate-a-unique-coupon-code
+	Deactivate(ctx context.Context, code string) error
+
+	// Restore restores an expired unique coupon code so it can be redeemed
+	// again.
+	//
+	// https://dev.recurly.com/docs/restore-a-unique-coupon-code
+	Restore(ctx context.Context, code string) (*UniqueCouponCode, error)
+}
+
+// Unique coupon code state constants.
+const (
+	UniqueCouponCodeStateRedeemable = "redeemable"
+	UniqueCouponCodeStateRedeemed   = "redeemed"
+	UniqueCouponCodeStateInactive   = "inactive"
+	UniqueCouponCodeStateExpired    = "expired"
+)
+
+// UniqueCouponCode is an individual code generated from a bulk coupon.
+// This is a read-only object.
+//
+// https://dev.recurly.com/docs/unique-coupon-code-object
+type UniqueCouponCode struct {
+	XMLName        xml.Name `xml:"unique_coupon_code"`
+	CouponCode     string   `xml:"-"` // Parent coupon code
+	Code           string   `xml:"code"`
+	State          string   `xml:"state"`
+	BulkCouponCode string   `xml:"bulk_coupon_code"`
+	BulkCouponID   int64    `xml:"bulk_coupon_id"`
+	CreatedAt      NullTime `xml:"created_at"`
+	UpdatedAt      NullTime `xml:"updated_at"`
+	RedeemedAt     NullTime `xml:"redeemed_at"`
+	ExpiredAt      NullTime `xml:"expired_at"`
+}
+
+// UnmarshalXML unmarshals unique coupon codes and handles intermediary state
+// during unmarshaling for types like href.
+func (c *UniqueCouponCode) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type uniqueCouponCodeAlias UniqueCouponCode
+	var v struct {
+		XMLName xml.Name `xml:"unique_coupon_code"`
+		uniqueCouponCodeAlias
+		CouponCode href `xml:"coupon"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	*c = UniqueCouponCode(v.uniqueCouponCodeAlias)
+	c.XMLName = v.XMLName
+	c.CouponCode = v.CouponCode.LastPartOfPath()
+	return nil
+}
+
+var _ UniqueCouponCodesService = &uniqueCouponCodesImpl{}
+
+// uniqueCouponCodesImpl implements UniqueCouponCodesService.
+type uniqueCouponCodesImpl serviceImpl
+
+func (s *uniqueCouponCodesImpl) List(couponCode string, opts *PagerOptions) Pager {
+	path := fmt.Sprintf("/coupons/%s/unique_coupon_codes", couponCode)
+	return s.client.newPager("GET", path, opts)
+}
+
+func (s *uniqueCouponCodesImpl) Get(ctx context.Context, code string) (*UniqueCouponCode, error) {
+	path := fmt.Sprintf("/unique_coupon_codes/%s", code)
+	req, err := s.client.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst UniqueCouponCode
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		if e, ok := err.(*ClientError); ok && e.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *uniqueCouponCodesImpl) Deactivate(ctx context.Context, code string) error {
+	path := fmt.Sprintf("/unique_coupon_codes/%s", code)
+	req, err := s.client.newRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	return err
+}
+
+func (s *uniqueCouponCodesImpl) Restore(ctx context.Context, code string) (*UniqueCouponCode, error) {
+	path := fmt.Sprintf("/unique_coupon_codes/%s/restore", code)
+	req, err := s.client.newRequest("PUT", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst UniqueCouponCode
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
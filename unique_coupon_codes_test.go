@@ -0,0 +1,129 @@
+package recurly_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUniqueCouponCodes_List(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	var invocations int
+	s.HandleFunc("GET", "/v2/coupons/special/unique_coupon_codes", func(w http.ResponseWriter, r *http.Request) {
+		invocations++
+		if v := r.URL.Query().Get("state"); v != "redeemed" {
+			t.Fatalf("unexpected state: %q", v)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("unique_coupon_codes.xml"))
+	}, t)
+
+	pager := client.UniqueCouponCodes.List("special", &recurly.PagerOptions{
+		State: recurly.UniqueCouponCodeStateRedeemed,
+	})
+	for pager.Next() {
+		var codes []recurly.UniqueCouponCode
+		if err := pager.Fetch(context.Background(), &codes); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected s to be invoked")
+		} else if diff := cmp.Diff(codes, []recurly.UniqueCouponCode{*NewTestUniqueCouponCode()}); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+	if invocations != 1 {
+		t.Fatalf("unexpected number of invocations: %d", invocations)
+	}
+}
+
+func TestUniqueCouponCodes_Get(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/unique_coupon_codes/special-0ab5", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustOpenFile("unique_coupon_code.xml"))
+		}, t)
+
+		if c, err := client.UniqueCouponCodes.Get(context.Background(), "special-0ab5"); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(c, NewTestUniqueCouponCode()); diff != "" {
+			t.Fatal(diff)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		}
+	})
+
+	// Ensure a 404 returns nil values.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/unique_coupon_codes/special-0ab5", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, t)
+
+		if c, err := client.UniqueCouponCodes.Get(context.Background(), "special-0ab5"); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if err != nil {
+			t.Fatal(err)
+		} else if c != nil {
+			t.Fatalf("expected nil: %#v", c)
+		}
+	})
+}
+
+func TestUniqueCouponCodes_Deactivate(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("DELETE", "/v2/unique_coupon_codes/special-0ab5", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, t)
+
+	if err := client.UniqueCouponCodes.Deactivate(context.Background(), "special-0ab5"); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUniqueCouponCodes_Restore(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("PUT", "/v2/unique_coupon_codes/special-0ab5/restore", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("unique_coupon_code.xml"))
+	}, t)
+
+	if c, err := client.UniqueCouponCodes.Restore(context.Background(), "special-0ab5"); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(c, NewTestUniqueCouponCode()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+// Returns a unique coupon code corresponding to testdata/unique_coupon_code.xml.
+func NewTestUniqueCouponCode() *recurly.UniqueCouponCode {
+	return &recurly.UniqueCouponCode{
+		XMLName:        xml.Name{Local: "unique_coupon_code"},
+		CouponCode:     "special",
+		Code:           "special-0ab5",
+		State:          recurly.UniqueCouponCodeStateRedeemed,
+		BulkCouponCode: "special",
+		BulkCouponID:   2151093486799579392,
+		CreatedAt:      recurly.NewTime(MustParseTime("2019-07-01T20:00:00Z")),
+		UpdatedAt:      recurly.NewTime(MustParseTime("2019-07-02T20:00:00Z")),
+		RedeemedAt:     recurly.NewTime(MustParseTime("2019-07-02T20:00:00Z")),
+	}
+}
@@ -0,0 +1,134 @@
+package recurly
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+)
+
+// UsageService manages the interactions for usage records on usage-based
+// subscription add-ons.
+type UsageService interface {
+	// List returns a pager to paginate usage records for a subscription add-on.
+	// PagerOptions are used to optionally filter the results. Set
+	// opts.BillingStatus to "unbilled", "billed", or "all" to filter by
+	// billing status.
+	//
+	// https://dev.recurly.com/docs/list-add-ons-usage
+	List(subUUID string, addOnCode string, opts *PagerOptions) Pager
+
+	// Get retrieves a usage record. If the usage record does not exist,
+	// a nil usage and nil error are returned.
+	//
+	// https://dev.recurly.com/docs/lookup-usage-record
+	Get(ctx context.Context, subUUID string, addOnCode string, usageID int64) (*Usage, error)
+
+	// Create logs a usage record on a subscription add-on.
+	//
+	// https://dev.recurly.com/docs/log-usage
+	Create(ctx context.Context, subUUID string, addOnCode string, u Usage) (*Usage, error)
+
+	// Update updates a usage record. Usage records that have been billed
+	// cannot be updated.
+	//
+	// https://dev.recurly.com/docs/update-usage
+	Update(ctx context.Context, subUUID string, addOnCode string, usageID int64, u Usage) (*Usage, error)
+
+	// Delete removes a usage record. Usage records that have been billed
+	// cannot be deleted.
+	//
+	// https://dev.recurly.com/docs/delete-a-usage-record
+	Delete(ctx context.Context, subUUID string, addOnCode string, usageID int64) error
+}
+
+// Usage billing status constants for filtering usage records.
+const (
+	UsageBillingStatusUnbilled = "unbilled"
+	UsageBillingStatusBilled   = "billed"
+	UsageBillingStatusAll      = "all"
+)
+
+// Usage is a record of units consumed on a usage-based subscription add-on.
+//
+// https://dev.recurly.com/docs/usage-record-object
+type Usage struct {
+	XMLName            xml.Name `xml:"usage"`
+	ID                 int64    `xml:"id,omitempty"`
+	Amount             int      `xml:"amount,omitempty"`
+	MerchantTag        string   `xml:"merchant_tag,omitempty"`
+	RecordingTimestamp NullTime `xml:"recording_timestamp,omitempty"`
+	UsageTimestamp     NullTime `xml:"usage_timestamp,omitempty"`
+	UsageType          string   `xml:"usage_type,omitempty"`
+	UnitAmountInCents  NullInt  `xml:"unit_amount_in_cents,omitempty"`
+	UsagePercentage    float64  `xml:"usage_percentage,omitempty"`
+	BilledAt           NullTime `xml:"billed_at,omitempty"`
+	CreatedAt          NullTime `xml:"created_at,omitempty"`
+	UpdatedAt          NullTime `xml:"updated_at,omitempty"`
+}
+
+var _ UsageService = &usageImpl{}
+
+// usageImpl implements UsageService.
+type usageImpl serviceImpl
+
+func (s *usageImpl) List(subUUID string, addOnCode string, opts *PagerOptions) Pager {
+	path := fmt.Sprintf("/subscriptions/%s/add_ons/%s/usage", sanitizeUUID(subUUID), addOnCode)
+	return s.client.newPager("GET", path, opts)
+}
+
+func (s *usageImpl) Get(ctx context.Context, subUUID string, addOnCode string, usageID int64) (*Usage, error) {
+	path := fmt.Sprintf("/subscriptions/%s/add_ons/%s/usage/%d", sanitizeUUID(subUUID), addOnCode, usageID)
+	req, err := s.client.newRequest("GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst Usage
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		if e, ok := err.(*ClientError); ok && e.Response.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *usageImpl) Create(ctx context.Context, subUUID string, addOnCode string, u Usage) (*Usage, error) {
+	path := fmt.Sprintf("/subscriptions/%s/add_ons/%s/usage", sanitizeUUID(subUUID), addOnCode)
+	req, err := s.client.newRequest("POST", path, u)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst Usage
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *usageImpl) Update(ctx context.Context, subUUID string, addOnCode string, usageID int64, u Usage) (*Usage, error) {
+	path := fmt.Sprintf("/subscriptions/%s/add_ons/%s/usage/%d", sanitizeUUID(subUUID), addOnCode, usageID)
+	req, err := s.client.newRequest("PUT", path, u)
+	if err != nil {
+		return nil, err
+	}
+
+	var dst Usage
+	if _, err := s.client.do(ctx, req, &dst); err != nil {
+		return nil, err
+	}
+	return &dst, nil
+}
+
+func (s *usageImpl) Delete(ctx context.Context, subUUID string, addOnCode string, usageID int64) error {
+	path := fmt.Sprintf("/subscriptions/%s/add_ons/%s/usage/%d", sanitizeUUID(subUUID), addOnCode, usageID)
+	req, err := s.client.newRequest("DELETE", path, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.do(ctx, req, nil)
+	return err
+}
@@ -0,0 +1,163 @@
+package recurly_test
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUsage_List(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	var invocations int
+	s.HandleFunc("GET", "/v2/subscriptions/44f83d7cba354d5b84812419f923ea96/add_ons/storage/usage", func(w http.ResponseWriter, r *http.Request) {
+		invocations++
+		if v := r.URL.Query().Get("billing_status"); v != "unbilled" {
+			t.Fatalf("unexpected billing_status: %q", v)
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("usages.xml"))
+	}, t)
+
+	pager := client.Usage.List("44f83d7c-ba35-4d5b-8481-2419f923ea96", "storage", &recurly.PagerOptions{
+		BillingStatus: recurly.UsageBillingStatusUnbilled,
+	})
+	for pager.Next() {
+		var usage []recurly.Usage
+		if err := pager.Fetch(context.Background(), &usage); err != nil {
+			t.Fatal(err)
+		} else if !s.Invoked {
+			t.Fatal("expected s to be invoked")
+		} else if diff := cmp.Diff(usage, []recurly.Usage{*NewTestUsage()}); diff != "" {
+			t.Fatal(diff)
+		}
+	}
+	if invocations != 1 {
+		t.Fatalf("unexpected number of invocations: %d", invocations)
+	}
+}
+
+func TestUsage_Get(t *testing.T) {
+	t.Run("OK", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/subscriptions/44f83d7cba354d5b84812419f923ea96/add_ons/storage/usage/394729929104688227", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write(MustOpenFile("usage.xml"))
+		}, t)
+
+		if u, err := client.Usage.Get(context.Background(), "44f83d7c-ba35-4d5b-8481-2419f923ea96", "storage", 394729929104688227); err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(u, NewTestUsage()); diff != "" {
+			t.Fatal(diff)
+		} else if !s.Invoked {
+			t.Fatal("expected fn invocation")
+		}
+	})
+
+	// Ensure a 404 returns nil values.
+	t.Run("ErrNotFound", func(t *testing.T) {
+		client, s := recurly.NewTestServer()
+		defer s.Close()
+
+		s.HandleFunc("GET", "/v2/subscriptions/44f83d7cba354d5b84812419f923ea96/add_ons/storage/usage/394729929104688227", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}, t)
+
+		if u, err := client.Usage.Get(context.Background(), "44f83d7c-ba35-4d5b-8481-2419f923ea96", "storage", 394729929104688227); !s.Invoked {
+			t.Fatal("expected fn invocation")
+		} else if err != nil {
+			t.Fatal(err)
+		} else if u != nil {
+			t.Fatalf("expected nil: %#v", u)
+		}
+	})
+}
+
+func TestUsage_Create(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("POST", "/v2/subscriptions/44f83d7cba354d5b84812419f923ea96/add_ons/storage/usage", func(w http.ResponseWriter, r *http.Request) {
+		if str := MustReadAllString(r.Body); str != MustCompactString(`
+			<usage>
+				<amount>100</amount>
+				<merchant_tag>Order ID: 4939853977878713</merchant_tag>
+				<recording_timestamp>2019-08-13T19:00:00Z</recording_timestamp>
+				<usage_timestamp>2019-08-13T18:00:00Z</usage_timestamp>
+			</usage>
+		`) {
+			t.Fatal(str)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write(MustOpenFile("usage.xml"))
+	}, t)
+
+	if u, err := client.Usage.Create(context.Background(), "44f83d7c-ba35-4d5b-8481-2419f923ea96", "storage", recurly.Usage{
+		Amount:             100,
+		MerchantTag:        "Order ID: 4939853977878713",
+		RecordingTimestamp: recurly.NewTime(MustParseTime("2019-08-13T19:00:00Z")),
+		UsageTimestamp:     recurly.NewTime(MustParseTime("2019-08-13T18:00:00Z")),
+	}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(u, NewTestUsage()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestUsage_Update(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("PUT", "/v2/subscriptions/44f83d7cba354d5b84812419f923ea96/add_ons/storage/usage/394729929104688227", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(MustOpenFile("usage.xml"))
+	}, t)
+
+	if u, err := client.Usage.Update(context.Background(), "44f83d7c-ba35-4d5b-8481-2419f923ea96", "storage", 394729929104688227, recurly.Usage{}); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	} else if diff := cmp.Diff(u, NewTestUsage()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestUsage_Delete(t *testing.T) {
+	client, s := recurly.NewTestServer()
+	defer s.Close()
+
+	s.HandleFunc("DELETE", "/v2/subscriptions/44f83d7cba354d5b84812419f923ea96/add_ons/storage/usage/394729929104688227", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}, t)
+
+	if err := client.Usage.Delete(context.Background(), "44f83d7c-ba35-4d5b-8481-2419f923ea96", "storage", 394729929104688227); !s.Invoked {
+		t.Fatal("expected fn invocation")
+	} else if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Returns a usage record corresponding to testdata/usage.xml.
+func NewTestUsage() *recurly.Usage {
+	return &recurly.Usage{
+		XMLName:            xml.Name{Local: "usage"},
+		ID:                 394729929104688227,
+		Amount:             100,
+		MerchantTag:        "Order ID: 4939853977878713",
+		RecordingTimestamp: recurly.NewTime(MustParseTime("2019-08-13T19:00:00Z")),
+		UsageTimestamp:     recurly.NewTime(MustParseTime("2019-08-13T18:00:00Z")),
+		UsageType:          recurly.UsageTypePrice,
+		UnitAmountInCents:  recurly.NewInt(45),
+		CreatedAt:          recurly.NewTime(MustParseTime("2019-08-13T19:00:01Z")),
+		UpdatedAt:          recurly.NewTime(MustParseTime("2019-08-13T19:00:01Z")),
+	}
+}
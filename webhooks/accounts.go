@@ -20,12 +20,42 @@ type AccountNotification struct {
 
 // Account represents the account object sent in webhooks.
 type Account struct {
-	XMLName     xml.Name `xml:"account"`
-	Code        string   `xml:"account_code"`
-	Username    string   `xml:"username"`
-	Email       string   `xml:"email"`
+	XMLName     xml.Name     `xml:"account"`
+	Code        string       `xml:"account_code"`
+	Username    string       `xml:"username"`
+	Email       string       `xml:"email"`
+	FirstName   string       `xml:"first_name"`
+	LastName    string       `xml:"last_name"`
+	CompanyName string       `xml:"company_name"`
+	Phone       string       `xml:"phone"`
+	VATNumber   string       `xml:"vat_number"`
+	Address     *Address     `xml:"address"`
+	BillingInfo *BillingInfo `xml:"billing_info"`
+}
+
+// Address represents an address sent in webhooks.
+type Address struct {
+	XMLName  xml.Name `xml:"address"`
+	Address  string   `xml:"address1"`
+	Address2 string   `xml:"address2"`
+	City     string   `xml:"city"`
+	State    string   `xml:"state"`
+	Zip      string   `xml:"zip"`
+	Country  string   `xml:"country"`
+	Phone    string   `xml:"phone"`
+}
+
+// BillingInfo represents the billing summary sent with accounts in
+// webhooks. Only masked card details are sent.
+type BillingInfo struct {
+	XMLName     xml.Name `xml:"billing_info"`
 	FirstName   string   `xml:"first_name"`
 	LastName    string   `xml:"last_name"`
-	CompanyName string   `xml:"company_name"`
-	Phone       string   `xml:"phone"`
+	Company     string   `xml:"company"`
+	PaymentType string   `xml:"payment_type"`
+	CardType    string   `xml:"card_type"`
+	FirstSix    string   `xml:"first_six"`
+	LastFour    string   `xml:"last_four"`
+	Month       int      `xml:"month"`
+	Year        int      `xml:"year"`
 }
@@ -0,0 +1,116 @@
+package webhooks_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+func TestBasicAuth(t *testing.T) {
+	var invoked bool
+	h := webhooks.BasicAuth("recurly", "secret", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+	}))
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+		setAuth  bool
+		code     int
+	}{
+		{name: "OK", username: "recurly", password: "secret", setAuth: true, code: http.StatusOK},
+		{name: "Missing", code: http.StatusUnauthorized},
+		{name: "InvalidUsername", username: "other", password: "secret", setAuth: true, code: http.StatusUnauthorized},
+		{name: "InvalidPassword", username: "recurly", password: "secrets", setAuth: true, code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoked = false
+			r := httptest.NewRequest("POST", "/", nil)
+			if tt.setAuth {
+				r.SetBasicAuth(tt.username, tt.password)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.code {
+				t.Fatalf("unexpected status: %d", w.Code)
+			} else if invoked != (tt.code == http.StatusOK) {
+				t.Fatalf("unexpected invocation: %v", invoked)
+			} else if tt.code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Fatal("expected WWW-Authenticate header")
+			}
+		})
+	}
+}
+
+func TestIPAllowlist(t *testing.T) {
+	a, err := webhooks.NewIPAllowlist()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a.TrustedProxies, err = webhooks.ParseCIDRs("10.0.0.0/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var invoked bool
+	h := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		invoked = true
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		code       int
+	}{
+		{name: "Direct", remoteAddr: "50.18.192.88:1234", code: http.StatusOK},
+		{name: "DirectDenied", remoteAddr: "203.0.113.1:1234", code: http.StatusForbidden},
+		{name: "Proxied", remoteAddr: "10.0.0.1:1234", forwarded: "52.8.32.100", code: http.StatusOK},
+		{name: "ProxiedChain", remoteAddr: "10.0.0.1:1234", forwarded: "52.8.32.100, 10.0.0.2", code: http.StatusOK},
+		{name: "ProxiedDenied", remoteAddr: "10.0.0.1:1234", forwarded: "203.0.113.1", code: http.StatusForbidden},
+		{name: "Spoofed", remoteAddr: "10.0.0.1:1234", forwarded: "52.8.32.100, 203.0.113.1", code: http.StatusForbidden},
+		{name: "UntrustedProxy", remoteAddr: "203.0.113.1:1234", forwarded: "52.8.32.100", code: http.StatusForbidden},
+		{name: "ProxyNoHeader", remoteAddr: "10.0.0.1:1234", code: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invoked = false
+			r := httptest.NewRequest("POST", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.code {
+				t.Fatalf("unexpected status: %d", w.Code)
+			} else if invoked != (tt.code == http.StatusOK) {
+				t.Fatalf("unexpected invocation: %v", invoked)
+			}
+		})
+	}
+}
+
+func TestParseCIDRs(t *testing.T) {
+	if nets, err := webhooks.ParseCIDRs("192.168.0.1", "2001:db8::1", "10.0.0.0/8"); err != nil {
+		t.Fatal(err)
+	} else if len(nets) != 3 {
+		t.Fatalf("unexpected length: %d", len(nets))
+	} else if nets[0].String() != "192.168.0.1/32" {
+		t.Fatal(nets[0].String())
+	} else if nets[1].String() != "2001:db8::1/128" {
+		t.Fatal(nets[1].String())
+	}
+
+	if _, err := webhooks.ParseCIDRs("not an ip"); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,40 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Marshal returns the XML webhook Recurly would send for notification n.
+// n must be one of the notifications returned by Parse (for example
+// *PaymentNotification or *ChargeInvoiceNotification) with its Type set to a
+// notification name of that type, which is used as the root element. Parse
+// returns a notification equal to n when given the result.
+//
+// Null values are omitted rather than sent with nil="true", which Parse
+// treats the same way.
+func Marshal(n interface{}) ([]byte, error) {
+	typ := notificationType(n)
+	if typ == "" {
+		return nil, errors.New("webhooks: notification type is required")
+	}
+
+	dst, err := nameToNotification(typ)
+	if err != nil {
+		return nil, err
+	} else if reflect.TypeOf(dst).Elem() != reflect.Indirect(reflect.ValueOf(n)).Type() {
+		return nil, fmt.Errorf("webhooks: %s cannot be sent as %T", typ, n)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.EncodeElement(n, xml.StartElement{Name: xml.Name{Local: typ}}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,138 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/blacklightcms/recurly/webhooks"
+	"github.com/google/go-cmp/cmp"
+)
+
+// Ensure every fixture round trips through Marshal and Parse.
+func TestMarshal_Fixtures(t *testing.T) {
+	files, err := filepath.Glob("testdata/*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			expected, err := webhooks.Parse(MustOpenFile(file))
+			if _, ok := err.(webhooks.ErrUnknownNotification); ok {
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			b, err := webhooks.Marshal(expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := webhooks.Parse(bytes.NewReader(b))
+			if err != nil {
+				t.Fatal(err)
+			} else if diff := cmp.Diff(result, expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	b, err := webhooks.Marshal(&webhooks.PaymentNotification{
+		Type:        webhooks.SuccessfulPayment,
+		Account:     NewTestNotificationAccount(),
+		Transaction: NewTestNotificationTransaction("purchase"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := string(b)
+	if !strings.HasPrefix(s, `<?xml version="1.0" encoding="UTF-8"?>`+"\n<successful_payment_notification>") {
+		t.Fatalf("unexpected xml: %s", s)
+	} else if !strings.Contains(s, "<account_code>1</account_code>") {
+		t.Fatalf("unexpected xml: %s", s)
+	} else if !strings.Contains(s, "<date>2020-02-13T21:00:31Z</date>") {
+		t.Fatalf("unexpected xml: %s", s)
+	}
+}
+
+func TestMarshal_Err(t *testing.T) {
+	for _, n := range []interface{}{
+		nil,
+		struct{}{},
+		&webhooks.PaymentNotification{},
+		&webhooks.PaymentNotification{Type: "unknown_notification"},
+		&webhooks.PaymentNotification{Type: webhooks.NewAccount},
+	} {
+		if _, err := webhooks.Marshal(n); err == nil {
+			t.Fatalf("expected error for %#v", n)
+		}
+	}
+}
+
+func TestPostTest(t *testing.T) {
+	var code string
+	h := webhooks.NewHandler()
+	h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+		code = n.Account.Code
+		return nil
+	})
+	v := webhooks.NewSignatureVerifier("secret")
+	handler := webhooks.BasicAuth("user", "pass", v.Handler(h))
+
+	n := &webhooks.AccountNotification{
+		Type:    webhooks.NewAccount,
+		Account: NewTestNotificationAccount(),
+	}
+
+	if w, err := webhooks.PostTest(handler, n, nil); err != nil {
+		t.Fatal(err)
+	} else if w.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status: %d", w.Code)
+	}
+
+	if w, err := webhooks.PostTest(handler, n, &webhooks.TestRequestOptions{
+		Username: "user",
+		Password: "pass",
+		Secrets:  []string{"secret"},
+	}); err != nil {
+		t.Fatal(err)
+	} else if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status: %d", w.Code)
+	} else if code != "1" {
+		t.Fatalf("unexpected account code: %q", code)
+	}
+
+	if _, err := webhooks.PostTest(handler, &webhooks.AccountNotification{}, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestNewTestRequest(t *testing.T) {
+	r, err := webhooks.NewTestRequest(&webhooks.AccountNotification{
+		Type:    webhooks.NewAccount,
+		Account: NewTestNotificationAccount(),
+	}, &webhooks.TestRequestOptions{RemoteAddr: "50.18.192.88:1234"})
+	if err != nil {
+		t.Fatal(err)
+	} else if r.Method != "POST" {
+		t.Fatalf("unexpected method: %s", r.Method)
+	} else if r.RemoteAddr != "50.18.192.88:1234" {
+		t.Fatalf("unexpected remote addr: %s", r.RemoteAddr)
+	} else if r.Header.Get(webhooks.SignatureHeader) != "" {
+		t.Fatal("unexpected signature")
+	}
+
+	n, err := webhooks.Parse(r.Body)
+	if err != nil {
+		t.Fatal(err)
+	} else if n, ok := n.(*webhooks.AccountNotification); !ok || n.Account.Code != "1" {
+		t.Fatalf("unexpected notification: %#v", n)
+	}
+}
@@ -17,6 +17,18 @@ const (
 	ReopenedChargeInvoice   = "reopened_charge_invoice_notification"
 )
 
+// Legacy invoice notifications, sent to sites that have not enabled credit
+// invoices. These are returned as ChargeInvoiceNotification. The legacy
+// subscription_id and date elements are read into SubscriptionUUIDs and
+// CreatedAt.
+// https://dev.recurly.com/page/webhooks#invoice-notifications
+const (
+	NewInvoice     = "new_invoice_notification"
+	PaidInvoice    = "paid_invoice_notification"
+	PastDueInvoice = "past_due_invoice_notification"
+	ClosedInvoice  = "closed_invoice_notification"
+)
+
 // ChargeInvoiceNotification is returned for all charge invoice notifications.
 type ChargeInvoiceNotification struct {
 	Type    string        `xml:"-"`
@@ -52,3 +64,29 @@ type ChargeInvoice struct {
 	CustomerNotes                 string           `xml:"customer_notes"`
 	TermsAndConditions            string           `xml:"terms_and_conditions"`
 }
+
+// UnmarshalXML unmarshals charge invoices, reading the subscription_id and
+// date elements of legacy invoice notifications into SubscriptionUUIDs and
+// CreatedAt.
+func (i *ChargeInvoice) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type chargeInvoiceAlias ChargeInvoice
+	var v struct {
+		XMLName xml.Name `xml:"invoice"`
+		chargeInvoiceAlias
+		SubscriptionUUID string           `xml:"subscription_id"`
+		Date             recurly.NullTime `xml:"date"`
+	}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+
+	*i = ChargeInvoice(v.chargeInvoiceAlias)
+	i.XMLName = v.XMLName
+	if len(i.SubscriptionUUIDs) == 0 && v.SubscriptionUUID != "" {
+		i.SubscriptionUUIDs = []string{v.SubscriptionUUID}
+	}
+	if i.CreatedAt.Time().IsZero() {
+		i.CreatedAt = v.Date
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package webhooks
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// SeenStore records the event keys of notifications that have been
+// processed. Implementations must be safe for concurrent use.
+type SeenStore interface {
+	// Seen returns true if key has been marked.
+	Seen(ctx context.Context, key string) (bool, error)
+
+	// Mark records key as processed.
+	Mark(ctx context.Context, key string) error
+}
+
+// Deduplicator prevents notifications retried by Recurly from being
+// processed more than once. Keys are marked only after the callback
+// succeeds, so failed notifications are processed again when retried.
+//
+// NOTE: Two deliveries of the same notification that arrive concurrently
+// may both be processed. Callbacks that must never run twice should also
+// be idempotent.
+type Deduplicator struct {
+	// Store records processed event keys.
+	Store SeenStore
+
+	// Flag, if true, runs the callback for duplicates with a context for
+	// which IsDuplicate returns true, rather than skipping them.
+	Flag bool
+
+	// Key returns the event key for a notification. If nil, EventKey is
+	// used. Notifications with an empty key are always processed.
+	Key func(n interface{}) string
+
+	// OnError, if set, is called when a key cannot be marked after the
+	// callback succeeds. The notification is still acknowledged, since
+	// failing it would cause Recurly to retry a processed notification.
+	OnError func(n interface{}, err error)
+}
+
+// NewDeduplicator returns a Deduplicator that skips duplicates recorded
+// in store.
+func NewDeduplicator(store SeenStore) *Deduplicator {
+	return &Deduplicator{Store: store}
+}
+
+// Wrap returns a HandlerFunc that checks the Store before calling fn.
+func (d *Deduplicator) Wrap(fn HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, n interface{}) error {
+		keyFn := d.Key
+		if keyFn == nil {
+			keyFn = EventKey
+		}
+
+		key := keyFn(n)
+		if key == "" {
+			return fn(ctx, n)
+		}
+
+		seen, err := d.Store.Seen(ctx, key)
+		if err != nil {
+			return err
+		} else if seen {
+			if !d.Flag {
+				return nil
+			}
+			return fn(context.WithValue(ctx, duplicateKey{}, true), n)
+		}
+
+		if err := fn(ctx, n); err != nil {
+			return err
+		} else if err := d.Store.Mark(ctx, key); err != nil && d.OnError != nil {
+			d.OnError(n, err)
+		}
+		return nil
+	}
+}
+
+type duplicateKey struct{}
+
+// IsDuplicate returns true if ctx was passed to a callback for a
+// notification that has already been processed. See Deduplicator.Flag.
+func IsDuplicate(ctx context.Context) bool {
+	v, _ := ctx.Value(duplicateKey{}).(bool)
+	return v
+}
+
+// EventKey returns a stable key identifying a notification returned by
+// Parse. The key is built from the notification type, the UUID (or code) of
+// the resource it describes, and the resource's state or timestamp, so
+// retries of the same notification share a key while later notifications
+// of the same type for the same resource do not. An empty string is
+// returned for unsupported notifications.
+//
+// NOTE: Account and subscription notifications carry no timestamp or other
+// version, so a retry cannot be told apart from a later update with the
+// same contents. EventKey returns an empty string for them, and they are
+// always processed unless Deduplicator.Key returns a key for them.
+func EventKey(n interface{}) string {
+	switch n := n.(type) {
+	case *ChargeInvoiceNotification:
+		return eventKey(n.Type, n.Invoice.UUID, n.Invoice.State, timeKey(n.Invoice.UpdatedAt))
+	case *CreditInvoiceNotification:
+		return eventKey(n.Type, n.Invoice.UUID, n.Invoice.State, timeKey(n.Invoice.UpdatedAt))
+	case *CreditPaymentNotification:
+		return eventKey(n.Type, n.CreditPayment.UUID, timeKey(n.CreditPayment.VoidedAt))
+	case *PaymentNotification:
+		return eventKey(n.Type, n.Transaction.UUID, n.Transaction.Status)
+	case *NewDunningEventNotification:
+		return eventKey(n.Type, n.Invoice.UUID, n.Invoice.State, timeKey(n.Invoice.UpdatedAt))
+	case *GiftCardNotification:
+		var id string
+		if n.GiftCard.ID != 0 {
+			id = strconv.FormatInt(n.GiftCard.ID, 10)
+		}
+		return eventKey(n.Type, id, strconv.Itoa(n.GiftCard.BalanceInCents), timeKey(n.GiftCard.UpdatedAt))
+	case *ItemNotification:
+		return eventKey(n.Type, n.Item.Code, n.Item.State, timeKey(n.Item.UpdatedAt))
+	case *UsageNotification:
+		var id string
+		if n.Usage.ID != 0 {
+			id = strconv.FormatInt(n.Usage.ID, 10)
+		}
+		return eventKey(n.Type, id, timeKey(n.Usage.UpdatedAt))
+	}
+	return ""
+}
+
+// eventKey joins the type and parts of a key. An empty string is returned
+// if there is no resource identifier.
+func eventKey(typ, id string, parts ...string) string {
+	if typ == "" || id == "" {
+		return ""
+	}
+	return strings.Join(append([]string{typ, id}, parts...), ":")
+}
+
+// timeKey returns t as Unix seconds, or an empty string if t is null.
+func timeKey(t recurly.NullTime) string {
+	if v := t.Time(); !v.IsZero() {
+		return strconv.FormatInt(v.Unix(), 10)
+	}
+	return ""
+}
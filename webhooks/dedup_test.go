@@ -0,0 +1,268 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+func TestEventKey(t *testing.T) {
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{file: "successful_payment_notification.xml", expected: "successful_payment_notification:a5143c1d3a6f4a8287d0e2cc1d4c0427:success"},
+		{file: "charge_invoice_notification.xml"},
+		{file: "credit_payment_notification.xml"},
+		{file: "updated_balance_gift_card_notification.xml", expected: "updated_balance_gift_card_notification:2005384587788419083:500:1470343047"},
+		{file: "deactivated_item_notification.xml", expected: "deactivated_item_notification:plastic_gloves:inactive:1562097600"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			key := webhooks.EventKey(MustParseFile(filepath.Join("testdata", tt.file)))
+			if key == "" {
+				t.Fatal("expected key")
+			} else if tt.expected != "" && key != tt.expected {
+				t.Fatalf("unexpected key: %q", key)
+			} else if again := webhooks.EventKey(MustParseFile(filepath.Join("testdata", tt.file))); again != key {
+				t.Fatalf("unstable key: %q != %q", again, key)
+			}
+		})
+	}
+
+	// Ensure notifications without a version have no key.
+	for _, file := range []string{
+		"updated_subscription_notification.xml",
+		"renewed_subscription_notification.xml",
+		"billing_info_updated_notification.xml",
+		"updated_account_notification.xml",
+	} {
+		if key := webhooks.EventKey(MustParseFile(filepath.Join("testdata", file))); key != "" {
+			t.Fatalf("%s: unexpected key: %q", file, key)
+		}
+	}
+
+	if key := webhooks.EventKey(struct{}{}); key != "" {
+		t.Fatalf("unexpected key: %q", key)
+	}
+}
+
+func TestDeduplicator(t *testing.T) {
+	t.Run("Skip", func(t *testing.T) {
+		h := webhooks.NewHandler()
+		h.Dedup = webhooks.NewDeduplicator(webhooks.NewMemorySeenStore(10))
+
+		var invocations int
+		h.HandlePayment(func(ctx context.Context, n *webhooks.PaymentNotification) error {
+			invocations++
+			return nil
+		})
+
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, MustNewRequest("testdata/successful_payment_notification.xml"))
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status: %d", w.Code)
+			}
+		}
+		if invocations != 1 {
+			t.Fatalf("unexpected number of invocations: %d", invocations)
+		}
+	})
+
+	t.Run("Flag", func(t *testing.T) {
+		d := webhooks.NewDeduplicator(webhooks.NewMemorySeenStore(10))
+		d.Flag = true
+
+		var duplicates []bool
+		fn := d.Wrap(func(ctx context.Context, n interface{}) error {
+			duplicates = append(duplicates, webhooks.IsDuplicate(ctx))
+			return nil
+		})
+
+		n := MustParseFile("testdata/successful_payment_notification.xml")
+		for i := 0; i < 2; i++ {
+			if err := fn(context.Background(), n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(duplicates) != 2 || duplicates[0] || !duplicates[1] {
+			t.Fatalf("unexpected duplicates: %v", duplicates)
+		}
+	})
+
+	// Ensure successive updates to the same subscription or account are
+	// all processed.
+	t.Run("Updates", func(t *testing.T) {
+		h := webhooks.NewHandler()
+		h.Dedup = webhooks.NewDeduplicator(webhooks.NewMemorySeenStore(10))
+
+		var quantities []int
+		h.HandleSubscription(func(ctx context.Context, n *webhooks.SubscriptionNotification) error {
+			quantities = append(quantities, n.Subscription.Quantity)
+			return nil
+		})
+		var accounts int
+		h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+			accounts++
+			return nil
+		})
+
+		b, err := ioutil.ReadFile("testdata/updated_subscription_notification.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, body := range [][]byte{
+			b,
+			bytes.Replace(b, []byte(`<quantity type="integer">1</quantity>`), []byte(`<quantity type="integer">2</quantity>`), 1),
+		} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/", bytes.NewReader(body)))
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status: %d", w.Code)
+			}
+		}
+
+		for i := 0; i < 2; i++ {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, MustNewRequest("testdata/billing_info_updated_notification.xml"))
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status: %d", w.Code)
+			}
+		}
+
+		if len(quantities) != 2 || quantities[0] != 1 || quantities[1] != 2 {
+			t.Fatalf("unexpected quantities: %v", quantities)
+		} else if accounts != 2 {
+			t.Fatalf("unexpected number of account invocations: %d", accounts)
+		}
+	})
+
+	// Ensure a processed notification is acknowledged if it cannot be marked.
+	t.Run("ErrMark", func(t *testing.T) {
+		d := webhooks.NewDeduplicator(errSeenStore{})
+
+		var reported error
+		d.OnError = func(n interface{}, err error) { reported = err }
+
+		fn := d.Wrap(func(ctx context.Context, n interface{}) error { return nil })
+		if err := fn(context.Background(), MustParseFile("testdata/successful_payment_notification.xml")); err != nil {
+			t.Fatal(err)
+		} else if reported == nil || reported.Error() != "mark failed" {
+			t.Fatalf("unexpected reported error: %v", reported)
+		}
+	})
+
+	// Ensure failed callbacks are not marked so retries are processed.
+	t.Run("ErrCallback", func(t *testing.T) {
+		d := webhooks.NewDeduplicator(webhooks.NewMemorySeenStore(10))
+
+		var invocations int
+		fn := d.Wrap(func(ctx context.Context, n interface{}) error {
+			invocations++
+			if invocations == 1 {
+				return errors.New("failed")
+			}
+			return nil
+		})
+
+		n := MustParseFile("testdata/successful_payment_notification.xml")
+		if err := fn(context.Background(), n); err == nil {
+			t.Fatal("expected error")
+		} else if err := fn(context.Background(), n); err != nil {
+			t.Fatal(err)
+		} else if err := fn(context.Background(), n); err != nil {
+			t.Fatal(err)
+		} else if invocations != 2 {
+			t.Fatalf("unexpected number of invocations: %d", invocations)
+		}
+	})
+}
+
+// errSeenStore is a SeenStore that fails to mark keys.
+type errSeenStore struct{}
+
+func (errSeenStore) Seen(ctx context.Context, key string) (bool, error) { return false, nil }
+func (errSeenStore) Mark(ctx context.Context, key string) error         { return errors.New("mark failed") }
+
+func TestMemorySeenStore(t *testing.T) {
+	ctx := context.Background()
+	s := webhooks.NewMemorySeenStore(2)
+
+	for _, key := range []string{"a", "b"} {
+		if err := s.Mark(ctx, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Touch "a" so "b" is the least recently used.
+	if seen, _ := s.Seen(ctx, "a"); !seen {
+		t.Fatal("expected a to be seen")
+	} else if err := s.Mark(ctx, "c"); err != nil {
+		t.Fatal(err)
+	} else if s.Len() != 2 {
+		t.Fatalf("unexpected length: %d", s.Len())
+	}
+
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if seen, err := s.Seen(ctx, key); err != nil {
+			t.Fatal(err)
+		} else if seen != expected {
+			t.Fatalf("unexpected seen value for %q: %v", key, seen)
+		}
+	}
+}
+
+func TestFileSeenStore(t *testing.T) {
+	ctx := context.Background()
+
+	dir, err := ioutil.TempDir("", "webhooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "seen")
+
+	s, err := webhooks.OpenFileSeenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	} else if err := s.Mark(ctx, "a"); err != nil {
+		t.Fatal(err)
+	} else if err := s.Mark(ctx, "a"); err != nil {
+		t.Fatal(err)
+	} else if err := s.Mark(ctx, "invalid\nkey"); err == nil {
+		t.Fatal("expected error")
+	} else if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Ensure keys persist across reopening the store.
+	s, err = webhooks.OpenFileSeenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if seen, err := s.Seen(ctx, "a"); err != nil {
+		t.Fatal(err)
+	} else if !seen {
+		t.Fatal("expected a to be seen")
+	} else if seen, _ := s.Seen(ctx, "b"); seen {
+		t.Fatal("expected b not to be seen")
+	}
+
+	if b, err := ioutil.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "a\n" {
+		t.Fatalf("unexpected file contents: %q", b)
+	}
+}
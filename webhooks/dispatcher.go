@@ -0,0 +1,292 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// DefaultMaxVersions is the default number of resource versions tracked by
+// each Dispatcher worker.
+const DefaultMaxVersions = 10000
+
+// ErrDispatcherClosed is returned when dispatching to a closed Dispatcher.
+var ErrDispatcherClosed = errors.New("webhooks: dispatcher closed")
+
+// Dispatcher serializes processing of notifications for the same account
+// and detects notifications delivered out of order. Recurly does not
+// guarantee delivery order, so an expired_subscription_notification may
+// arrive before the renewed_subscription_notification that preceded it.
+//
+// Notifications are sharded across a fixed pool of workers by account code,
+// so notifications for one account are processed one at a time while
+// notifications for other accounts are processed concurrently. Each worker
+// tracks the latest version of every resource it has processed (see
+// ResourceVersion). Notifications older than the latest version are passed
+// to callbacks with a context for which IsStale returns true, so handlers
+// can ignore them or re-fetch the resource (see Enrich).
+//
+// Fields must not be modified after the first notification is dispatched.
+type Dispatcher struct {
+	// Version returns the resource key and version of a notification. If
+	// nil, ResourceVersion is used. Notifications with an empty key or zero
+	// version are never stale.
+	Version func(n interface{}) (key string, version time.Time)
+
+	// MaxVersions is the number of resource versions tracked by each worker.
+	// Once exceeded, arbitrary versions are forgotten. Defaults to
+	// DefaultMaxVersions.
+	MaxVersions int
+
+	mu     sync.RWMutex
+	closed bool
+	shards []*dispatchShard
+	wg     sync.WaitGroup
+}
+
+// dispatchShard holds the queue and resource versions of a single worker.
+// versions is only accessed by the worker.
+type dispatchShard struct {
+	jobs     chan *dispatchJob
+	versions map[string]time.Time
+}
+
+type dispatchJob struct {
+	ctx  context.Context
+	n    interface{}
+	fn   HandlerFunc
+	done chan error
+}
+
+// NewDispatcher returns a Dispatcher and starts workers goroutines. Close
+// must be called to stop them.
+func NewDispatcher(workers int) *Dispatcher {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	d := &Dispatcher{shards: make([]*dispatchShard, workers)}
+	for i := range d.shards {
+		s := &dispatchShard{
+			jobs:     make(chan *dispatchJob),
+			versions: make(map[string]time.Time),
+		}
+		d.shards[i] = s
+
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			d.work(s)
+		}()
+	}
+	return d
+}
+
+// Wrap returns a HandlerFunc that dispatches notifications to fn on the
+// worker for their account and waits for fn to return.
+func (d *Dispatcher) Wrap(fn HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, n interface{}) error {
+		return d.Dispatch(ctx, n, fn)
+	}
+}
+
+// Dispatch calls fn with n on the worker for the account of n, and returns
+// the result of fn. If ctx is canceled before a worker accepts n, ctx.Err()
+// is returned and fn is not called.
+func (d *Dispatcher) Dispatch(ctx context.Context, n interface{}, fn HandlerFunc) error {
+	job := &dispatchJob{ctx: ctx, n: n, fn: fn, done: make(chan error, 1)}
+
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		return ErrDispatcherClosed
+	}
+	select {
+	case d.shards[d.shard(n)].jobs <- job:
+		d.mu.RUnlock()
+	case <-ctx.Done():
+		d.mu.RUnlock()
+		return ctx.Err()
+	}
+	return <-job.done
+}
+
+// Close stops accepting notifications and waits for the workers to finish.
+func (d *Dispatcher) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return nil
+	}
+	d.closed = true
+	for _, s := range d.shards {
+		close(s.jobs)
+	}
+	d.mu.Unlock()
+
+	d.wg.Wait()
+	return nil
+}
+
+// shard returns the index of the worker for n.
+func (d *Dispatcher) shard(n interface{}) int {
+	key := notificationAccountCode(n)
+	if key == "" {
+		key, _ = d.version(n)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(d.shards)))
+}
+
+func (d *Dispatcher) version(n interface{}) (string, time.Time) {
+	if d.Version != nil {
+		return d.Version(n)
+	}
+	return ResourceVersion(n)
+}
+
+// work processes jobs for s until its queue is closed. Versions are only
+// recorded for fresh notifications whose callback succeeds, so failed
+// notifications retried by Recurly are not marked stale.
+func (d *Dispatcher) work(s *dispatchShard) {
+	limit := d.MaxVersions
+	if limit <= 0 {
+		limit = DefaultMaxVersions
+	}
+
+	for job := range s.jobs {
+		ctx := job.ctx
+		key, version := d.version(job.n)
+		track := key != "" && !version.IsZero()
+
+		stale := false
+		if last, ok := s.versions[key]; track && ok && version.Before(last) {
+			stale = true
+			ctx = context.WithValue(ctx, staleKey{}, true)
+		}
+
+		err := job.fn(ctx, job.n)
+		if err == nil && track && !stale {
+			if _, ok := s.versions[key]; !ok && len(s.versions) >= limit {
+				for k := range s.versions {
+					delete(s.versions, k)
+					break
+				}
+			}
+			s.versions[key] = version
+		}
+		job.done <- err
+	}
+}
+
+type staleKey struct{}
+
+// IsStale returns true if ctx was passed to a callback by a Dispatcher for a
+// notification older than one already processed for the same resource.
+func IsStale(ctx context.Context) bool {
+	v, _ := ctx.Value(staleKey{}).(bool)
+	return v
+}
+
+// ResourceVersion returns the key of the resource a notification returned by
+// Parse describes, and the version of the resource in the notification: the
+// latest of the resource's timestamps that record something that has
+// happened, such as UpdatedAt, ClosedAt or CreatedAt. Account notifications
+// carry no timestamps and return an empty key.
+//
+// NOTE: Subscriptions in webhooks have no updated timestamp, so changes that
+// do not move a timestamp forward (such as reactivating a canceled
+// subscription) may be reported as stale. Handlers should re-fetch rather
+// than drop stale subscription notifications.
+func ResourceVersion(n interface{}) (string, time.Time) {
+	switch n := n.(type) {
+	case *SubscriptionNotification:
+		return subscriptionVersion(n.Subscription)
+	case *ChargeInvoiceNotification:
+		return resourceVersion("invoice", n.Invoice.UUID, n.Invoice.CreatedAt, n.Invoice.UpdatedAt, n.Invoice.ClosedAt)
+	case *CreditInvoiceNotification:
+		return resourceVersion("invoice", n.Invoice.UUID, n.Invoice.CreatedAt, n.Invoice.UpdatedAt, n.Invoice.ClosedAt)
+	case *NewDunningEventNotification:
+		return resourceVersion("invoice", n.Invoice.UUID, n.Invoice.CreatedAt, n.Invoice.UpdatedAt, n.Invoice.ClosedAt)
+	case *CreditPaymentNotification:
+		return resourceVersion("credit_payment", n.CreditPayment.UUID, n.CreditPayment.CreatedAt, n.CreditPayment.VoidedAt)
+	case *PaymentNotification:
+		return resourceVersion("transaction", n.Transaction.UUID, n.Transaction.CreatedAt, n.Transaction.CollectedAt)
+	case *GiftCardNotification:
+		var id string
+		if n.GiftCard.ID != 0 {
+			id = strconv.FormatInt(n.GiftCard.ID, 10)
+		}
+		g := n.GiftCard
+		return resourceVersion("gift_card", id, g.CreatedAt, g.UpdatedAt, g.DeliveredAt, g.RedeemedAt, g.CanceledAt)
+	case *ItemNotification:
+		return resourceVersion("item", n.Item.Code, n.Item.CreatedAt, n.Item.UpdatedAt, n.Item.DeletedAt)
+	case *UsageNotification:
+		var id string
+		if n.Usage.ID != 0 {
+			id = strconv.FormatInt(n.Usage.ID, 10)
+		}
+		return resourceVersion("usage", id, n.Usage.CreatedAt, n.Usage.UpdatedAt, n.Usage.BilledAt)
+	}
+	return "", time.Time{}
+}
+
+// subscriptionVersion returns the version of a subscription. ExpiresAt is
+// only used once the subscription has expired since canceled subscriptions
+// expire in the future.
+func subscriptionVersion(s Subscription) (string, time.Time) {
+	times := []recurly.NullTime{s.ActivatedAt, s.CurrentPeriodStartedAt, s.TrialStartedAt, s.CanceledAt, s.PausedAt}
+	if s.State == recurly.SubscriptionStateExpired {
+		times = append(times, s.ExpiresAt)
+	}
+	return resourceVersion("subscription", s.UUID, times...)
+}
+
+// resourceVersion returns the resource key and the latest of times. An empty
+// key is returned if there is no resource identifier.
+func resourceVersion(typ, id string, times ...recurly.NullTime) (string, time.Time) {
+	if id == "" {
+		return "", time.Time{}
+	}
+
+	var version time.Time
+	for _, t := range times {
+		if v := t.Time(); v.After(version) {
+			version = v
+		}
+	}
+	return typ + ":" + id, version
+}
+
+// notificationAccountCode returns the code of the account a notification
+// belongs to.
+func notificationAccountCode(n interface{}) string {
+	switch n := n.(type) {
+	case *AccountNotification:
+		return n.Account.Code
+	case *SubscriptionNotification:
+		return n.Account.Code
+	case *ChargeInvoiceNotification:
+		return n.Account.Code
+	case *CreditInvoiceNotification:
+		return n.Account.Code
+	case *CreditPaymentNotification:
+		return n.Account.Code
+	case *PaymentNotification:
+		return n.Account.Code
+	case *NewDunningEventNotification:
+		return n.Account.Code
+	case *GiftCardNotification:
+		return n.GiftCard.GifterAccountCode
+	case *UsageNotification:
+		return n.Account.Code
+	}
+	return ""
+}
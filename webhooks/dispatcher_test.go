@@ -0,0 +1,154 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+func TestDispatcher(t *testing.T) {
+	renewed := &webhooks.SubscriptionNotification{
+		Type:         webhooks.RenewedSubscription,
+		Account:      NewTestNotificationAccount(),
+		Subscription: NewTestNotificationSubscription(),
+	}
+	expired := &webhooks.SubscriptionNotification{
+		Type:         webhooks.ExpiredSubscription,
+		Account:      NewTestNotificationAccount(),
+		Subscription: NewTestNotificationSubscription(),
+	}
+	expired.Subscription.State = recurly.SubscriptionStateExpired
+	expired.Subscription.ExpiresAt = recurly.NewTime(MustParseTime("2018-03-09T16:47:30Z"))
+
+	t.Run("Stale", func(t *testing.T) {
+		d := webhooks.NewDispatcher(4)
+		defer d.Close()
+
+		var stale []bool
+		fn := d.Wrap(func(ctx context.Context, n interface{}) error {
+			stale = append(stale, webhooks.IsStale(ctx))
+			return nil
+		})
+
+		for _, n := range []interface{}{expired, renewed, expired} {
+			if err := fn(context.Background(), n); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(stale) != 3 || stale[0] || !stale[1] || stale[2] {
+			t.Fatalf("unexpected stale values: %v", stale)
+		}
+	})
+
+	// Ensure versions of failed notifications are not recorded.
+	t.Run("ErrCallback", func(t *testing.T) {
+		d := webhooks.NewDispatcher(1)
+		defer d.Close()
+
+		var stale bool
+		if err := d.Dispatch(context.Background(), expired, func(ctx context.Context, n interface{}) error {
+			return errors.New("failed")
+		}); err == nil || err.Error() != "failed" {
+			t.Fatalf("unexpected error: %v", err)
+		} else if err := d.Dispatch(context.Background(), renewed, func(ctx context.Context, n interface{}) error {
+			stale = webhooks.IsStale(ctx)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		} else if stale {
+			t.Fatal("unexpected stale notification")
+		}
+	})
+
+	// Ensure notifications for the same account are processed one at a time,
+	// including usage notifications.
+	t.Run("Serialized", func(t *testing.T) {
+		usage := MustParseFile("testdata/new_usage_notification.xml")
+		d := webhooks.NewDispatcher(5)
+		defer d.Close()
+
+		var mu sync.Mutex
+		var running, maxRunning int
+		fn := d.Wrap(func(ctx context.Context, n interface{}) error {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			var n interface{} = renewed
+			if i%2 == 1 {
+				n = usage
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := fn(context.Background(), n); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+
+		if maxRunning != 1 {
+			t.Fatalf("unexpected concurrent callbacks: %d", maxRunning)
+		}
+	})
+
+	t.Run("ErrClosed", func(t *testing.T) {
+		d := webhooks.NewDispatcher(1)
+		if err := d.Close(); err != nil {
+			t.Fatal(err)
+		} else if err := d.Close(); err != nil {
+			t.Fatal(err)
+		} else if err := d.Dispatch(context.Background(), renewed, func(ctx context.Context, n interface{}) error {
+			t.Fatal("unexpected invocation")
+			return nil
+		}); err != webhooks.ErrDispatcherClosed {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestResourceVersion(t *testing.T) {
+	tests := []struct {
+		file    string
+		key     string
+		version string
+	}{
+		{file: "new_account_notification.xml"},
+		{file: "charge_invoice_notification.xml", key: "invoice:42feb03ce368c0e1ead35d4bfa89b82e", version: "2018-02-13T16:00:04Z"},
+		{file: "successful_payment_notification_full.xml", key: "transaction:6027ae4ff3ed18e4ed7d5c4f06b02c4e", version: "2020-02-13T21:00:32Z"},
+		{file: "updated_subscription_notification_full.xml", key: "subscription:4110792b3b01967d854f674b7282f542", version: "2018-02-09T16:47:30Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			key, version := webhooks.ResourceVersion(MustParseFile("testdata/" + tt.file))
+			if key != tt.key {
+				t.Fatalf("unexpected key: %q", key)
+			} else if tt.version == "" && !version.IsZero() {
+				t.Fatalf("unexpected version: %s", version)
+			} else if tt.version != "" && !version.Equal(MustParseTime(tt.version)) {
+				t.Fatalf("unexpected version: %s", version)
+			}
+		})
+	}
+}
@@ -1,7 +1,5 @@
 package webhooks
 
-import "github.com/blacklightcms/recurly"
-
 // Dunning event constants.
 const (
 	NewDunningEvent = "new_dunning_event_notification"
@@ -12,5 +10,5 @@ type NewDunningEventNotification struct {
 	Type         string `xml:"-"`
 	Account      Account
 	Invoice      ChargeInvoice
-	Subscription recurly.Subscription
+	Subscription Subscription
 }
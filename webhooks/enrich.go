@@ -0,0 +1,143 @@
+package webhooks
+
+import (
+	"context"
+	"sync"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// Resources holds the current state of the resources a notification refers
+// to, as returned by the API. Fields are nil if the notification does not
+// refer to the resource or if the resource no longer exists.
+type Resources struct {
+	Account       *recurly.Account
+	Subscription  *recurly.Subscription
+	Invoice       *recurly.Invoice
+	Transaction   *recurly.Transaction
+	CreditPayment *recurly.CreditPayment
+}
+
+// Enrich fetches the authoritative version of each resource referenced by
+// notification n, a notification returned by Parse. Webhooks carry partial
+// snapshots that may be stale by the time they are processed, so Enrich
+// should be used whenever correctness depends on the current state.
+//
+// Resources are fetched concurrently. Resources that have been deleted
+// (404 Not Found) are left nil rather than returned as an error. If any
+// request fails, the first error is returned. Notifications that do not
+// reference any of the resources above return an empty *Resources.
+func Enrich(ctx context.Context, client *recurly.Client, n interface{}) (*Resources, error) {
+	var res Resources
+	var f fetcher
+
+	switch n := n.(type) {
+	case *AccountNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+	case *SubscriptionNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+		f.subscription(ctx, client, n.Subscription.UUID, &res)
+	case *ChargeInvoiceNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+		f.invoice(ctx, client, n.Invoice.InvoiceNumber, &res)
+	case *CreditInvoiceNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+		f.invoice(ctx, client, n.Invoice.InvoiceNumber, &res)
+	case *CreditPaymentNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+		f.creditPayment(ctx, client, n.CreditPayment.UUID, &res)
+	case *PaymentNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+		f.transaction(ctx, client, n.Transaction.UUID, &res)
+		f.invoice(ctx, client, n.Transaction.InvoiceNumber, &res)
+		f.subscription(ctx, client, n.Transaction.SubscriptionUUID, &res)
+	case *NewDunningEventNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+		f.invoice(ctx, client, n.Invoice.InvoiceNumber, &res)
+		f.subscription(ctx, client, n.Subscription.UUID, &res)
+	case *GiftCardNotification:
+		f.account(ctx, client, n.GiftCard.GifterAccountCode, &res)
+	case *UsageNotification:
+		f.account(ctx, client, n.Account.Code, &res)
+		f.subscription(ctx, client, n.Usage.SubscriptionUUID, &res)
+	}
+
+	if err := f.wait(); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// fetcher runs requests concurrently and records the first error.
+type fetcher struct {
+	wg   sync.WaitGroup
+	once sync.Once
+	err  error
+}
+
+// run calls fn in a new goroutine.
+func (f *fetcher) run(fn func() error) {
+	f.wg.Add(1)
+	go func() {
+		defer f.wg.Done()
+		if err := fn(); err != nil {
+			f.once.Do(func() { f.err = err })
+		}
+	}()
+}
+
+// wait waits for all requests to complete and returns the first error.
+func (f *fetcher) wait() error {
+	f.wg.Wait()
+	return f.err
+}
+
+func (f *fetcher) account(ctx context.Context, client *recurly.Client, code string, res *Resources) {
+	if code == "" {
+		return
+	}
+	f.run(func() (err error) {
+		res.Account, err = client.Accounts.Get(ctx, code)
+		return err
+	})
+}
+
+func (f *fetcher) subscription(ctx context.Context, client *recurly.Client, uuid string, res *Resources) {
+	if uuid == "" {
+		return
+	}
+	f.run(func() (err error) {
+		res.Subscription, err = client.Subscriptions.Get(ctx, uuid)
+		return err
+	})
+}
+
+func (f *fetcher) invoice(ctx context.Context, client *recurly.Client, invoiceNumber int, res *Resources) {
+	if invoiceNumber == 0 {
+		return
+	}
+	f.run(func() (err error) {
+		res.Invoice, err = client.Invoices.Get(ctx, invoiceNumber)
+		return err
+	})
+}
+
+func (f *fetcher) transaction(ctx context.Context, client *recurly.Client, uuid string, res *Resources) {
+	if uuid == "" {
+		return
+	}
+	f.run(func() (err error) {
+		res.Transaction, err = client.Transactions.Get(ctx, uuid)
+		return err
+	})
+}
+
+func (f *fetcher) creditPayment(ctx context.Context, client *recurly.Client, uuid string, res *Resources) {
+	if uuid == "" {
+		return
+	}
+	f.run(func() (err error) {
+		res.CreditPayment, err = client.CreditPayments.Get(ctx, uuid)
+		return err
+	})
+}
@@ -0,0 +1,127 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/blacklightcms/recurly"
+	"github.com/blacklightcms/recurly/mock"
+	"github.com/blacklightcms/recurly/webhooks"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEnrich(t *testing.T) {
+	newClient := func() *mock.Client {
+		client := mock.NewClient("foo", "bar")
+		client.Accounts.OnGet = func(ctx context.Context, code string) (*recurly.Account, error) {
+			return &recurly.Account{Code: code}, nil
+		}
+		client.Subscriptions.OnGet = func(ctx context.Context, uuid string) (*recurly.Subscription, error) {
+			return &recurly.Subscription{UUID: uuid}, nil
+		}
+		client.Invoices.OnGet = func(ctx context.Context, invoiceNumber int) (*recurly.Invoice, error) {
+			return &recurly.Invoice{InvoiceNumber: invoiceNumber}, nil
+		}
+		client.Transactions.OnGet = func(ctx context.Context, uuid string) (*recurly.Transaction, error) {
+			return &recurly.Transaction{UUID: uuid}, nil
+		}
+		client.CreditPayments.OnGet = func(ctx context.Context, uuid string) (*recurly.CreditPayment, error) {
+			return &recurly.CreditPayment{UUID: uuid}, nil
+		}
+		return client
+	}
+
+	t.Run("Payment", func(t *testing.T) {
+		client := newClient()
+		res, err := webhooks.Enrich(context.Background(), client.Client, MustParseFile("testdata/successful_payment_notification_full.xml"))
+		if err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(res, &webhooks.Resources{
+			Account:      &recurly.Account{Code: "1"},
+			Subscription: &recurly.Subscription{UUID: "6027ae4fc5a0c6ac3d8df64ad7b7a8d7"},
+			Invoice:      &recurly.Invoice{InvoiceNumber: 1427},
+			Transaction:  &recurly.Transaction{UUID: "6027ae4ff3ed18e4ed7d5c4f06b02c4e"},
+		}); diff != "" {
+			t.Fatal(diff)
+		} else if client.CreditPayments.GetInvoked {
+			t.Fatal("unexpected credit payment fetch")
+		}
+	})
+
+	t.Run("CreditPayment", func(t *testing.T) {
+		client := newClient()
+		res, err := webhooks.Enrich(context.Background(), client.Client, MustParseFile("testdata/credit_payment_notification.xml"))
+		if err != nil {
+			t.Fatal(err)
+		} else if res.Account == nil || res.CreditPayment == nil {
+			t.Fatalf("unexpected resources: %#v", res)
+		} else if res.Invoice != nil || res.Subscription != nil || res.Transaction != nil {
+			t.Fatalf("unexpected resources: %#v", res)
+		}
+	})
+
+	// Ensure deleted resources are left nil.
+	t.Run("NotFound", func(t *testing.T) {
+		client := newClient()
+		client.Subscriptions.OnGet = func(ctx context.Context, uuid string) (*recurly.Subscription, error) {
+			return nil, nil
+		}
+
+		res, err := webhooks.Enrich(context.Background(), client.Client, MustParseFile("testdata/new_subscription_notification.xml"))
+		if err != nil {
+			t.Fatal(err)
+		} else if !client.Subscriptions.GetInvoked {
+			t.Fatal("expected subscription fetch")
+		} else if res.Account == nil {
+			t.Fatal("expected account")
+		} else if res.Subscription != nil {
+			t.Fatalf("unexpected subscription: %#v", res.Subscription)
+		}
+	})
+
+	t.Run("GiftCard", func(t *testing.T) {
+		res, err := webhooks.Enrich(context.Background(), newClient().Client, MustParseFile("testdata/purchased_gift_card_notification.xml"))
+		if err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(res, &webhooks.Resources{
+			Account: &recurly.Account{Code: "1"},
+		}); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Usage", func(t *testing.T) {
+		res, err := webhooks.Enrich(context.Background(), newClient().Client, MustParseFile("testdata/new_usage_notification.xml"))
+		if err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(res, &webhooks.Resources{
+			Account:      &recurly.Account{Code: "1"},
+			Subscription: &recurly.Subscription{UUID: "4ff8726e7ee6a4fcc2dbb24ee2bcc6a7"},
+		}); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		res, err := webhooks.Enrich(context.Background(), newClient().Client, MustParseFile("testdata/new_item_notification.xml"))
+		if err != nil {
+			t.Fatal(err)
+		} else if diff := cmp.Diff(res, &webhooks.Resources{}); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("Err", func(t *testing.T) {
+		client := newClient()
+		client.Invoices.OnGet = func(ctx context.Context, invoiceNumber int) (*recurly.Invoice, error) {
+			return nil, errors.New("failed")
+		}
+
+		if res, err := webhooks.Enrich(context.Background(), client.Client, MustParseFile("testdata/charge_invoice_notification.xml")); err == nil || err.Error() != "failed" {
+			t.Fatalf("unexpected error: %v", err)
+		} else if res != nil {
+			t.Fatalf("unexpected resources: %#v", res)
+		}
+	})
+}
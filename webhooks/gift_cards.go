@@ -0,0 +1,45 @@
+package webhooks
+
+import (
+	"encoding/xml"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// Gift card notifications.
+// https://dev.recurly.com/page/webhooks#gift-card-notifications
+const (
+	PurchasedGiftCard      = "purchased_gift_card_notification"
+	CanceledGiftCard       = "canceled_gift_card_notification"
+	UpdatedGiftCard        = "updated_gift_card_notification"
+	RegeneratedGiftCard    = "regenerated_gift_card_notification"
+	RedeemedGiftCard       = "redeemed_gift_card_notification"
+	UpdatedBalanceGiftCard = "updated_balance_gift_card_notification"
+	LowBalanceGiftCard     = "low_balance_gift_card_notification"
+)
+
+// GiftCardNotification is returned for all gift card notifications.
+type GiftCardNotification struct {
+	Type     string   `xml:"-"`
+	GiftCard GiftCard `xml:"gift_card"`
+}
+
+// GiftCard represents the gift card object sent in webhooks.
+type GiftCard struct {
+	XMLName              xml.Name                  `xml:"gift_card"`
+	ID                   int64                     `xml:"id"`
+	RedemptionCode       string                    `xml:"redemption_code"`
+	ProductCode          string                    `xml:"product_code"`
+	UnitAmountInCents    int                       `xml:"unit_amount_in_cents"`
+	BalanceInCents       int                       `xml:"balance_in_cents"`
+	Currency             string                    `xml:"currency"`
+	GifterAccountCode    string                    `xml:"gifter_account_code"`
+	RecipientAccountCode string                    `xml:"recipient_account_code"`
+	InvoiceNumber        int                       `xml:"invoice_number"`
+	Delivery             *recurly.GiftCardDelivery `xml:"delivery"`
+	CreatedAt            recurly.NullTime          `xml:"created_at"`
+	UpdatedAt            recurly.NullTime          `xml:"updated_at"`
+	DeliveredAt          recurly.NullTime          `xml:"delivered_at"`
+	RedeemedAt           recurly.NullTime          `xml:"redeemed_at"`
+	CanceledAt           recurly.NullTime          `xml:"canceled_at"`
+}
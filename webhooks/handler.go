@@ -0,0 +1,308 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// DefaultMaxBodyBytes is the default maximum size of a webhook request body
+// accepted by Handler.
+const DefaultMaxBodyBytes = 1 << 20 // 1 MB
+
+// ErrBodyTooLarge is reported to Handler.OnError when a request body exceeds
+// Handler.MaxBodyBytes.
+var ErrBodyTooLarge = errors.New("webhook body too large")
+
+// ErrUnsupportedMediaType is reported to Handler.OnError when a request has
+// a JSON Content-Type. Only XML webhooks are supported.
+var ErrUnsupportedMediaType = errors.New("webhook media type not supported")
+
+// HandlerFunc handles a parsed notification. The notification is one of the
+// notification structs returned by Parse. Returning a non-nil error responds
+// with a 500 so Recurly will retry the webhook.
+type HandlerFunc func(ctx context.Context, notification interface{}) error
+
+// Handler is an http.Handler that parses incoming webhooks and dispatches
+// them to registered callbacks.
+//
+// Callbacks registered for a notification name (e.g. NewAccount) take
+// precedence over callbacks registered for a notification struct (e.g.
+// HandleAccount without names). Known notifications without a callback are
+// acknowledged with a 200. The typed Handle methods panic if given a name that
+// Parse does not return as their notification struct.
+//
+// Handler responds with:
+//   - 200 when the notification was handled (or ignored).
+//   - 400 when the body cannot be parsed.
+//   - 405 when the request method is not POST.
+//   - 413 when the body exceeds MaxBodyBytes.
+//   - 415 when the Content-Type is JSON. Only XML webhooks are supported.
+//   - 500 when a callback returns an error, so Recurly will retry.
+//
+// NOTE: Handler does not validate the source of the webhook. See Parse.
+type Handler struct {
+	// MaxBodyBytes limits the size of request bodies. If zero,
+	// DefaultMaxBodyBytes is used.
+	MaxBodyBytes int64
+
+	// UnknownStatus is the status code returned for notifications that
+	// Parse does not recognize (ErrUnknownNotification). If zero, the
+	// notification is acknowledged with a 200 so Recurly does not retry it.
+	UnknownStatus int
+
+	// OnError, if set, is called with any error that results in a non-2xx
+	// response. This is useful for logging.
+	OnError func(r *http.Request, err error)
+
+	// Dedup, if set, skips or flags notifications that have already been
+	// processed before callbacks run.
+	Dedup *Deduplicator
+
+	names map[string]HandlerFunc
+	types map[reflect.Type]HandlerFunc
+}
+
+// NewHandler returns a new Handler with no callbacks registered. The zero
+// value of Handler is also ready to use.
+func NewHandler() *Handler {
+	return &Handler{}
+}
+
+// Handle registers fn for the notification name (e.g. NewAccount).
+func (h *Handler) Handle(name string, fn HandlerFunc) {
+	if h.names == nil {
+		h.names = make(map[string]HandlerFunc)
+	}
+	h.names[name] = fn
+}
+
+// HandleAccount registers fn for account notifications. If names are
+// provided, fn is only called for those notifications. Otherwise fn is called
+// for all notifications returned as *AccountNotification.
+func (h *Handler) HandleAccount(fn func(ctx context.Context, n *AccountNotification) error, names ...string) {
+	h.register(&AccountNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*AccountNotification))
+	}, names)
+}
+
+// HandleSubscription registers fn for subscription notifications. If names
+// are provided, fn is only called for those notifications. Otherwise fn is
+// called for all notifications returned as *SubscriptionNotification.
+func (h *Handler) HandleSubscription(fn func(ctx context.Context, n *SubscriptionNotification) error, names ...string) {
+	h.register(&SubscriptionNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*SubscriptionNotification))
+	}, names)
+}
+
+// HandleChargeInvoice registers fn for charge invoice notifications. If names
+// are provided, fn is only called for those notifications. Otherwise fn is
+// called for all notifications returned as *ChargeInvoiceNotification.
+func (h *Handler) HandleChargeInvoice(fn func(ctx context.Context, n *ChargeInvoiceNotification) error, names ...string) {
+	h.register(&ChargeInvoiceNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*ChargeInvoiceNotification))
+	}, names)
+}
+
+// HandleCreditInvoice registers fn for credit invoice notifications. If names
+// are provided, fn is only called for those notifications. Otherwise fn is
+// called for all notifications returned as *CreditInvoiceNotification.
+func (h *Handler) HandleCreditInvoice(fn func(ctx context.Context, n *CreditInvoiceNotification) error, names ...string) {
+	h.register(&CreditInvoiceNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*CreditInvoiceNotification))
+	}, names)
+}
+
+// HandleCreditPayment registers fn for credit payment notifications. If names
+// are provided, fn is only called for those notifications. Otherwise fn is
+// called for all notifications returned as *CreditPaymentNotification.
+func (h *Handler) HandleCreditPayment(fn func(ctx context.Context, n *CreditPaymentNotification) error, names ...string) {
+	h.register(&CreditPaymentNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*CreditPaymentNotification))
+	}, names)
+}
+
+// HandlePayment registers fn for payment notifications. If names are
+// provided, fn is only called for those notifications. Otherwise fn is called
+// for all notifications returned as *PaymentNotification.
+func (h *Handler) HandlePayment(fn func(ctx context.Context, n *PaymentNotification) error, names ...string) {
+	h.register(&PaymentNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*PaymentNotification))
+	}, names)
+}
+
+// HandleGiftCard registers fn for gift card notifications. If names are
+// provided, fn is only called for those notifications. Otherwise fn is called
+// for all notifications returned as *GiftCardNotification.
+func (h *Handler) HandleGiftCard(fn func(ctx context.Context, n *GiftCardNotification) error, names ...string) {
+	h.register(&GiftCardNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*GiftCardNotification))
+	}, names)
+}
+
+// HandleItem registers fn for item notifications. If names are provided, fn
+// is only called for those notifications. Otherwise fn is called for all
+// notifications returned as *ItemNotification.
+func (h *Handler) HandleItem(fn func(ctx context.Context, n *ItemNotification) error, names ...string) {
+	h.register(&ItemNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*ItemNotification))
+	}, names)
+}
+
+// HandleUsage registers fn for usage notifications. If names are provided,
+// fn is only called for those notifications. Otherwise fn is called for all
+// notifications returned as *UsageNotification.
+func (h *Handler) HandleUsage(fn func(ctx context.Context, n *UsageNotification) error, names ...string) {
+	h.register(&UsageNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*UsageNotification))
+	}, names)
+}
+
+// HandleNewDunningEvent registers fn for new dunning event notifications.
+func (h *Handler) HandleNewDunningEvent(fn func(ctx context.Context, n *NewDunningEventNotification) error) {
+	h.register(&NewDunningEventNotification{}, func(ctx context.Context, n interface{}) error {
+		return fn(ctx, n.(*NewDunningEventNotification))
+	}, nil)
+}
+
+// register registers fn for names, or for the type of v if no names are
+// provided. It panics if a name is not parsed as the type of v, as fn would
+// otherwise fail when the notification is delivered.
+func (h *Handler) register(v interface{}, fn HandlerFunc, names []string) {
+	typ := reflect.TypeOf(v)
+	if len(names) == 0 {
+		if h.types == nil {
+			h.types = make(map[reflect.Type]HandlerFunc)
+		}
+		h.types[typ] = fn
+		return
+	}
+
+	for _, name := range names {
+		if n, err := nameToNotification(name); err != nil {
+			panic(fmt.Sprintf("webhooks: cannot register %s: %v", typ, err))
+		} else if reflect.TypeOf(n) != typ {
+			panic(fmt.Sprintf("webhooks: cannot register %s for %q, which is parsed as %T", typ, name, n))
+		}
+	}
+	for _, name := range names {
+		h.Handle(name, fn)
+	}
+}
+
+// lookup returns the callback for the notification, or nil if there is none.
+func (h *Handler) lookup(name string, n interface{}) HandlerFunc {
+	if fn, ok := h.names[name]; ok {
+		return fn
+	}
+	return h.types[reflect.TypeOf(n)]
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, ok := h.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	n, raw, err := ParseRaw(bytes.NewReader(b))
+	if e, ok := err.(ErrUnknownNotification); ok {
+		code := h.UnknownStatus
+		if code == 0 {
+			code = http.StatusOK
+		}
+		if code >= 300 {
+			h.error(w, r, e, code)
+			return
+		}
+		w.WriteHeader(code)
+		return
+	} else if err != nil {
+		h.error(w, r, err, http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), n, raw); err != nil {
+		h.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// readBody reads the body of a webhook request. If the method is not POST,
+// the Content-Type is JSON, or the body cannot be read, the error is
+// written to w and false is returned.
+func (h *Handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return nil, false
+	} else if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		h.error(w, r, ErrUnsupportedMediaType, http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	max := h.MaxBodyBytes
+	if max <= 0 {
+		max = DefaultMaxBodyBytes
+	}
+
+	defer r.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+	if err != nil {
+		h.error(w, r, err, http.StatusBadRequest)
+		return nil, false
+	} else if int64(len(b)) > max {
+		h.error(w, r, ErrBodyTooLarge, http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	return b, true
+}
+
+// dispatch calls the callback registered for n, if any.
+func (h *Handler) dispatch(ctx context.Context, n interface{}, raw []byte) error {
+	fn := h.lookup(notificationType(n), n)
+	if fn == nil {
+		return nil
+	} else if h.Dedup != nil {
+		fn = h.Dedup.Wrap(fn)
+	}
+	return fn(context.WithValue(ctx, rawXMLKey{}, raw), n)
+}
+
+type rawXMLKey struct{}
+
+// RawXML returns the raw XML of the notification being handled, for reading
+// fields that are not yet modeled. It returns nil if ctx was not passed to a
+// callback by Handler.
+func RawXML(ctx context.Context) []byte {
+	b, _ := ctx.Value(rawXMLKey{}).([]byte)
+	return b
+}
+
+// error reports err to OnError (if set) and writes the status code.
+func (h *Handler) error(w http.ResponseWriter, r *http.Request, err error, code int) {
+	if h.OnError != nil {
+		h.OnError(r, err)
+	}
+	http.Error(w, http.StatusText(code), code)
+}
+
+// notificationType returns the Type field of a notification returned by Parse.
+func notificationType(n interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(n))
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	if f := v.FieldByName("Type"); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
@@ -0,0 +1,241 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+func TestHandler(t *testing.T) {
+	type ctxKey struct{}
+
+	t.Run("Name", func(t *testing.T) {
+		h := webhooks.NewHandler()
+
+		var invoked bool
+		h.Handle(webhooks.NewAccount, func(ctx context.Context, n interface{}) error {
+			invoked = true
+			if v := ctx.Value(ctxKey{}); v != "value" {
+				t.Fatalf("unexpected context value: %v", v)
+			} else if n, ok := n.(*webhooks.AccountNotification); !ok {
+				t.Fatalf("unexpected type: %T", n)
+			} else if n.Account.Code != "1" {
+				t.Fatalf("unexpected account code: %q", n.Account.Code)
+			}
+			return nil
+		})
+
+		r := MustNewRequest("testdata/new_account_notification.xml")
+		r = r.WithContext(context.WithValue(r.Context(), ctxKey{}, "value"))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if !invoked {
+			t.Fatal("expected fn invocation")
+		}
+	})
+
+	t.Run("Type", func(t *testing.T) {
+		h := webhooks.NewHandler()
+
+		var typed, named string
+		h.HandleSubscription(func(ctx context.Context, n *webhooks.SubscriptionNotification) error {
+			typed = n.Type
+			return nil
+		})
+		h.HandleSubscription(func(ctx context.Context, n *webhooks.SubscriptionNotification) error {
+			named = n.Type
+			return nil
+		}, webhooks.RenewedSubscription)
+
+		for _, file := range []string{
+			"testdata/new_subscription_notification.xml",
+			"testdata/renewed_subscription_notification.xml",
+		} {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, MustNewRequest(file))
+			if w.Code != http.StatusOK {
+				t.Fatalf("unexpected status: %d", w.Code)
+			}
+		}
+
+		if typed != webhooks.NewSubscription {
+			t.Fatalf("unexpected type: %q", typed)
+		} else if named != webhooks.RenewedSubscription {
+			t.Fatalf("unexpected type: %q", named)
+		}
+	})
+
+	// Ensure the zero value of Handler is usable.
+	t.Run("ZeroValue", func(t *testing.T) {
+		var h webhooks.Handler
+		var invoked bool
+		h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+			invoked = true
+			return nil
+		}, webhooks.NewAccount)
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("testdata/new_account_notification.xml"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if !invoked {
+			t.Fatal("expected fn invocation")
+		}
+	})
+
+	// Ensure names parsed as another type are rejected when registered.
+	t.Run("ErrRegister", func(t *testing.T) {
+		for _, name := range []string{webhooks.NewSubscription, webhooks.ReactivatedAccount, "unknown"} {
+			func() {
+				defer func() {
+					if r := recover(); r == nil {
+						t.Fatalf("expected panic for %q", name)
+					}
+				}()
+				webhooks.NewHandler().HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+					return nil
+				}, name)
+			}()
+		}
+	})
+
+	// Ensure known notifications without a callback are acknowledged.
+	t.Run("Unhandled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		webhooks.NewHandler().ServeHTTP(w, MustNewRequest("testdata/successful_payment_notification.xml"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	})
+
+	// Ensure callback errors return a 5xx so Recurly retries.
+	t.Run("ErrCallback", func(t *testing.T) {
+		h := webhooks.NewHandler()
+		h.HandlePayment(func(ctx context.Context, n *webhooks.PaymentNotification) error {
+			return errors.New("db unavailable")
+		})
+
+		var reported error
+		h.OnError = func(r *http.Request, err error) { reported = err }
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("testdata/successful_payment_notification.xml"))
+		if w.Code != http.StatusInternalServerError {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if reported == nil || reported.Error() != "db unavailable" {
+			t.Fatalf("unexpected error: %v", reported)
+		}
+	})
+
+	t.Run("ErrUnknownNotification", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		webhooks.NewHandler().ServeHTTP(w, MustNewRequest("testdata/unknown_notification.xml"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+
+		h := webhooks.NewHandler()
+		h.UnknownStatus = http.StatusUnprocessableEntity
+
+		var reported error
+		h.OnError = func(r *http.Request, err error) { reported = err }
+
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("testdata/unknown_notification.xml"))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if _, ok := reported.(webhooks.ErrUnknownNotification); !ok {
+			t.Fatalf("unexpected error: %#v", reported)
+		}
+	})
+
+	t.Run("ErrBodyTooLarge", func(t *testing.T) {
+		h := webhooks.NewHandler()
+		h.MaxBodyBytes = 16
+
+		var reported error
+		h.OnError = func(r *http.Request, err error) { reported = err }
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("testdata/new_account_notification.xml"))
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if reported != webhooks.ErrBodyTooLarge {
+			t.Fatalf("unexpected error: %v", reported)
+		}
+	})
+
+	t.Run("RawXML", func(t *testing.T) {
+		b, err := ioutil.ReadFile("testdata/new_account_notification.xml")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		h := webhooks.NewHandler()
+		var raw []byte
+		h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+			raw = webhooks.RawXML(ctx)
+			return nil
+		})
+
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, MustNewRequest("testdata/new_account_notification.xml"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if !bytes.Equal(raw, b) {
+			t.Fatalf("unexpected raw xml: %s", raw)
+		} else if webhooks.RawXML(context.Background()) != nil {
+			t.Fatal("expected nil raw xml")
+		}
+	})
+
+	t.Run("ErrMalformed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		webhooks.NewHandler().ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("<not xml")))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	})
+
+	t.Run("ErrUnsupportedMediaType", func(t *testing.T) {
+		h := webhooks.NewHandler()
+		var reported error
+		h.OnError = func(r *http.Request, err error) { reported = err }
+
+		r := httptest.NewRequest("POST", "/", strings.NewReader(`{"object_type":"account","event_type":"created"}`))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if reported != webhooks.ErrUnsupportedMediaType {
+			t.Fatalf("unexpected error: %v", reported)
+		}
+	})
+
+	t.Run("ErrMethodNotAllowed", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		webhooks.NewHandler().ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+	})
+}
+
+// MustNewRequest returns a POST request with the contents of file as the body.
+func MustNewRequest(file string) *http.Request {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		panic(err)
+	}
+	return httptest.NewRequest("POST", "/", bytes.NewReader(b))
+}
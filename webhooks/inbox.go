@@ -0,0 +1,467 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Inbox event states.
+const (
+	EventPending   = "pending"
+	EventProcessed = "processed"
+	EventDead      = "dead"
+)
+
+// Inbox defaults.
+const (
+	DefaultInboxMaxAttempts  = 5
+	DefaultInboxPollInterval = time.Second
+)
+
+// Event is a raw webhook stored in an inbox.
+type Event struct {
+	ID            string
+	Type          string // notification name, e.g. NewAccount
+	ReceivedAt    time.Time
+	Header        http.Header // without Authorization or SignatureHeader
+	Body          []byte
+	State         string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+}
+
+// EventQuery filters events returned by an InboxStore. Zero values match
+// all events.
+type EventQuery struct {
+	Types  []string
+	States []string
+	Since  time.Time // inclusive
+	Until  time.Time // exclusive
+}
+
+// Match returns true if e matches q.
+func (q EventQuery) Match(e *Event) bool {
+	if len(q.Types) > 0 && !containsString(q.Types, e.Type) {
+		return false
+	} else if len(q.States) > 0 && !containsString(q.States, e.State) {
+		return false
+	} else if !q.Since.IsZero() && e.ReceivedAt.Before(q.Since) {
+		return false
+	} else if !q.Until.IsZero() && !e.ReceivedAt.Before(q.Until) {
+		return false
+	}
+	return true
+}
+
+// InboxStore persists inbox events. Implementations must be safe for
+// concurrent use.
+type InboxStore interface {
+	// Insert stores a new event.
+	Insert(ctx context.Context, e *Event) error
+
+	// Update replaces the stored event with the same ID.
+	Update(ctx context.Context, e *Event) error
+
+	// Get returns the event with id, or nil if it does not exist.
+	Get(ctx context.Context, id string) (*Event, error)
+
+	// Find returns the events matching q ordered by ReceivedAt.
+	Find(ctx context.Context, q EventQuery) ([]*Event, error)
+}
+
+// Inbox is an http.Handler that stores webhooks before acknowledging them
+// and processes them asynchronously, so notifications are not lost when
+// callbacks fail after Recurly has been sent a response.
+//
+// Requests are parsed to determine the notification type and stored as
+// pending events. Inbox responds with a 200 once the event is stored, a 500
+// if it cannot be stored, a 400 if the body cannot be parsed, and a 415 if
+// the Content-Type is JSON, since only XML webhooks are supported. Unknown
+// notifications are stored so they can be replayed once supported.
+//
+// Run processes pending events with the callbacks registered on Handler.
+// Failed events are retried with Backoff until MaxAttempts is reached, at
+// which point they are dead-lettered. Replay reprocesses stored events.
+type Inbox struct {
+	// Store persists events.
+	Store InboxStore
+
+	// Handler holds the callbacks used to process events. Its MaxBodyBytes
+	// and OnError settings also apply to requests received by the Inbox.
+	// If nil, a Handler with no callbacks is used.
+	Handler *Handler
+
+	// Workers is the number of events processed concurrently by Run.
+	// Defaults to 1.
+	Workers int
+
+	// MaxAttempts is the number of times an event is processed before it
+	// is dead-lettered. Defaults to DefaultInboxMaxAttempts.
+	MaxAttempts int
+
+	// Backoff returns the delay before retrying an event that has failed
+	// attempts times. Defaults to doubling from one second up to an hour.
+	Backoff func(attempts int) time.Duration
+
+	// PollInterval is how often Run checks the store for events that are
+	// due. Defaults to DefaultInboxPollInterval.
+	PollInterval time.Duration
+
+	// OnError, if set, is called when processing an event fails.
+	OnError func(e *Event, err error)
+
+	once  sync.Once
+	queue chan string
+
+	mu     sync.Mutex
+	active map[string]chan struct{} // closed when the event is released
+}
+
+// NewInbox returns an Inbox that stores events in store and processes them
+// with h. An Inbox may also be created directly with Store and Handler set.
+func NewInbox(store InboxStore, h *Handler) *Inbox {
+	return &Inbox{
+		Store:   store,
+		Handler: h,
+	}
+}
+
+// init creates the queue of events to process.
+func (i *Inbox) init() {
+	i.once.Do(func() {
+		i.queue = make(chan string, 64)
+	})
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (i *Inbox) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	i.init()
+	h := i.handler()
+	b, ok := h.readBody(w, r)
+	if !ok {
+		return
+	}
+
+	var typ string
+	n, _, err := ParseRaw(bytes.NewReader(b))
+	if e, ok := err.(ErrUnknownNotification); ok {
+		typ = e.Name()
+	} else if err != nil {
+		h.error(w, r, err, http.StatusBadRequest)
+		return
+	} else {
+		typ = notificationType(n)
+	}
+
+	id, err := newEventID()
+	if err != nil {
+		h.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+
+	e := &Event{
+		ID:         id,
+		Type:       typ,
+		ReceivedAt: time.Now().UTC(),
+		Header:     scrubHeader(r.Header),
+		Body:       b,
+		State:      EventPending,
+	}
+	if err := i.Store.Insert(r.Context(), e); err != nil {
+		h.error(w, r, err, http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+
+	// Process the event immediately if a worker is available. Otherwise it
+	// is picked up by the next poll.
+	select {
+	case i.queue <- e.ID:
+	default:
+	}
+}
+
+// Run processes pending events until ctx is canceled, and returns
+// ctx.Err(). Events left pending by a previous process are recovered on
+// the first poll.
+func (i *Inbox) Run(ctx context.Context) error {
+	i.init()
+	workers := i.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+	interval := i.PollInterval
+	if interval <= 0 {
+		interval = DefaultInboxPollInterval
+	}
+
+	var wg sync.WaitGroup
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case id := <-i.queue:
+					i.process(ctx, id)
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		i.poll(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+	}
+}
+
+// poll queues pending events that are due.
+func (i *Inbox) poll(ctx context.Context) {
+	events, err := i.Store.Find(ctx, EventQuery{States: []string{EventPending}})
+	if err != nil {
+		i.reportError(nil, err)
+		return
+	}
+
+	now := time.Now()
+	for _, e := range events {
+		if e.NextAttemptAt.After(now) {
+			continue
+		}
+		select {
+		case i.queue <- e.ID:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// process processes the pending event with id unless it is already being
+// processed by another worker or Replay.
+func (i *Inbox) process(ctx context.Context, id string) {
+	if _, ok := i.claim(id); !ok {
+		return
+	}
+	defer i.release(id)
+
+	// Reload the event since it may have been processed since it was queued.
+	e, err := i.Store.Get(ctx, id)
+	if err != nil {
+		i.reportError(nil, err)
+		return
+	} else if e == nil || e.State != EventPending || e.NextAttemptAt.After(time.Now()) {
+		return
+	}
+
+	e.Attempts++
+	err = i.handle(ctx, e)
+	switch err.(type) {
+	case nil:
+		e.State, e.LastError = EventProcessed, ""
+	case permanentError:
+		e.State, e.LastError = EventDead, err.Error()
+	default:
+		e.LastError = err.Error()
+		if e.Attempts >= i.maxAttempts() {
+			e.State = EventDead
+		} else {
+			e.NextAttemptAt = time.Now().Add(i.backoff(e.Attempts))
+		}
+	}
+
+	if err != nil {
+		i.reportError(e, err)
+	}
+	if err := i.Store.Update(ctx, e); err != nil {
+		i.reportError(e, err)
+	}
+}
+
+// Replay reprocesses the stored events matching q in the order they were
+// received, regardless of their state, and marks them processed. Callbacks
+// are passed a context for which IsReplay returns true. Replay stops at the
+// first event that fails and returns the number of events replayed. Events
+// being processed by Run are replayed once processing finishes.
+//
+// NOTE: If Handler.Dedup is set, notifications that were processed
+// successfully are skipped unless Dedup.Flag is set.
+func (i *Inbox) Replay(ctx context.Context, q EventQuery) (int, error) {
+	events, err := i.Store.Find(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx = context.WithValue(ctx, replayKey{}, true)
+	for n, e := range events {
+		if err := i.replay(ctx, e.ID); err != nil {
+			return n, err
+		}
+	}
+	return len(events), nil
+}
+
+// replay claims the event with id, waiting for any worker processing it to
+// finish, and reprocesses it.
+func (i *Inbox) replay(ctx context.Context, id string) error {
+	for {
+		done, ok := i.claim(id)
+		if ok {
+			break
+		}
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	defer i.release(id)
+
+	// Reload the event since a worker may have updated it.
+	e, err := i.Store.Get(ctx, id)
+	if err != nil {
+		return err
+	} else if e == nil {
+		return nil
+	}
+
+	e.Attempts++
+	if err := i.handle(ctx, e); err != nil {
+		e.LastError = err.Error()
+		if updateErr := i.Store.Update(ctx, e); updateErr != nil {
+			i.reportError(e, updateErr)
+		}
+		return err
+	}
+
+	e.State, e.LastError, e.NextAttemptAt = EventProcessed, "", time.Time{}
+	return i.Store.Update(ctx, e)
+}
+
+// handle parses e and calls the callback registered on Handler. Events that
+// cannot be parsed return a permanentError. Unknown notifications are
+// ignored.
+func (i *Inbox) handle(ctx context.Context, e *Event) error {
+	n, raw, err := ParseRaw(bytes.NewReader(e.Body))
+	if _, ok := err.(ErrUnknownNotification); ok {
+		return nil
+	} else if err != nil {
+		return permanentError{err}
+	}
+	return i.handler().dispatch(ctx, n, raw)
+}
+
+// handler returns Handler, or a Handler with no callbacks if it is nil.
+func (i *Inbox) handler() *Handler {
+	if i.Handler == nil {
+		return &Handler{}
+	}
+	return i.Handler
+}
+
+// claim marks the event with id as being processed. If it already is, false
+// is returned with a channel that is closed when the event is released.
+func (i *Inbox) claim(id string) (<-chan struct{}, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	if done, ok := i.active[id]; ok {
+		return done, false
+	} else if i.active == nil {
+		i.active = make(map[string]chan struct{})
+	}
+	i.active[id] = make(chan struct{})
+	return nil, true
+}
+
+func (i *Inbox) release(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	close(i.active[id])
+	delete(i.active, id)
+}
+
+func (i *Inbox) maxAttempts() int {
+	if i.MaxAttempts > 0 {
+		return i.MaxAttempts
+	}
+	return DefaultInboxMaxAttempts
+}
+
+func (i *Inbox) backoff(attempts int) time.Duration {
+	if i.Backoff != nil {
+		return i.Backoff(attempts)
+	}
+
+	d := time.Second
+	for n := 1; n < attempts && d < time.Hour; n++ {
+		d *= 2
+	}
+	if d > time.Hour {
+		d = time.Hour
+	}
+	return d
+}
+
+func (i *Inbox) reportError(e *Event, err error) {
+	if i.OnError != nil {
+		i.OnError(e, err)
+	}
+}
+
+// permanentError wraps errors that retrying will not resolve.
+type permanentError struct {
+	error
+}
+
+type replayKey struct{}
+
+// IsReplay returns true if ctx was passed to a callback by Inbox.Replay.
+func IsReplay(ctx context.Context) bool {
+	v, _ := ctx.Value(replayKey{}).(bool)
+	return v
+}
+
+// scrubbedHeaders are the request headers holding credentials, which are
+// not stored with events.
+var scrubbedHeaders = []string{"Authorization", SignatureHeader}
+
+// scrubHeader returns a copy of h without scrubbedHeaders.
+func scrubHeader(h http.Header) http.Header {
+	v := h.Clone()
+	for _, key := range scrubbedHeaders {
+		v.Del(key)
+	}
+	return v
+}
+
+// newEventID returns a random event ID.
+func newEventID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func containsString(a []string, s string) bool {
+	for _, v := range a {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var _ InboxStore = &MemoryInboxStore{}
+
+// MemoryInboxStore is an in-memory InboxStore. Events are lost when the
+// process exits, so it is intended for tests and development.
+type MemoryInboxStore struct {
+	mu     sync.Mutex
+	events map[string]*Event
+}
+
+// NewMemoryInboxStore returns an empty MemoryInboxStore.
+func NewMemoryInboxStore() *MemoryInboxStore {
+	return &MemoryInboxStore{events: make(map[string]*Event)}
+}
+
+// Insert stores a copy of e.
+func (s *MemoryInboxStore) Insert(ctx context.Context, e *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.events[e.ID]; ok {
+		return fmt.Errorf("event already exists: %q", e.ID)
+	}
+	s.events[e.ID] = copyEvent(e)
+	return nil
+}
+
+// Update replaces the stored event with a copy of e.
+func (s *MemoryInboxStore) Update(ctx context.Context, e *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.events[e.ID]; !ok {
+		return fmt.Errorf("event not found: %q", e.ID)
+	}
+	s.events[e.ID] = copyEvent(e)
+	return nil
+}
+
+// Get returns a copy of the event with id, or nil if it does not exist.
+func (s *MemoryInboxStore) Get(ctx context.Context, id string) (*Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.events[id]; ok {
+		return copyEvent(e), nil
+	}
+	return nil, nil
+}
+
+// Find returns copies of the events matching q ordered by ReceivedAt.
+func (s *MemoryInboxStore) Find(ctx context.Context, q EventQuery) ([]*Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*Event
+	for _, e := range s.events {
+		if q.Match(e) {
+			events = append(events, copyEvent(e))
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].ReceivedAt.Equal(events[j].ReceivedAt) {
+			return events[i].ID < events[j].ID
+		}
+		return events[i].ReceivedAt.Before(events[j].ReceivedAt)
+	})
+	return events, nil
+}
+
+// copyEvent returns a copy of e so callers cannot modify stored events.
+func copyEvent(e *Event) *Event {
+	v := *e
+	v.Header = e.Header.Clone()
+	v.Body = append([]byte(nil), e.Body...)
+	return &v
+}
@@ -0,0 +1,386 @@
+package webhooks_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+func TestInbox(t *testing.T) {
+	t.Run("Process", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		h := webhooks.NewHandler()
+
+		invoked := make(chan string, 1)
+		h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+			invoked <- n.Account.Code
+			return nil
+		})
+
+		inbox := webhooks.NewInbox(store, h)
+		inbox.PollInterval = 10 * time.Millisecond
+
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, MustNewRequest("testdata/new_account_notification.xml"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+
+		// Ensure the event is stored before it is processed.
+		events, err := store.Find(context.Background(), webhooks.EventQuery{})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(events) != 1 {
+			t.Fatalf("unexpected events: %d", len(events))
+		} else if e := events[0]; e.Type != webhooks.NewAccount || e.State != webhooks.EventPending || len(e.Body) == 0 || e.ReceivedAt.IsZero() {
+			t.Fatalf("unexpected event: %#v", e)
+		}
+
+		stop := RunInbox(inbox)
+		defer stop()
+
+		select {
+		case code := <-invoked:
+			if code != "1" {
+				t.Fatalf("unexpected account code: %q", code)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out")
+		}
+		WaitForEventState(t, store, events[0].ID, webhooks.EventProcessed)
+	})
+
+	t.Run("DeadLetter", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		h := webhooks.NewHandler()
+		h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+			return errors.New("failed")
+		})
+
+		var mu sync.Mutex
+		var errs int
+		inbox := webhooks.NewInbox(store, h)
+		inbox.PollInterval = time.Millisecond
+		inbox.MaxAttempts = 3
+		inbox.Backoff = func(attempts int) time.Duration { return 0 }
+		inbox.OnError = func(e *webhooks.Event, err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs++
+		}
+
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, MustNewRequest("testdata/new_account_notification.xml"))
+		events, _ := store.Find(context.Background(), webhooks.EventQuery{})
+
+		stop := RunInbox(inbox)
+		e := WaitForEventState(t, store, events[0].ID, webhooks.EventDead)
+		stop()
+
+		if e.Attempts != 3 {
+			t.Fatalf("unexpected attempts: %d", e.Attempts)
+		} else if e.LastError != "failed" {
+			t.Fatalf("unexpected error: %q", e.LastError)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if errs != 3 {
+			t.Fatalf("unexpected number of errors: %d", errs)
+		}
+	})
+
+	// Ensure an Inbox created without NewInbox processes events.
+	t.Run("Literal", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		h := webhooks.NewHandler()
+		h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+			return nil
+		})
+		inbox := &webhooks.Inbox{Store: store, Handler: h, PollInterval: 10 * time.Millisecond}
+
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, MustNewRequest("testdata/new_account_notification.xml"))
+		events, _ := store.Find(context.Background(), webhooks.EventQuery{})
+
+		stop := RunInbox(inbox)
+		defer stop()
+		WaitForEventState(t, store, events[0].ID, webhooks.EventProcessed)
+
+		if n, err := inbox.Replay(context.Background(), webhooks.EventQuery{}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("unexpected number of events replayed: %d", n)
+		}
+	})
+
+	// Ensure an Inbox without a Handler stores and processes events.
+	t.Run("NilHandler", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		inbox := webhooks.NewInbox(store, nil)
+		inbox.PollInterval = 10 * time.Millisecond
+
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, MustNewRequest("testdata/new_account_notification.xml"))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		}
+		events, _ := store.Find(context.Background(), webhooks.EventQuery{})
+		if len(events) != 1 {
+			t.Fatalf("unexpected events: %d", len(events))
+		}
+
+		stop := RunInbox(inbox)
+		defer stop()
+		WaitForEventState(t, store, events[0].ID, webhooks.EventProcessed)
+	})
+
+	// Ensure credentials are not stored with events.
+	t.Run("ScrubHeaders", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		inbox := webhooks.NewInbox(store, webhooks.NewHandler())
+
+		r := MustNewRequest("testdata/new_account_notification.xml")
+		r.SetBasicAuth("recurly", "secret")
+		r.Header.Set(webhooks.SignatureHeader, "1588284180000,abc")
+		r.Header.Set("Content-Type", "application/xml")
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, r)
+
+		events, _ := store.Find(context.Background(), webhooks.EventQuery{})
+		if len(events) != 1 {
+			t.Fatalf("unexpected events: %d", len(events))
+		} else if h := events[0].Header; h.Get("Authorization") != "" || h.Get(webhooks.SignatureHeader) != "" {
+			t.Fatalf("unexpected header: %v", h)
+		} else if h.Get("Content-Type") != "application/xml" {
+			t.Fatalf("unexpected header: %v", h)
+		} else if r.Header.Get("Authorization") == "" {
+			t.Fatal("expected request header to be unchanged")
+		}
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		inbox := webhooks.NewInbox(store, webhooks.NewHandler())
+
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("<unknown_notification></unknown_notification>")))
+		if w.Code != http.StatusOK {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if events, _ := store.Find(context.Background(), webhooks.EventQuery{Types: []string{"unknown_notification"}}); len(events) != 1 {
+			t.Fatalf("unexpected events: %d", len(events))
+		}
+	})
+
+	t.Run("ErrMalformed", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		inbox := webhooks.NewInbox(store, webhooks.NewHandler())
+
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("<not xml")))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if events, _ := store.Find(context.Background(), webhooks.EventQuery{}); len(events) != 0 {
+			t.Fatalf("unexpected events: %d", len(events))
+		}
+	})
+
+	t.Run("ErrUnsupportedMediaType", func(t *testing.T) {
+		store := webhooks.NewMemoryInboxStore()
+		inbox := webhooks.NewInbox(store, webhooks.NewHandler())
+
+		r := httptest.NewRequest("POST", "/", strings.NewReader(`{"object_type":"account","event_type":"created"}`))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		inbox.ServeHTTP(w, r)
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Fatalf("unexpected status: %d", w.Code)
+		} else if events, _ := store.Find(context.Background(), webhooks.EventQuery{}); len(events) != 0 {
+			t.Fatalf("unexpected events: %d", len(events))
+		}
+	})
+}
+
+func TestInbox_Replay(t *testing.T) {
+	ctx := context.Background()
+	store := webhooks.NewMemoryInboxStore()
+	received := MustParseTime("2020-01-01T00:00:00Z")
+	for i, file := range []string{
+		"testdata/new_account_notification.xml",
+		"testdata/successful_payment_notification.xml",
+		"testdata/updated_account_notification.xml",
+	} {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Insert(ctx, &webhooks.Event{
+			ID:         string(rune('a' + i)),
+			Type:       strings.TrimSuffix(strings.TrimPrefix(file, "testdata/"), ".xml"),
+			ReceivedAt: received.Add(time.Duration(i) * time.Hour),
+			Header:     http.Header{},
+			Body:       b,
+			State:      webhooks.EventDead,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var replayed []string
+	h := webhooks.NewHandler()
+	h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+		if !webhooks.IsReplay(ctx) {
+			t.Fatal("expected replay context")
+		}
+		replayed = append(replayed, n.Type)
+		return nil
+	})
+	inbox := webhooks.NewInbox(store, h)
+
+	n, err := inbox.Replay(ctx, webhooks.EventQuery{
+		Types: []string{webhooks.NewAccount, webhooks.UpdatedAccount},
+		Since: received,
+		Until: received.Add(3 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if n != 2 {
+		t.Fatalf("unexpected number of events replayed: %d", n)
+	} else if len(replayed) != 2 || replayed[0] != webhooks.NewAccount || replayed[1] != webhooks.UpdatedAccount {
+		t.Fatalf("unexpected notifications: %v", replayed)
+	}
+
+	if events, err := store.Find(ctx, webhooks.EventQuery{States: []string{webhooks.EventProcessed}}); err != nil {
+		t.Fatal(err)
+	} else if len(events) != 2 || events[0].ID != "a" || events[1].ID != "c" {
+		t.Fatalf("unexpected processed events: %#v", events)
+	}
+
+	// Ensure replay stops at the first failure.
+	h.HandlePayment(func(ctx context.Context, n *webhooks.PaymentNotification) error {
+		return errors.New("failed")
+	})
+	replayed = nil
+	if n, err := inbox.Replay(ctx, webhooks.EventQuery{}); err == nil || err.Error() != "failed" {
+		t.Fatalf("unexpected error: %v", err)
+	} else if n != 1 {
+		t.Fatalf("unexpected number of events replayed: %d", n)
+	} else if e, _ := store.Get(ctx, "b"); e.LastError != "failed" || e.State != webhooks.EventDead {
+		t.Fatalf("unexpected event: %#v", e)
+	}
+}
+
+// Ensure events being processed by a worker are not replayed concurrently.
+func TestInbox_ReplayWhileProcessing(t *testing.T) {
+	store := webhooks.NewMemoryInboxStore()
+	h := webhooks.NewHandler()
+
+	var mu sync.Mutex
+	var calls, active, maxActive int
+	started, release := make(chan struct{}), make(chan struct{})
+	h.HandleAccount(func(ctx context.Context, n *webhooks.AccountNotification) error {
+		mu.Lock()
+		calls++
+		first := calls == 1
+		if active++; active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		if first {
+			close(started)
+			<-release
+		}
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil
+	})
+
+	inbox := webhooks.NewInbox(store, h)
+	inbox.PollInterval = 10 * time.Millisecond
+	inbox.ServeHTTP(httptest.NewRecorder(), MustNewRequest("testdata/new_account_notification.xml"))
+
+	stop := RunInbox(inbox)
+	defer stop()
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	type result struct {
+		n   int
+		err error
+	}
+	replayed := make(chan result, 1)
+	go func() {
+		n, err := inbox.Replay(context.Background(), webhooks.EventQuery{})
+		replayed <- result{n, err}
+	}()
+
+	select {
+	case <-replayed:
+		t.Fatal("expected replay to wait for the worker")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(release)
+
+	select {
+	case r := <-replayed:
+		if r.err != nil {
+			t.Fatal(r.err)
+		} else if r.n != 1 {
+			t.Fatalf("unexpected number of events replayed: %d", r.n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Fatalf("unexpected calls: %d", calls)
+	} else if maxActive != 1 {
+		t.Fatalf("unexpected concurrent calls: %d", maxActive)
+	}
+}
+
+// RunInbox runs inbox in a goroutine and returns a function that stops it.
+func RunInbox(inbox *webhooks.Inbox) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		inbox.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// WaitForEventState waits for the event with id to reach state.
+func WaitForEventState(t *testing.T, store webhooks.InboxStore, id, state string) *webhooks.Event {
+	deadline := time.Now().Add(time.Second)
+	for {
+		e, err := store.Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		} else if e != nil && e.State == state {
+			return e
+		} else if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s event: %#v", state, e)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
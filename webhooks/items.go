@@ -0,0 +1,18 @@
+package webhooks
+
+import "github.com/blacklightcms/recurly"
+
+// Item notifications.
+// https://dev.recurly.com/page/webhooks#item-notifications
+const (
+	NewItem         = "new_item_notification"
+	UpdatedItem     = "updated_item_notification"
+	DeactivatedItem = "deactivated_item_notification"
+	ReactivatedItem = "reactivated_item_notification"
+)
+
+// ItemNotification is returned for all item notifications.
+type ItemNotification struct {
+	Type string       `xml:"-"`
+	Item recurly.Item `xml:"item"`
+}
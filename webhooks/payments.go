@@ -16,6 +16,8 @@ const (
 	ScheduledPayment         = "scheduled_payment_notification"
 	ProcessingPayment        = "processing_payment_notification"
 	TransactionStatusUpdated = "transaction_status_updated_notification"
+	TransactionAuthorized    = "transaction_authorized_notification"
+	FraudInfoUpdated         = "fraud_info_updated_notification"
 )
 
 // PaymentNotification is returned for all credit payment notifications.
@@ -29,20 +31,34 @@ type PaymentNotification struct {
 type Transaction struct {
 	XMLName           xml.Name         `xml:"transaction"`
 	UUID              string           `xml:"id"`
+	InvoiceUUID       string           `xml:"invoice_id"`
 	InvoiceNumber     int              `xml:"invoice_number"`
 	SubscriptionUUID  string           `xml:"subscription_id"`
 	Action            string           `xml:"action"`
 	PaymentMethod     string           `xml:"payment_method"`
 	AmountInCents     int              `xml:"amount_in_cents"`
+	Currency          string           `xml:"currency"`
 	Status            string           `xml:"status"`
 	Message           string           `xml:"message"`
 	GatewayErrorCodes string           `xml:"gateway_error_codes"`
 	FailureType       string           `xml:"failure_type"`
 	Reference         string           `xml:"reference"`
 	Source            string           `xml:"source"`
+	Gateway           string           `xml:"gateway"`
 	Test              recurly.NullBool `xml:"test"`
 	Voidable          recurly.NullBool `xml:"voidable"`
 	Refundable        recurly.NullBool `xml:"refundable"`
+	FraudInfo         *FraudInfo       `xml:"fraud_info"`
+	CreatedAt         recurly.NullTime `xml:"date"`
+	CollectedAt       recurly.NullTime `xml:"collected_at"`
+}
+
+// FraudInfo holds the fraud screening results for a transaction.
+type FraudInfo struct {
+	XMLName   xml.Name `xml:"fraud_info"`
+	Score     int      `xml:"score"`
+	Decision  string   `xml:"decision"`
+	Reference string   `xml:"reference"`
 }
 
 // Transaction constants.
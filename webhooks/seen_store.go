@@ -0,0 +1,145 @@
+package webhooks
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	_ SeenStore = &MemorySeenStore{}
+	_ SeenStore = &FileSeenStore{}
+)
+
+// MemorySeenStore is an in-memory SeenStore that retains the most recently
+// marked keys, evicting the least recently used key once full.
+type MemorySeenStore struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+// NewMemorySeenStore returns a MemorySeenStore that retains up to size keys.
+func NewMemorySeenStore(size int) *MemorySeenStore {
+	if size <= 0 {
+		size = 1
+	}
+	return &MemorySeenStore{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+// Seen returns true if key has been marked and not evicted.
+func (s *MemorySeenStore) Seen(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	if ok {
+		s.ll.MoveToFront(e)
+	}
+	return ok, nil
+}
+
+// Mark records key, evicting the least recently used key if the store is
+// full.
+func (s *MemorySeenStore) Mark(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e, ok := s.items[key]; ok {
+		s.ll.MoveToFront(e)
+		return nil
+	}
+
+	s.items[key] = s.ll.PushFront(key)
+	if s.ll.Len() > s.size {
+		e := s.ll.Back()
+		s.ll.Remove(e)
+		delete(s.items, e.Value.(string))
+	}
+	return nil
+}
+
+// Len returns the number of keys in the store.
+func (s *MemorySeenStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+// FileSeenStore is a SeenStore backed by an append-only file with one key
+// per line. Keys are loaded into memory when the store is opened, so the
+// store survives restarts of a single process. It is not safe for use by
+// multiple processes.
+type FileSeenStore struct {
+	mu   sync.Mutex
+	f    *os.File
+	keys map[string]struct{}
+}
+
+// OpenFileSeenStore opens (or creates) the file at path and loads its keys.
+func OpenFileSeenStore(path string) (*FileSeenStore, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{})
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if key := scanner.Text(); key != "" {
+			keys[key] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &FileSeenStore{f: f, keys: keys}, nil
+}
+
+// Seen returns true if key has been marked.
+func (s *FileSeenStore) Seen(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.keys[key]
+	return ok, nil
+}
+
+// Mark appends key to the file and syncs it to disk.
+func (s *FileSeenStore) Mark(ctx context.Context, key string) error {
+	if strings.ContainsAny(key, "\r\n") {
+		return fmt.Errorf("invalid key: %q", key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.keys[key]; ok {
+		return nil
+	}
+	if _, err := s.f.WriteString(key + "\n"); err != nil {
+		return err
+	} else if err := s.f.Sync(); err != nil {
+		return err
+	}
+	s.keys[key] = struct{}{}
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *FileSeenStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
@@ -0,0 +1,198 @@
+package webhooks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureHeader is the header carrying the webhook signature.
+//
+// The header value is a millisecond Unix timestamp followed by one or more
+// hex encoded HMAC-SHA256 signatures, separated by commas:
+//
+//	1588284180000,4e2cd7e1e32a1ac8a1ac...,b91f9a4a3f0f0d1c1e8c...
+//
+// Each signature is computed over the timestamp, a period, and the raw
+// request body using a webhook secret. More than one signature is sent while
+// secrets are being rotated.
+const SignatureHeader = "Recurly-Signature"
+
+// DefaultSignatureTolerance is the default maximum age of a signature.
+const DefaultSignatureTolerance = 5 * time.Minute
+
+// Signature verification errors.
+var (
+	ErrSignatureMissing   = errors.New("webhook signature missing")
+	ErrSignatureMalformed = errors.New("webhook signature malformed")
+	ErrSignatureExpired   = errors.New("webhook signature expired")
+	ErrSignatureMismatch  = errors.New("webhook signature mismatch")
+)
+
+// SignatureVerifier verifies webhook signatures.
+type SignatureVerifier struct {
+	// Secrets holds the webhook secrets a signature may be computed with.
+	// Multiple secrets allow a new secret to be rolled out before the old
+	// one is retired.
+	Secrets []string
+
+	// Tolerance is the maximum difference between the signature timestamp
+	// and the current time, rejecting replayed requests. If zero,
+	// DefaultSignatureTolerance is used. If negative, the timestamp is
+	// not checked.
+	Tolerance time.Duration
+
+	// Now returns the current time. If nil, time.Now is used.
+	Now func() time.Time
+
+	// MaxBodyBytes limits the size of request bodies read by Handler. If
+	// zero, DefaultMaxBodyBytes is used. Set it to at least the
+	// MaxBodyBytes of the wrapped handler.
+	MaxBodyBytes int64
+
+	// OnError, if set, is called with any error that causes Handler to
+	// reject a request. This is useful for logging.
+	OnError func(r *http.Request, err error)
+}
+
+// NewSignatureVerifier returns a verifier for the provided secrets.
+func NewSignatureVerifier(secrets ...string) *SignatureVerifier {
+	return &SignatureVerifier{Secrets: secrets}
+}
+
+// Verify verifies header (the value of SignatureHeader) against body. It
+// returns ErrSignatureMissing, ErrSignatureMalformed, ErrSignatureExpired,
+// or ErrSignatureMismatch if verification fails.
+func (v *SignatureVerifier) Verify(header string, body []byte) error {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return ErrSignatureMissing
+	}
+
+	parts := strings.Split(header, ",")
+	if len(parts) < 2 {
+		return ErrSignatureMalformed
+	}
+
+	timestamp := strings.TrimSpace(parts[0])
+	ms, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrSignatureMalformed
+	}
+
+	signatures := make([][]byte, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		sig, err := hex.DecodeString(strings.TrimSpace(p))
+		if err != nil || len(sig) != sha256.Size {
+			return ErrSignatureMalformed
+		}
+		signatures = append(signatures, sig)
+	}
+
+	if tolerance := v.tolerance(); tolerance > 0 {
+		signedAt := time.Unix(0, ms*int64(time.Millisecond))
+		if d := v.now().Sub(signedAt); d > tolerance || d < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	for _, secret := range v.Secrets {
+		expected := computeSignature(secret, timestamp, body)
+		for _, sig := range signatures {
+			if hmac.Equal(sig, expected) {
+				return nil
+			}
+		}
+	}
+	return ErrSignatureMismatch
+}
+
+// Handler returns a handler that verifies the signature of each request
+// before calling next. The request body is buffered (up to MaxBodyBytes)
+// and restored so next can read it. Malformed signatures receive a 400;
+// missing, expired, or mismatched signatures receive a 401.
+func (v *SignatureVerifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		max := v.maxBodyBytes()
+		b, err := ioutil.ReadAll(io.LimitReader(r.Body, max+1))
+		if err != nil {
+			v.error(w, r, err, http.StatusBadRequest)
+			return
+		} else if int64(len(b)) > max {
+			v.error(w, r, ErrBodyTooLarge, http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		if err := v.Verify(r.Header.Get(SignatureHeader), b); err == ErrSignatureMalformed {
+			v.error(w, r, err, http.StatusBadRequest)
+			return
+		} else if err != nil {
+			v.error(w, r, err, http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = ioutil.NopCloser(bytes.NewReader(b))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// error reports err to OnError (if set) and writes the status code.
+func (v *SignatureVerifier) error(w http.ResponseWriter, r *http.Request, err error, code int) {
+	if v.OnError != nil {
+		v.OnError(r, err)
+	}
+	http.Error(w, http.StatusText(code), code)
+}
+
+func (v *SignatureVerifier) tolerance() time.Duration {
+	if v.Tolerance == 0 {
+		return DefaultSignatureTolerance
+	}
+	return v.Tolerance
+}
+
+func (v *SignatureVerifier) now() time.Time {
+	if v.Now != nil {
+		return v.Now()
+	}
+	return time.Now()
+}
+
+func (v *SignatureVerifier) maxBodyBytes() int64 {
+	if v.MaxBodyBytes > 0 {
+		return v.MaxBodyBytes
+	}
+	return DefaultMaxBodyBytes
+}
+
+// Sign returns a SignatureHeader value for body signed at t with each of
+// secrets. This is primarily useful for testing.
+func Sign(t time.Time, body []byte, secrets ...string) string {
+	timestamp := strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10)
+
+	var b strings.Builder
+	b.WriteString(timestamp)
+	for _, secret := range secrets {
+		b.WriteString(",")
+		b.WriteString(hex.EncodeToString(computeSignature(secret, timestamp, body)))
+	}
+	return b.String()
+}
+
+// computeSignature returns the HMAC-SHA256 of timestamp and body.
+func computeSignature(secret, timestamp string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,130 @@
+package webhooks_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/blacklightcms/recurly/webhooks"
+)
+
+func TestSignatureVerifier_Verify(t *testing.T) {
+	now := time.Date(2020, time.May, 1, 0, 0, 0, 0, time.UTC)
+	body := []byte("<new_account_notification></new_account_notification>")
+
+	v := webhooks.NewSignatureVerifier("old", "new")
+	v.Now = func() time.Time { return now }
+
+	tests := []struct {
+		name   string
+		header string
+		err    error
+	}{
+		{name: "OK", header: webhooks.Sign(now, body, "new")},
+		{name: "Rotation", header: webhooks.Sign(now, body, "unknown", "old")},
+		{name: "WithinTolerance", header: webhooks.Sign(now.Add(-4*time.Minute), body, "new")},
+		{name: "Missing", header: "", err: webhooks.ErrSignatureMissing},
+		{name: "NoSignatures", header: "1588291200000", err: webhooks.ErrSignatureMalformed},
+		{name: "InvalidTimestamp", header: "abc," + strings.Repeat("0", 64), err: webhooks.ErrSignatureMalformed},
+		{name: "InvalidHex", header: "1588291200000,zz", err: webhooks.ErrSignatureMalformed},
+		{name: "Expired", header: webhooks.Sign(now.Add(-6*time.Minute), body, "new"), err: webhooks.ErrSignatureExpired},
+		{name: "Future", header: webhooks.Sign(now.Add(6*time.Minute), body, "new"), err: webhooks.ErrSignatureExpired},
+		{name: "Mismatch", header: webhooks.Sign(now, body, "unknown"), err: webhooks.ErrSignatureMismatch},
+		{name: "Tampered", header: webhooks.Sign(now, []byte("<other/>"), "new"), err: webhooks.ErrSignatureMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.Verify(tt.header, body); err != tt.err {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Ensure a negative tolerance disables the timestamp check.
+	t.Run("NoTolerance", func(t *testing.T) {
+		v := webhooks.NewSignatureVerifier("new")
+		v.Tolerance = -1
+		if err := v.Verify(webhooks.Sign(now.Add(-24*time.Hour), body, "new"), body); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestSignatureVerifier_Handler(t *testing.T) {
+	body := []byte("<new_account_notification></new_account_notification>")
+	v := webhooks.NewSignatureVerifier("secret")
+
+	var received []byte
+	h := v.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		received = b
+	}))
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{name: "OK", header: webhooks.Sign(time.Now(), body, "secret"), code: http.StatusOK},
+		{name: "Missing", code: http.StatusUnauthorized},
+		{name: "Malformed", header: "abc", code: http.StatusBadRequest},
+		{name: "Mismatch", header: webhooks.Sign(time.Now(), body, "other"), code: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = nil
+			r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+			if tt.header != "" {
+				r.Header.Set(webhooks.SignatureHeader, tt.header)
+			}
+
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			if w.Code != tt.code {
+				t.Fatalf("unexpected status: %d", w.Code)
+			} else if tt.code == http.StatusOK && !bytes.Equal(received, body) {
+				t.Fatalf("unexpected body: %s", received)
+			} else if tt.code != http.StatusOK && received != nil {
+				t.Fatal("unexpected invocation")
+			}
+		})
+	}
+}
+
+func TestSignatureVerifier_Handler_MaxBodyBytes(t *testing.T) {
+	body := bytes.Repeat([]byte("a"), webhooks.DefaultMaxBodyBytes+1)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name string
+		max  int64
+		code int
+	}{
+		{name: "Default", code: http.StatusRequestEntityTooLarge},
+		{name: "Raised", max: 2 * webhooks.DefaultMaxBodyBytes, code: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := webhooks.NewSignatureVerifier("secret")
+			v.MaxBodyBytes = tt.max
+
+			r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+			r.Header.Set(webhooks.SignatureHeader, webhooks.Sign(time.Now(), body, "secret"))
+			w := httptest.NewRecorder()
+			v.Handler(next).ServeHTTP(w, r)
+			if w.Code != tt.code {
+				t.Fatalf("unexpected status: %d", w.Code)
+			}
+		})
+	}
+}
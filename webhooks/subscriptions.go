@@ -1,6 +1,10 @@
 package webhooks
 
-import "github.com/blacklightcms/recurly"
+import (
+	"encoding/xml"
+
+	"github.com/blacklightcms/recurly"
+)
 
 // Subscription notifications.
 // https://dev.recurly.com/page/webhooks#subscription-notifications
@@ -17,11 +21,69 @@ const (
 	PausedRenewalSubscription  = "paused_subscription_renewal_notification"
 	PauseCanceledSubscription  = "subscription_pause_canceled_notification"
 	ReactivatedAccount         = "reactivated_account_notification"
+	ReactivatedSubscription    = "reactivated_subscription_notification"
+	Prerenewal                 = "prerenewal_notification"
 )
 
 // SubscriptionNotification is returned for all subscription notifications.
 type SubscriptionNotification struct {
-	Type         string               `xml:"-"`
-	Account      Account              `xml:"account"`
-	Subscription recurly.Subscription `xml:"subscription"`
+	Type         string       `xml:"-"`
+	Account      Account      `xml:"account"`
+	Subscription Subscription `xml:"subscription"`
+}
+
+// Subscription represents the subscription object sent in webhooks.
+// https://dev.recurly.com/page/webhooks#section-subscription-schema
+type Subscription struct {
+	XMLName                xml.Name             `xml:"subscription"`
+	Plan                   Plan                 `xml:"plan"`
+	UUID                   string               `xml:"uuid"`
+	State                  string               `xml:"state"`
+	UnitAmountInCents      int                  `xml:"unit_amount_in_cents"`
+	Currency               string               `xml:"currency"`
+	Quantity               int                  `xml:"quantity"`
+	TotalAmountInCents     int                  `xml:"total_amount_in_cents"`
+	SubscriptionAddOns     []SubscriptionAddOn  `xml:"subscription_add_ons>subscription_add_on"`
+	ActivatedAt            recurly.NullTime     `xml:"activated_at"`
+	CanceledAt             recurly.NullTime     `xml:"canceled_at"`
+	ExpiresAt              recurly.NullTime     `xml:"expires_at"`
+	CurrentPeriodStartedAt recurly.NullTime     `xml:"current_period_started_at"`
+	CurrentPeriodEndsAt    recurly.NullTime     `xml:"current_period_ends_at"`
+	TrialStartedAt         recurly.NullTime     `xml:"trial_started_at"`
+	TrialEndsAt            recurly.NullTime     `xml:"trial_ends_at"`
+	PausedAt               recurly.NullTime     `xml:"paused_at"`
+	ResumeAt               recurly.NullTime     `xml:"resume_at"`
+	RemainingPauseCycles   int                  `xml:"remaining_pause_cycles"`
+	RemainingBillingCycles recurly.NullInt      `xml:"remaining_billing_cycles"`
+	AutoRenew              recurly.NullBool     `xml:"auto_renew"`
+	CollectionMethod       string               `xml:"collection_method"`
+	PendingSubscription    *PendingSubscription `xml:"pending_subscription"`
+}
+
+// Plan represents the plan summary sent with subscriptions in webhooks.
+type Plan struct {
+	Code string `xml:"plan_code"`
+	Name string `xml:"name"`
+}
+
+// SubscriptionAddOn represents an add-on on a subscription sent in webhooks.
+type SubscriptionAddOn struct {
+	XMLName           xml.Name `xml:"subscription_add_on"`
+	Code              string   `xml:"add_on_code"`
+	Name              string   `xml:"name"`
+	AddOnType         string   `xml:"add_on_type"`
+	UsageType         string   `xml:"usage_type"`
+	UsagePercentage   float64  `xml:"usage_percentage"`
+	Quantity          int      `xml:"quantity"`
+	UnitAmountInCents int      `xml:"unit_amount_in_cents"`
+}
+
+// PendingSubscription represents subscription changes that take effect at
+// renewal.
+type PendingSubscription struct {
+	XMLName            xml.Name            `xml:"pending_subscription"`
+	Plan               Plan                `xml:"plan"`
+	UnitAmountInCents  int                 `xml:"unit_amount_in_cents"`
+	Quantity           int                 `xml:"quantity"`
+	SubscriptionAddOns []SubscriptionAddOn `xml:"subscription_add_ons>subscription_add_on"`
 }
@@ -0,0 +1,70 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"time"
+)
+
+// TestRequestOptions configures requests built by NewTestRequest.
+type TestRequestOptions struct {
+	// Username and Password, if Username is set, are sent using HTTP Basic
+	// Authentication. See BasicAuth.
+	Username string
+	Password string
+
+	// Secrets, if set, are used to sign the request in the SignatureHeader.
+	// See SignatureVerifier.
+	Secrets []string
+
+	// SignedAt is the signature timestamp. Defaults to the current time.
+	SignedAt time.Time
+
+	// RemoteAddr overrides the address of the request. See IPAllowlist.
+	RemoteAddr string
+}
+
+// NewTestRequest returns a POST request with the XML webhook for n as the
+// body, for testing handlers. See Marshal for the requirements of n. opts
+// may be nil.
+func NewTestRequest(n interface{}, opts *TestRequestOptions) (*http.Request, error) {
+	body, err := Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+
+	r := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/xml; charset=utf-8")
+	if opts == nil {
+		return r, nil
+	}
+
+	if opts.Username != "" {
+		r.SetBasicAuth(opts.Username, opts.Password)
+	}
+	if len(opts.Secrets) > 0 {
+		t := opts.SignedAt
+		if t.IsZero() {
+			t = time.Now()
+		}
+		r.Header.Set(SignatureHeader, Sign(t, body, opts.Secrets...))
+	}
+	if opts.RemoteAddr != "" {
+		r.RemoteAddr = opts.RemoteAddr
+	}
+	return r, nil
+}
+
+// PostTest sends the XML webhook for n to h and returns the recorded
+// response. opts may be nil.
+func PostTest(h http.Handler, n interface{}, opts *TestRequestOptions) (*httptest.ResponseRecorder, error) {
+	r, err := NewTestRequest(n, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w, nil
+}
@@ -0,0 +1,39 @@
+package webhooks
+
+import (
+	"encoding/xml"
+
+	"github.com/blacklightcms/recurly"
+)
+
+// Usage notifications.
+// https://dev.recurly.com/page/webhooks#usage-notifications
+const (
+	NewUsage = "new_usage_notification"
+)
+
+// UsageNotification is returned for all usage notifications.
+type UsageNotification struct {
+	Type    string  `xml:"-"`
+	Account Account `xml:"account"`
+	Usage   Usage   `xml:"usage"`
+}
+
+// Usage represents the usage record object sent in webhooks.
+type Usage struct {
+	XMLName            xml.Name         `xml:"usage"`
+	ID                 int64            `xml:"id"`
+	SubscriptionUUID   string           `xml:"subscription_id"`
+	AddOnCode          string           `xml:"add_on_code"`
+	MeasuredUnitID     int64            `xml:"measured_unit_id"`
+	Amount             int              `xml:"amount"`
+	MerchantTag        string           `xml:"merchant_tag"`
+	RecordingTimestamp recurly.NullTime `xml:"recording_timestamp"`
+	UsageTimestamp     recurly.NullTime `xml:"usage_timestamp"`
+	CreatedAt          recurly.NullTime `xml:"created_at"`
+	UpdatedAt          recurly.NullTime `xml:"modified_at"`
+	BilledAt           recurly.NullTime `xml:"billed_at"`
+	UsageType          string           `xml:"usage_type"`
+	UnitAmountInCents  recurly.NullInt  `xml:"unit_amount_in_cents"`
+	UsagePercentage    float64          `xml:"usage_percentage"`
+}
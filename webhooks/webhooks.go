@@ -12,39 +12,49 @@ type notificationName struct {
 }
 
 // Parse parses an incoming webhook and returns the notification.
-// If r implements the io.Closer interface, it will be closed.
+// If r implements the io.Closer interface, it will be closed. Only XML
+// webhooks are supported; JSON webhooks cannot be parsed.
 //
 // NOTE: It is important to validate the source of the webhook before trusting
 // it came from Recurly. Please see Recurly's documentation about the IP
 // addresses to expect and/or setting up HTTP Basic Authentication to verify
-// the request came from Recurly's servers.
+// the request came from Recurly's servers. BasicAuth and IPAllowlist can be
+// used to wrap a Handler for this purpose.
 //
 // https://docs.recurly.com/docs/webhooks
 func Parse(r io.Reader) (interface{}, error) {
+	n, _, err := ParseRaw(r)
+	return n, err
+}
+
+// ParseRaw parses an incoming webhook like Parse, and also returns the raw
+// XML of the notification so fields that are not yet modeled can be
+// unmarshaled by the caller.
+func ParseRaw(r io.Reader) (interface{}, []byte, error) {
 	if closer, ok := r.(io.Closer); ok {
 		defer closer.Close()
 	}
 
 	notification, err := ioutil.ReadAll(r)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var n notificationName
 	if err := xml.Unmarshal(notification, &n); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	dst, err := nameToNotification(n.XMLName.Local)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err := xml.Unmarshal(notification, dst); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return dst, nil
+	return dst, notification, nil
 }
 
 // nameToNotification returns the notification interface.
@@ -53,18 +63,27 @@ func nameToNotification(name string) (interface{}, error) {
 	case BillingInfoUpdated, NewAccount, UpdatedAccount, CanceledAccount, BillingInfoUpdateFailed:
 		return &AccountNotification{Type: name}, nil
 	case NewSubscription, UpdatedSubscription, RenewedSubscription, ExpiredSubscription, CanceledSubscription, ReactivatedAccount, PausedSubscription,
-		ResumedSubscription, ScheduledPauseSubscription, ModifiedPauseSubscription, PausedRenewalSubscription, PauseCanceledSubscription:
+		ResumedSubscription, ScheduledPauseSubscription, ModifiedPauseSubscription, PausedRenewalSubscription, PauseCanceledSubscription,
+		ReactivatedSubscription, Prerenewal:
 		return &SubscriptionNotification{Type: name}, nil
-	case NewChargeInvoice, ProcessingChargeInvoice, PastDueChargeInvoice, PaidChargeInvoice, FailedChargeInvoice, ReopenedChargeInvoice:
+	case NewChargeInvoice, ProcessingChargeInvoice, PastDueChargeInvoice, PaidChargeInvoice, FailedChargeInvoice, ReopenedChargeInvoice,
+		NewInvoice, PaidInvoice, PastDueInvoice, ClosedInvoice:
 		return &ChargeInvoiceNotification{Type: name}, nil
 	case NewCreditInvoice, ProcessingCreditInvoice, ClosedCreditInvoice, VoidedCreditInvoice, ReopenedCreditInvoice, OpenCreditInvoice:
 		return &CreditInvoiceNotification{Type: name}, nil
 	case NewCreditPayment, VoidedCreditPayment:
 		return &CreditPaymentNotification{Type: name}, nil
-	case SuccessfulPayment, FailedPayment, VoidPayment, SuccessfulRefund, ScheduledPayment, ProcessingPayment, TransactionStatusUpdated:
+	case SuccessfulPayment, FailedPayment, VoidPayment, SuccessfulRefund, ScheduledPayment, ProcessingPayment, TransactionStatusUpdated,
+		TransactionAuthorized, FraudInfoUpdated:
 		return &PaymentNotification{Type: name}, nil
 	case NewDunningEvent:
 		return &NewDunningEventNotification{Type: name}, nil
+	case PurchasedGiftCard, CanceledGiftCard, UpdatedGiftCard, RegeneratedGiftCard, RedeemedGiftCard, UpdatedBalanceGiftCard, LowBalanceGiftCard:
+		return &GiftCardNotification{Type: name}, nil
+	case NewItem, UpdatedItem, DeactivatedItem, ReactivatedItem:
+		return &ItemNotification{Type: name}, nil
+	case NewUsage:
+		return &UsageNotification{Type: name}, nil
 	}
 	return nil, ErrUnknownNotification{name: name}
 }
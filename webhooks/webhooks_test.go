@@ -206,9 +206,9 @@ func TestParse_NewSubscriptionNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "bronze",
 				Name: "Bronze Plan",
 			},
@@ -241,9 +241,9 @@ func TestParse_UpdatedSubscriptionNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "1dpt",
 				Name: "Subscription One",
 			},
@@ -277,9 +277,9 @@ func TestParse_RenewedSubscriptionNotification(t *testing.T) {
 			LastName:    "Example",
 			CompanyName: "Company, Inc.",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "bootstrap",
 				Name: "Bootstrap",
 			},
@@ -310,9 +310,9 @@ func TestParse_ExpiredSubscriptionNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "1dpt",
 				Name: "Subscription One",
 			},
@@ -345,9 +345,9 @@ func TestParse_CanceledSubscriptionNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "1dpt",
 				Name: "Subscription One",
 			},
@@ -380,9 +380,9 @@ func TestParse_PausedSubscriptionNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "daily_plan",
 				Name: "daily_plan",
 			},
@@ -415,9 +415,9 @@ func TestParse_ResumedSubscriptionNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "daily_plan",
 				Name: "daily_plan",
 			},
@@ -447,9 +447,9 @@ func TestParse_ScheduledSubscriptionPausedNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "daily_plan",
 				Name: "daily_plan",
 			},
@@ -482,9 +482,9 @@ func TestParse_SubscriptionPauseModifiedNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "daily_plan",
 				Name: "daily_plan",
 			},
@@ -517,9 +517,9 @@ func TestParse_PausedSubscriptionRenewalNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "daily_plan",
 				Name: "daily_plan",
 			},
@@ -551,9 +551,9 @@ func TestParse_SubscriptionPauseCanceledNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "daily_plan",
 				Name: "daily_plan",
 			},
@@ -582,9 +582,9 @@ func TestParse_ReactivatedAccountNotification(t *testing.T) {
 			FirstName: "Verena",
 			LastName:  "Example",
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "bootstrap",
 				Name: "Bootstrap",
 			},
@@ -620,6 +620,7 @@ func TestParse_SuccessfulPaymentNotification(t *testing.T) {
 		Transaction: webhooks.Transaction{
 			XMLName:       xml.Name{Local: "transaction"},
 			UUID:          "a5143c1d3a6f4a8287d0e2cc1d4c0427",
+			InvoiceUUID:   "1974a09kj90s0789dsf099798326881c",
 			InvoiceNumber: 2059,
 			Action:        "purchase",
 			PaymentMethod: "credit_card",
@@ -631,6 +632,7 @@ func TestParse_SuccessfulPaymentNotification(t *testing.T) {
 			Test:          recurly.NewBool(true),
 			Voidable:      recurly.NewBool(true),
 			Refundable:    recurly.NewBool(true),
+			CreatedAt:     recurly.NewTime(MustParseTime("2009-11-22T13:10:38Z")),
 		},
 	}); diff != "" {
 		t.Fatal(diff)
@@ -655,6 +657,7 @@ func TestParse_FailedPaymentNotification(t *testing.T) {
 		Transaction: webhooks.Transaction{
 			XMLName:          xml.Name{Local: "transaction"},
 			UUID:             "a5143c1d3a6f4a8287d0e2cc1d4c0427",
+			InvoiceUUID:      "8fjk3sd7j90s0789dsf099798jkliy65",
 			InvoiceNumber:    2059,
 			SubscriptionUUID: "1974a098jhlkjasdfljkha898326881c",
 			Action:           "purchase",
@@ -668,6 +671,8 @@ func TestParse_FailedPaymentNotification(t *testing.T) {
 			Test:             recurly.NewBool(true),
 			Voidable:         recurly.NewBool(false),
 			Refundable:       recurly.NewBool(false),
+			Gateway:          "cybersource",
+			CreatedAt:        recurly.NewTime(MustParseTime("2009-11-22T13:10:38Z")),
 		},
 	}); diff != "" {
 		t.Fatal(diff)
@@ -692,6 +697,7 @@ func TestParse_VoidPaymentNotification(t *testing.T) {
 		Transaction: webhooks.Transaction{
 			XMLName:          xml.Name{Local: "transaction"},
 			UUID:             "a5143c1d3a6f4a8287d0e2cc1d4c0427",
+			InvoiceUUID:      "ffc64d71d4b5404e93f13aac9c63b007",
 			InvoiceNumber:    2059,
 			SubscriptionUUID: "1974a098jhlkjasdfljkha898326881c",
 			Action:           "purchase",
@@ -703,6 +709,7 @@ func TestParse_VoidPaymentNotification(t *testing.T) {
 			Test:             recurly.NewBool(true),
 			Voidable:         recurly.NewBool(true),
 			Refundable:       recurly.NewBool(true),
+			CreatedAt:        recurly.NewTime(MustParseTime("2010-10-05T23:00:50Z")),
 		},
 	}); diff != "" {
 		t.Fatal(diff)
@@ -727,6 +734,7 @@ func TestParse_SuccessfulRefundNotification(t *testing.T) {
 		Transaction: webhooks.Transaction{
 			XMLName:          xml.Name{Local: "transaction"},
 			UUID:             "a5143c1d3a6f4a8287d0e2cc1d4c0427",
+			InvoiceUUID:      "ffc64d71d4b5404e93f13aac9c63b007",
 			InvoiceNumber:    2059,
 			SubscriptionUUID: "1974a098jhlkjasdfljkha898326881c",
 			Action:           "credit",
@@ -738,6 +746,7 @@ func TestParse_SuccessfulRefundNotification(t *testing.T) {
 			Test:             recurly.NewBool(true),
 			Voidable:         recurly.NewBool(true),
 			Refundable:       recurly.NewBool(true),
+			CreatedAt:        recurly.NewTime(MustParseTime("2010-10-06T20:37:55Z")),
 		},
 	}); diff != "" {
 		t.Fatal(diff)
@@ -762,6 +771,7 @@ func TestParse_ProcessingPaymentNotification(t *testing.T) {
 		Transaction: webhooks.Transaction{
 			XMLName:          xml.Name{Local: "transaction"},
 			UUID:             "a5143c1d3a6f4a8287d0e2cc1d4c0427",
+			InvoiceUUID:      "1974a09kj90s0789dsf099798326881c",
 			InvoiceNumber:    2059,
 			SubscriptionUUID: "1974a098jhlkjasdfljkha898326881c",
 			Action:           "purchase",
@@ -773,6 +783,7 @@ func TestParse_ProcessingPaymentNotification(t *testing.T) {
 			Test:             recurly.NewBool(true),
 			Voidable:         recurly.NewBool(true),
 			Refundable:       recurly.NewBool(true),
+			CreatedAt:        recurly.NewTime(MustParseTime("2009-11-22T13:10:38Z")),
 		},
 	}); diff != "" {
 		t.Fatal(diff)
@@ -797,6 +808,7 @@ func TestParse_ScheduledPaymentNotification(t *testing.T) {
 		Transaction: webhooks.Transaction{
 			XMLName:          xml.Name{Local: "transaction"},
 			UUID:             "a5143c1d3a6f4a8287d0e2cc1d4c0427",
+			InvoiceUUID:      "1974a09kj90s0789dsf099798326881c",
 			InvoiceNumber:    2059,
 			SubscriptionUUID: "1974a098jhlkjasdfljkha898326881c",
 			Action:           "purchase",
@@ -808,6 +820,7 @@ func TestParse_ScheduledPaymentNotification(t *testing.T) {
 			Test:             recurly.NewBool(true),
 			Voidable:         recurly.NewBool(true),
 			Refundable:       recurly.NewBool(true),
+			CreatedAt:        recurly.NewTime(MustParseTime("2009-11-22T13:10:38Z")),
 		},
 	}); diff != "" {
 		t.Fatal(diff)
@@ -832,6 +845,7 @@ func TestParse_TransactionStatusUpdatedNotification(t *testing.T) {
 		Transaction: webhooks.Transaction{
 			XMLName:          xml.Name{Local: "transaction"},
 			UUID:             "a5143c1d3a6f4a8287d0e2cc1d4c0427",
+			InvoiceUUID:      "ffc64d71d4b5404e93f13aac9c63b007",
 			InvoiceNumber:    2059,
 			SubscriptionUUID: "1974a098jhlkjasdfljkha898326881c",
 			Action:           "purchase",
@@ -843,6 +857,7 @@ func TestParse_TransactionStatusUpdatedNotification(t *testing.T) {
 			Test:             recurly.NewBool(true),
 			Voidable:         recurly.NewBool(true),
 			Refundable:       recurly.NewBool(true),
+			CreatedAt:        recurly.NewTime(MustParseTime("2010-10-05T23:00:50Z")),
 		},
 	}); diff != "" {
 		t.Fatal(diff)
@@ -883,9 +898,9 @@ func TestParse_NewDunningEventNotification(t *testing.T) {
 			NetTerms:                      recurly.NewInt(30),
 			CollectionMethod:              recurly.CollectionMethodManual,
 		},
-		Subscription: recurly.Subscription{
+		Subscription: webhooks.Subscription{
 			XMLName: xml.Name{Local: "subscription"},
-			Plan: recurly.NestedPlan{
+			Plan: webhooks.Plan{
 				Code: "gold",
 				Name: "Gold",
 			},
@@ -902,6 +917,369 @@ func TestParse_NewDunningEventNotification(t *testing.T) {
 	}
 }
 
+func TestParse_GiftCardNotifications(t *testing.T) {
+	tests := []struct {
+		name     string
+		balance  int
+		redeemed bool
+	}{
+		{name: webhooks.PurchasedGiftCard, balance: 2000},
+		{name: webhooks.CanceledGiftCard, balance: 2000},
+		{name: webhooks.UpdatedGiftCard, balance: 2000},
+		{name: webhooks.RegeneratedGiftCard, balance: 2000},
+		{name: webhooks.RedeemedGiftCard, balance: 2000, redeemed: true},
+		{name: webhooks.UpdatedBalanceGiftCard, balance: 500, redeemed: true},
+		{name: webhooks.LowBalanceGiftCard, balance: 100, redeemed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := &webhooks.GiftCardNotification{
+				Type: tt.name,
+				GiftCard: webhooks.GiftCard{
+					XMLName:           xml.Name{Local: "gift_card"},
+					ID:                2005384587788419083,
+					RedemptionCode:    "AE1DB8F9A2C37E5A",
+					ProductCode:       "gift_card",
+					UnitAmountInCents: 2000,
+					BalanceInCents:    tt.balance,
+					Currency:          "USD",
+					GifterAccountCode: "1",
+					InvoiceNumber:     1001,
+					Delivery: &recurly.GiftCardDelivery{
+						XMLName:         xml.Name{Local: "delivery"},
+						Method:          recurly.GiftCardDeliveryMethodEmail,
+						EmailAddress:    "benjamin@example.com",
+						FirstName:       "Benjamin",
+						LastName:        "Example",
+						GifterName:      "Verena",
+						PersonalMessage: "Happy birthday!",
+					},
+					CreatedAt:   recurly.NewTime(MustParseTime("2016-08-03T20:37:27Z")),
+					UpdatedAt:   recurly.NewTime(MustParseTime("2016-08-04T20:37:27Z")),
+					DeliveredAt: recurly.NewTime(MustParseTime("2016-08-03T20:37:27Z")),
+				},
+			}
+			if tt.redeemed {
+				expected.GiftCard.RecipientAccountCode = "2"
+				expected.GiftCard.RedeemedAt = recurly.NewTime(MustParseTime("2016-08-04T20:37:27Z"))
+			}
+
+			result := MustParseFile("testdata/" + tt.name + ".xml")
+			if n, ok := result.(*webhooks.GiftCardNotification); !ok {
+				t.Fatalf("unexpected type: %T, result", n)
+			} else if diff := cmp.Diff(n, expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestParse_ItemNotifications(t *testing.T) {
+	tests := []struct {
+		name    string
+		state   string
+		deleted bool
+	}{
+		{name: webhooks.NewItem, state: "active"},
+		{name: webhooks.UpdatedItem, state: "active"},
+		{name: webhooks.DeactivatedItem, state: "inactive", deleted: true},
+		{name: webhooks.ReactivatedItem, state: "active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := &webhooks.ItemNotification{
+				Type: tt.name,
+				Item: recurly.Item{
+					XMLName:        xml.Name{Local: "item"},
+					Code:           "plastic_gloves",
+					Name:           "Plastic Gloves",
+					Description:    "Protects hands from chemicals",
+					ExternalSKU:    "SKU-1234",
+					AccountingCode: "gloves",
+					State:          tt.state,
+					CreatedAt:      recurly.NewTime(MustParseTime("2019-07-01T20:00:00Z")),
+					UpdatedAt:      recurly.NewTime(MustParseTime("2019-07-02T20:00:00Z")),
+				},
+			}
+			if tt.deleted {
+				expected.Item.DeletedAt = recurly.NewTime(MustParseTime("2019-07-02T20:00:00Z"))
+			}
+
+			result := MustParseFile("testdata/" + tt.name + ".xml")
+			if n, ok := result.(*webhooks.ItemNotification); !ok {
+				t.Fatalf("unexpected type: %T, result", n)
+			} else if diff := cmp.Diff(n, expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestParse_TransactionAuthorizedNotification(t *testing.T) {
+	result := MustParseFile("testdata/transaction_authorized_notification.xml")
+	if n, ok := result.(*webhooks.PaymentNotification); !ok {
+		t.Fatalf("unexpected type: %T, result", n)
+	} else if diff := cmp.Diff(n, &webhooks.PaymentNotification{
+		Type:        webhooks.TransactionAuthorized,
+		Account:     NewTestNotificationAccount(),
+		Transaction: NewTestNotificationTransaction("authorization"),
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParse_FraudInfoUpdatedNotification(t *testing.T) {
+	transaction := NewTestNotificationTransaction("purchase")
+	transaction.FraudInfo = &webhooks.FraudInfo{
+		XMLName:   xml.Name{Local: "fraud_info"},
+		Score:     88,
+		Decision:  "REVIEW",
+		Reference: "7ac3a1b40ee3",
+	}
+
+	result := MustParseFile("testdata/fraud_info_updated_notification.xml")
+	if n, ok := result.(*webhooks.PaymentNotification); !ok {
+		t.Fatalf("unexpected type: %T, result", n)
+	} else if diff := cmp.Diff(n, &webhooks.PaymentNotification{
+		Type:        webhooks.FraudInfoUpdated,
+		Account:     NewTestNotificationAccount(),
+		Transaction: transaction,
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParse_PrerenewalNotification(t *testing.T) {
+	result := MustParseFile("testdata/prerenewal_notification.xml")
+	if n, ok := result.(*webhooks.SubscriptionNotification); !ok {
+		t.Fatalf("unexpected type: %T, result", n)
+	} else if diff := cmp.Diff(n, &webhooks.SubscriptionNotification{
+		Type:         webhooks.Prerenewal,
+		Account:      NewTestNotificationAccount(),
+		Subscription: NewTestNotificationSubscription(),
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParse_ReactivatedSubscriptionNotification(t *testing.T) {
+	result := MustParseFile("testdata/reactivated_subscription_notification.xml")
+	if n, ok := result.(*webhooks.SubscriptionNotification); !ok {
+		t.Fatalf("unexpected type: %T, result", n)
+	} else if diff := cmp.Diff(n, &webhooks.SubscriptionNotification{
+		Type:         webhooks.ReactivatedSubscription,
+		Account:      NewTestNotificationAccount(),
+		Subscription: NewTestNotificationSubscription(),
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParse_LegacyInvoiceNotifications(t *testing.T) {
+	tests := []struct {
+		name   string
+		state  string
+		closed bool
+	}{
+		{name: webhooks.NewInvoice, state: "open"},
+		{name: webhooks.PaidInvoice, state: "collected", closed: true},
+		{name: webhooks.PastDueInvoice, state: "past_due"},
+		{name: webhooks.ClosedInvoice, state: "failed", closed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := &webhooks.ChargeInvoiceNotification{
+				Type:    tt.name,
+				Account: NewTestNotificationAccount(),
+				Invoice: webhooks.ChargeInvoice{
+					XMLName:           xml.Name{Local: "invoice"},
+					SubscriptionUUIDs: []string{"ffc64d71d4b5404e93f13aac9c63b006"},
+					UUID:              "ffc64d71d4b5404e93f13aac9c63b007",
+					State:             tt.state,
+					CreatedAt:         recurly.NewTime(MustParseTime("2018-02-13T16:00:04Z")),
+					InvoiceNumber:     1000,
+					TotalInCents:      1100,
+					Currency:          "USD",
+					NetTerms:          recurly.NewInt(0),
+					CollectionMethod:  recurly.CollectionMethodAutomatic,
+				},
+			}
+			if tt.closed {
+				expected.Invoice.ClosedAt = recurly.NewTime(MustParseTime("2018-02-13T16:00:04Z"))
+			}
+
+			result := MustParseFile("testdata/" + tt.name + ".xml")
+			if n, ok := result.(*webhooks.ChargeInvoiceNotification); !ok {
+				t.Fatalf("unexpected type: %T, result", n)
+			} else if diff := cmp.Diff(n, expected); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
+func TestParse_NewUsageNotification(t *testing.T) {
+	result := MustParseFile("testdata/new_usage_notification.xml")
+	if n, ok := result.(*webhooks.UsageNotification); !ok {
+		t.Fatalf("unexpected type: %T, result", n)
+	} else if diff := cmp.Diff(n, &webhooks.UsageNotification{
+		Type:    webhooks.NewUsage,
+		Account: NewTestNotificationAccount(),
+		Usage: webhooks.Usage{
+			XMLName:            xml.Name{Local: "usage"},
+			ID:                 394729929104688227,
+			SubscriptionUUID:   "4ff8726e7ee6a4fcc2dbb24ee2bcc6a7",
+			AddOnCode:          "video_storage",
+			MeasuredUnitID:     394681920153192422,
+			Amount:             1,
+			MerchantTag:        "Order ID: 4939853977878713",
+			RecordingTimestamp: recurly.NewTime(MustParseTime("2016-04-28T21:57:53Z")),
+			UsageTimestamp:     recurly.NewTime(MustParseTime("2016-04-28T21:57:53Z")),
+			CreatedAt:          recurly.NewTime(MustParseTime("2016-04-28T21:57:54Z")),
+			UpdatedAt:          recurly.NewTime(MustParseTime("2016-04-28T21:57:54Z")),
+			UsageType:          "price",
+			UnitAmountInCents:  recurly.NewInt(45),
+		},
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParse_UpdatedSubscriptionNotification_Full(t *testing.T) {
+	account := NewTestNotificationAccount()
+	account.CompanyName = "Company, Inc."
+	account.Phone = "555-555-5555"
+	account.VATNumber = "GB123456789"
+	account.Address = &webhooks.Address{
+		XMLName: xml.Name{Local: "address"},
+		Address: "123 Main St.",
+		City:    "San Francisco",
+		State:   "CA",
+		Zip:     "94105",
+		Country: "US",
+	}
+	account.BillingInfo = &webhooks.BillingInfo{
+		XMLName:     xml.Name{Local: "billing_info"},
+		FirstName:   "Verena",
+		LastName:    "Example",
+		PaymentType: "credit_card",
+		CardType:    "Visa",
+		FirstSix:    "411111",
+		LastFour:    "1111",
+		Month:       11,
+		Year:        2025,
+	}
+
+	subscription := NewTestNotificationSubscription()
+	subscription.UnitAmountInCents = 4000
+	subscription.Currency = "USD"
+	subscription.TotalAmountInCents = 5000
+	subscription.SubscriptionAddOns = []webhooks.SubscriptionAddOn{
+		{
+			XMLName:           xml.Name{Local: "subscription_add_on"},
+			Code:              "ipaddresses",
+			Name:              "IP Addresses",
+			AddOnType:         "fixed",
+			Quantity:          2,
+			UnitAmountInCents: 500,
+		},
+		{
+			XMLName:         xml.Name{Local: "subscription_add_on"},
+			Code:            "bandwidth",
+			Name:            "Bandwidth",
+			AddOnType:       "usage",
+			UsageType:       "percentage",
+			UsagePercentage: 1.5,
+			Quantity:        1,
+		},
+	}
+	subscription.RemainingBillingCycles = recurly.NewInt(3)
+	subscription.AutoRenew = recurly.NewBool(true)
+	subscription.PendingSubscription = &webhooks.PendingSubscription{
+		XMLName: xml.Name{Local: "pending_subscription"},
+		Plan: webhooks.Plan{
+			Code: "platinum",
+			Name: "Platinum",
+		},
+		UnitAmountInCents: 8000,
+		Quantity:          1,
+		SubscriptionAddOns: []webhooks.SubscriptionAddOn{{
+			XMLName:           xml.Name{Local: "subscription_add_on"},
+			Code:              "ipaddresses",
+			Name:              "IP Addresses",
+			AddOnType:         "fixed",
+			Quantity:          4,
+			UnitAmountInCents: 500,
+		}},
+	}
+
+	result := MustParseFile("testdata/updated_subscription_notification_full.xml")
+	if n, ok := result.(*webhooks.SubscriptionNotification); !ok {
+		t.Fatalf("unexpected type: %T, result", n)
+	} else if diff := cmp.Diff(n, &webhooks.SubscriptionNotification{
+		Type:         webhooks.UpdatedSubscription,
+		Account:      account,
+		Subscription: subscription,
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestParse_SuccessfulPaymentNotification_Full(t *testing.T) {
+	transaction := NewTestNotificationTransaction("purchase")
+	transaction.Currency = "EUR"
+	transaction.Gateway = "braintree"
+	transaction.CollectedAt = recurly.NewTime(MustParseTime("2020-02-13T21:00:32Z"))
+	transaction.FraudInfo = &webhooks.FraudInfo{
+		XMLName:   xml.Name{Local: "fraud_info"},
+		Score:     12,
+		Decision:  "APPROVE",
+		Reference: "7ac3a1b40ee3",
+	}
+
+	result := MustParseFile("testdata/successful_payment_notification_full.xml")
+	if n, ok := result.(*webhooks.PaymentNotification); !ok {
+		t.Fatalf("unexpected type: %T, result", n)
+	} else if diff := cmp.Diff(n, &webhooks.PaymentNotification{
+		Type:        webhooks.SuccessfulPayment,
+		Account:     NewTestNotificationAccount(),
+		Transaction: transaction,
+	}); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+// Ensure the raw XML is returned so unmodeled fields can be read.
+func TestParseRaw(t *testing.T) {
+	for _, file := range []string{
+		"testdata/updated_subscription_notification_full.xml",
+	} {
+		t.Run(file, func(t *testing.T) {
+			n, raw, err := webhooks.ParseRaw(MustOpenFile(file))
+			if err != nil {
+				t.Fatal(err)
+			} else if _, ok := n.(*webhooks.SubscriptionNotification); !ok {
+				t.Fatalf("unexpected type: %T", n)
+			}
+
+			var v struct {
+				CustomFields []struct {
+					Name  string `xml:"name"`
+					Value string `xml:"value"`
+				} `xml:"subscription>custom_fields>custom_field"`
+			}
+			if err := xml.Unmarshal(raw, &v); err != nil {
+				t.Fatal(err)
+			} else if len(v.CustomFields) != 1 || v.CustomFields[0].Name != "device_id" || v.CustomFields[0].Value != "KIWGT-94HDV" {
+				t.Fatalf("unexpected custom fields: %#v", v.CustomFields)
+			}
+		})
+	}
+}
+
 func TestParse_ErrUnknownNotification(t *testing.T) {
 	f := MustOpenFile("testdata/unknown_notification.xml")
 	defer f.Close()
@@ -918,6 +1296,58 @@ func TestParse_ErrUnknownNotification(t *testing.T) {
 	}
 }
 
+// Returns the account sent in newer notification fixtures.
+func NewTestNotificationAccount() webhooks.Account {
+	return webhooks.Account{
+		XMLName:   xml.Name{Local: "account"},
+		Code:      "1",
+		Email:     "verena@example.com",
+		FirstName: "Verena",
+		LastName:  "Example",
+	}
+}
+
+// Returns the transaction sent in newer payment notification fixtures.
+func NewTestNotificationTransaction(action string) webhooks.Transaction {
+	return webhooks.Transaction{
+		XMLName:          xml.Name{Local: "transaction"},
+		UUID:             "6027ae4ff3ed18e4ed7d5c4f06b02c4e",
+		InvoiceUUID:      "6027ae4fb6e5d4d8a5cb9a44c5930d2d",
+		InvoiceNumber:    1427,
+		SubscriptionUUID: "6027ae4fc5a0c6ac3d8df64ad7b7a8d7",
+		Action:           action,
+		PaymentMethod:    "credit_card",
+		AmountInCents:    2000,
+		Status:           "success",
+		Message:          "Successful test transaction",
+		Reference:        "12345",
+		Source:           "subscription",
+		Test:             recurly.NewBool(true),
+		Voidable:         recurly.NewBool(true),
+		Refundable:       recurly.NewBool(false),
+		CreatedAt:        recurly.NewTime(MustParseTime("2020-02-13T21:00:31Z")),
+	}
+}
+
+// Returns the subscription sent in newer subscription notification fixtures.
+func NewTestNotificationSubscription() webhooks.Subscription {
+	return webhooks.Subscription{
+		XMLName: xml.Name{Local: "subscription"},
+		Plan: webhooks.Plan{
+			Code: "gold",
+			Name: "Gold",
+		},
+		UUID:                   "4110792b3b01967d854f674b7282f542",
+		State:                  "active",
+		Quantity:               1,
+		TotalAmountInCents:     4500,
+		ActivatedAt:            recurly.NewTime(MustParseTime("2017-11-09T16:47:30Z")),
+		CurrentPeriodStartedAt: recurly.NewTime(MustParseTime("2018-02-09T16:47:30Z")),
+		CurrentPeriodEndsAt:    recurly.NewTime(MustParseTime("2018-03-09T16:47:30Z")),
+		CollectionMethod:       recurly.CollectionMethodAutomatic,
+	}
+}
+
 func MustParseFile(file string) interface{} {
 	f := MustOpenFile(file)
 	result, err := webhooks.Parse(f)